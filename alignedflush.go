@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Span processor force-flushing the next processor at wall-clock multiples
+// of period (e.g. every minute on the minute), on top of its own schedule, so
+// exports line up across instances
+type alignedFlushProcessor struct {
+    next     trace.SpanProcessor
+    period   time.Duration
+    now      func() time.Time
+    newTimer func(time.Duration) flushTimer
+
+    stop     chan struct{}
+    done     chan struct{}
+    stopOnce sync.Once
+}
+
+// Timer driving the flush loop, a time.Timer outside tests
+type flushTimer interface {
+    Chan() <-chan time.Time
+    Reset(d time.Duration) bool
+    Stop() bool
+}
+
+type wallTimer struct{ *time.Timer }
+
+func (t wallTimer) Chan() <-chan time.Time { return t.C }
+
+func newAlignedFlushProcessor(next trace.SpanProcessor, period time.Duration) *alignedFlushProcessor {
+    return newAlignedFlushProcessorWithClock(next, period, time.Now, func(d time.Duration) flushTimer {
+        return wallTimer{time.NewTimer(d)}
+    })
+}
+
+// As newAlignedFlushProcessor, reading the time from now and waiting on
+// timers from newTimer
+func newAlignedFlushProcessorWithClock(next trace.SpanProcessor, period time.Duration, now func() time.Time, newTimer func(time.Duration) flushTimer) *alignedFlushProcessor {
+    p := &alignedFlushProcessor{
+        next:     next,
+        period:   period,
+        now:      now,
+        newTimer: newTimer,
+        stop:     make(chan struct{}),
+        done:     make(chan struct{}),
+    }
+    go p.loop()
+    return p
+}
+
+// First multiple of period after now, counted from the zero time so that
+// minute and hour periods fall on UTC boundaries
+func nextAlignedTime(now time.Time, period time.Duration) time.Time {
+    return now.Truncate(period).Add(period)
+}
+
+func (p *alignedFlushProcessor) loop() {
+    defer close(p.done)
+    timer := p.newTimer(p.untilNextFlush())
+    defer timer.Stop()
+    for {
+        select {
+        case <-timer.Chan():
+            _ = p.next.ForceFlush(context.Background())
+            timer.Reset(p.untilNextFlush())
+        case <-p.stop:
+            return
+        }
+    }
+}
+
+func (p *alignedFlushProcessor) untilNextFlush() time.Duration {
+    now := p.now()
+    return nextAlignedTime(now, p.period).Sub(now)
+}
+
+func (p *alignedFlushProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *alignedFlushProcessor) OnEnd(s trace.ReadOnlySpan) {
+    p.next.OnEnd(s)
+}
+
+func (p *alignedFlushProcessor) Shutdown(ctx context.Context) error {
+    p.stopOnce.Do(func() { close(p.stop) })
+    <-p.done
+    return p.next.Shutdown(ctx)
+}
+
+func (p *alignedFlushProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+    "context"
+    "testing"
+    "time"
+)
+
+// Timer fired by the test; each Reset is reported on resets
+type fakeFlushTimer struct {
+    c      chan time.Time
+    resets chan time.Duration
+}
+
+func (t *fakeFlushTimer) Chan() <-chan time.Time { return t.c }
+
+func (t *fakeFlushTimer) Reset(d time.Duration) bool {
+    t.resets <- d
+    return true
+}
+
+func (t *fakeFlushTimer) Stop() bool { return true }
+
+// Span processor reporting each ForceFlush on flushed
+type flushSignallingProcessor struct {
+    collectingProcessor
+    flushed chan struct{}
+}
+
+func (p *flushSignallingProcessor) ForceFlush(context.Context) error {
+    p.flushed <- struct{}{}
+    return nil
+}
+
+func TestAlignedFlushFollowsClock(t *testing.T) {
+    now := time.Date(2024, 1, 1, 12, 0, 20, 0, time.UTC)
+    clock := make(chan time.Time, 1)
+    clock <- now
+    timer := &fakeFlushTimer{c: make(chan time.Time), resets: make(chan time.Duration, 1)}
+    started := make(chan time.Duration, 1)
+    next := &flushSignallingProcessor{flushed: make(chan struct{}, 1)}
+
+    p := newAlignedFlushProcessorWithClock(next, time.Minute, func() time.Time { return <-clock }, func(d time.Duration) flushTimer {
+        started <- d
+        return timer
+    })
+    if got := <-started; got != 40*time.Second {
+        t.Errorf("first flush in %s, want 40s", got)
+    }
+
+    // The flush runs late, at 12:01:05; the next one is still on the minute
+    clock <- now.Add(45 * time.Second)
+    timer.c <- now
+    <-next.flushed
+    if got := <-timer.resets; got != 55*time.Second {
+        t.Errorf("next flush in %s, want 55s", got)
+    }
+
+    if err := p.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Span processor removing attributes before export: in allow mode only the
+// listed keys are kept, in deny mode the listed keys are dropped
+type attributeFilterProcessor struct {
+    next  trace.SpanProcessor
+    keys  map[attribute.Key]struct{}
+    allow bool
+}
+
+func newAttributeFilterProcessor(next trace.SpanProcessor, keys []string, allow bool) *attributeFilterProcessor {
+    p := &attributeFilterProcessor{next: next, keys: make(map[attribute.Key]struct{}, len(keys)), allow: allow}
+    for _, k := range keys {
+        p.keys[attribute.Key(k)] = struct{}{}
+    }
+    return p
+}
+
+func (p *attributeFilterProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *attributeFilterProcessor) OnEnd(s trace.ReadOnlySpan) {
+    attrs := s.Attributes()
+    kept := make([]attribute.KeyValue, 0, len(attrs))
+    for _, kv := range attrs {
+        if _, listed := p.keys[kv.Key]; listed == p.allow {
+            kept = append(kept, kv)
+        }
+    }
+    if len(kept) != len(attrs) {
+        s = replaceAttributes(s, kept)
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *attributeFilterProcessor) Shutdown(ctx context.Context) error {
+    return p.next.Shutdown(ctx)
+}
+
+func (p *attributeFilterProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
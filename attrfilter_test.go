@@ -0,0 +1,44 @@
+package main
+
+import (
+    "context"
+    "reflect"
+    "sort"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func filteredAttributeKeys(t *testing.T, keys []string, allow bool) []string {
+    t.Helper()
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(newAttributeFilterProcessor(recorder, keys, allow)))
+    _, span := tp.Tracer("test").Start(context.Background(), "op", oteltrace.WithAttributes(
+        attribute.String("http.method", "GET"),
+        attribute.String("user.email", "a@example.com"),
+        attribute.String("client.ip", "10.0.0.1")))
+    span.End()
+    ended := recorder.Ended()
+    if len(ended) != 1 {
+        t.Fatalf("recorded %d spans, want 1", len(ended))
+    }
+    var got []string
+    for _, kv := range ended[0].Attributes() {
+        got = append(got, string(kv.Key))
+    }
+    sort.Strings(got)
+    return got
+}
+
+func TestAttributeFilterModes(t *testing.T) {
+    listed := []string{"user.email", "client.ip"}
+    if got, want := filteredAttributeKeys(t, listed, true), []string{"client.ip", "user.email"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("allow mode kept %v, want %v", got, want)
+    }
+    if got, want := filteredAttributeKeys(t, listed, false), []string{"http.method"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("deny mode kept %v, want %v", got, want)
+    }
+}
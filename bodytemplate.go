@@ -0,0 +1,57 @@
+package main
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+// Variable part of a log body replaced by <Name> in its template
+type tokenPattern struct {
+    Name    string
+    Pattern *regexp.Regexp
+}
+
+// Default tokens, applied in order so that UUIDs and IPs are replaced before
+// their digits could be taken for numbers
+var defaultBodyTokenPatterns = []tokenPattern{
+    {"UUID", regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)},
+    {"IP", regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)},
+    {"HEX", regexp.MustCompile(`\b0[xX][0-9a-fA-F]+\b`)},
+    {"NUM", regexp.MustCompile(`\b\d+(?:\.\d+)?`)},
+}
+
+// Message template of a body: each match of a pattern replaced by its
+// placeholder, so bodies differing only in those values share a template
+func bodyTemplate(body string, patterns []tokenPattern) string {
+    for _, p := range patterns {
+        body = p.Pattern.ReplaceAllLiteralString(body, "<"+p.Name+">")
+    }
+    return body
+}
+
+// Parse "NAME=regex" items separated by semicolons (patterns often contain
+// commas); an empty value yields the default patterns
+func parseBodyTokenPatterns(value string) ([]tokenPattern, error) {
+    if strings.TrimSpace(value) == "" {
+        return defaultBodyTokenPatterns, nil
+    }
+    var patterns []tokenPattern
+    for _, item := range strings.Split(value, ";") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        name, expr, ok := strings.Cut(item, "=")
+        name = strings.TrimSpace(name)
+        if !ok || name == "" {
+            return nil, fmt.Errorf("invalid body token pattern %q: want NAME=regex", item)
+        }
+        re, err := regexp.Compile(expr)
+        if err != nil {
+            return nil, fmt.Errorf("invalid body token pattern %q: %v", item, err)
+        }
+        patterns = append(patterns, tokenPattern{Name: name, Pattern: re})
+    }
+    return patterns, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestBodyTemplateGroupsVaryingValues(t *testing.T) {
+    bodies := []string{
+        "user 42 from 10.0.0.1 opened order 3f2504e0-4f89-11d3-9a0c-0305e82c3301 in 1.5s",
+        "user 7 from 192.168.12.200 opened order 6ba7b810-9dad-11d1-80b4-00c04fd430c8 in 12s",
+    }
+    want := "user <NUM> from <IP> opened order <UUID> in <NUM>s"
+    for _, body := range bodies {
+        if got := bodyTemplate(body, defaultBodyTokenPatterns); got != want {
+            t.Errorf("bodyTemplate(%q) = %q, want %q", body, got, want)
+        }
+    }
+    if got := bodyTemplate("flags 0x1F set", defaultBodyTokenPatterns); got != "flags <HEX> set" {
+        t.Errorf("hex template = %q", got)
+    }
+}
+
+func TestBodyTokenPatternsConfigurable(t *testing.T) {
+    patterns, err := parseBodyTokenPatterns(`ORDER=ord-[a-z0-9]+; NUM=\d+`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := bodyTemplate("ord-a1b2 shipped 3 items", patterns); got != "<ORDER> shipped <NUM> items" {
+        t.Errorf("custom template = %q", got)
+    }
+
+    for _, value := range []string{"NUM", "=\\d+", "NUM=("} {
+        if _, err := parseBodyTokenPatterns(value); err == nil {
+            t.Errorf("parseBodyTokenPatterns(%q) succeeded, want error", value)
+        }
+    }
+}
+
+func TestEntrySpanCarriesBodyTemplate(t *testing.T) {
+    cfg := config{BodyTemplate: true, BodyTokenPatterns: defaultBodyTokenPatterns}
+    span := recordEntrySpan(t, LogEntry{Body: "retry 3 of 5"}, cfg)
+    AssertSpanAttribute(t, span, "log.body.template", "retry <NUM> of <NUM>")
+}
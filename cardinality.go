@@ -0,0 +1,79 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+)
+
+// Count the distinct values seen for each attribute key across entries
+func CardinalityReport(entries []LogEntry) map[string]int {
+    values := make(map[string]map[string]struct{})
+    for _, l := range entries {
+        for k, v := range l.Attributes {
+            if values[k] == nil {
+                values[k] = make(map[string]struct{})
+            }
+            values[k][v] = struct{}{}
+        }
+    }
+
+    report := make(map[string]int, len(values))
+    for k, distinct := range values {
+        report[k] = len(distinct)
+    }
+    return report
+}
+
+// Render a cardinality report one key per line, highest cardinality first
+// and ties ordered by key
+func formatCardinalityReport(report map[string]int) string {
+    keys := make([]string, 0, len(report))
+    for k := range report {
+        keys = append(keys, k)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        if report[keys[i]] != report[keys[j]] {
+            return report[keys[i]] > report[keys[j]]
+        }
+        return keys[i] < keys[j]
+    })
+
+    var b strings.Builder
+    for _, k := range keys {
+        fmt.Fprintf(&b, "%s\t%d\n", k, report[k])
+    }
+    return b.String()
+}
+
+// Write the formatted cardinality report of a file of log entries, one JSON
+// object per line decoded with decodeLogEntry, to w
+func writeCardinalityReport(w io.Writer, path string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    var entries []LogEntry
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(nil, maxEntryLineSize)
+    for line := 1; scanner.Scan(); line++ {
+        if strings.TrimSpace(scanner.Text()) == "" {
+            continue
+        }
+        l, err := decodeLogEntry(scanner.Bytes())
+        if err != nil {
+            return fmt.Errorf("%s:%d: %w", path, line, err)
+        }
+        entries = append(entries, l)
+    }
+    if err := scanner.Err(); err != nil {
+        return fmt.Errorf("%s: %w", path, err)
+    }
+    _, err = io.WriteString(w, formatCardinalityReport(CardinalityReport(entries)))
+    return err
+}
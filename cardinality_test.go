@@ -0,0 +1,47 @@
+package main
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestCardinalityReport(t *testing.T) {
+    entries := []LogEntry{
+        {Attributes: map[string]string{"http.method": "GET", "user.id": "1", "region": "eu"}},
+        {Attributes: map[string]string{"http.method": "POST", "user.id": "2", "region": "eu"}},
+        {Attributes: map[string]string{"http.method": "GET", "user.id": "3"}},
+    }
+    report := CardinalityReport(entries)
+    want := map[string]int{"http.method": 2, "user.id": 3, "region": 1}
+    if len(report) != len(want) {
+        t.Fatalf("report = %v, want %v", report, want)
+    }
+    for k, n := range want {
+        if report[k] != n {
+            t.Errorf("%s: %d distinct values, want %d", k, report[k], n)
+        }
+    }
+    if got := formatCardinalityReport(report); got != "user.id\t3\nhttp.method\t2\nregion\t1\n" {
+        t.Errorf("formatted report %q", got)
+    }
+}
+
+func TestWriteCardinalityReport(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "entries.jsonl")
+    data := `{"Attributes":{"http.method":"GET"}}
+
+{"Attributes":{"http.method":"PUT"}}
+`
+    if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    var out bytes.Buffer
+    if err := writeCardinalityReport(&out, path); err != nil {
+        t.Fatal(err)
+    }
+    if out.String() != "http.method\t2\n" {
+        t.Errorf("report %q", out.String())
+    }
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const defaultCircuitBreakerCooldown = 30 * time.Second
+
+// Returned instead of exporting while the circuit is open
+var errCircuitOpen = errors.New("exporter circuit breaker open, spans dropped")
+
+type circuitState int
+
+const (
+    circuitClosed circuitState = iota
+    circuitOpen
+    circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+    switch s {
+    case circuitOpen:
+        return "open"
+    case circuitHalfOpen:
+        return "half-open"
+    }
+    return "closed"
+}
+
+// Exporter wrapper that stops exporting (tripping open) after maxFailures
+// consecutive failed exports. Once cooldown has passed a single trial export
+// is let through (half-open): success closes the circuit, failure opens it
+// for another cooldown. State changes are logged.
+type circuitBreakerExporter struct {
+    trace.SpanExporter
+    maxFailures int
+    cooldown    time.Duration
+    now         func() time.Time
+
+    mu       sync.Mutex
+    state    circuitState
+    failures int
+    openedAt time.Time
+    trial    bool
+}
+
+func newCircuitBreakerExporter(exporter trace.SpanExporter, maxFailures int, cooldown time.Duration) *circuitBreakerExporter {
+    if cooldown <= 0 {
+        cooldown = defaultCircuitBreakerCooldown
+    }
+    return &circuitBreakerExporter{SpanExporter: exporter, maxFailures: maxFailures, cooldown: cooldown, now: time.Now}
+}
+
+// Current state of the circuit, reported by observeCircuitState
+func (e *circuitBreakerExporter) State() circuitState {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    return e.state
+}
+
+func (e *circuitBreakerExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if !e.allow() {
+        return errCircuitOpen
+    }
+    err := e.SpanExporter.ExportSpans(ctx, spans)
+    e.record(err)
+    return err
+}
+
+// Report whether an export may go ahead, moving an open circuit whose
+// cooldown has passed to half-open. Only one trial runs while half-open.
+func (e *circuitBreakerExporter) allow() bool {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    switch e.state {
+    case circuitOpen:
+        if e.now().Sub(e.openedAt) < e.cooldown {
+            return false
+        }
+        e.setState(circuitHalfOpen)
+        e.trial = true
+        return true
+    case circuitHalfOpen:
+        if e.trial {
+            return false
+        }
+        e.trial = true
+        return true
+    }
+    return true
+}
+
+func (e *circuitBreakerExporter) record(err error) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.trial = false
+    if err == nil {
+        e.failures = 0
+        e.setState(circuitClosed)
+        return
+    }
+    e.failures++
+    if e.state == circuitHalfOpen || e.failures >= e.maxFailures {
+        e.openedAt = e.now()
+        e.setState(circuitOpen)
+    }
+}
+
+func (e *circuitBreakerExporter) setState(state circuitState) {
+    if state == e.state {
+        return
+    }
+    log.Printf("Exporter circuit breaker %s -> %s%s", e.state, state, e.reason(state))
+    e.state = state
+}
+
+func (e *circuitBreakerExporter) reason(state circuitState) string {
+    if state == circuitOpen {
+        return fmt.Sprintf(" after %d consecutive failures, retrying in %s", e.failures, e.cooldown)
+    }
+    return ""
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/metric"
+    "go.opentelemetry.io/otel/sdk/metric/metricdata"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Exporter failing while fail is set
+type flakyExporter struct {
+    tracetest.InMemoryExporter
+    fail  bool
+    calls int
+}
+
+func (e *flakyExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    e.calls++
+    if e.fail {
+        return errors.New("collector down")
+    }
+    return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+    inner := &flakyExporter{fail: true}
+    breaker := newCircuitBreakerExporter(inner, 2, time.Minute)
+    now := time.Unix(1700000000, 0)
+    breaker.now = func() time.Time { return now }
+    spans := tracetest.SpanStubs{{Name: "op"}}.Snapshots()
+    ctx := context.Background()
+
+    breaker.ExportSpans(ctx, spans)
+    if got := breaker.State(); got != circuitClosed {
+        t.Fatalf("after one failure: %s, want closed", got)
+    }
+    breaker.ExportSpans(ctx, spans)
+    if got := breaker.State(); got != circuitOpen {
+        t.Fatalf("after two failures: %s, want open", got)
+    }
+    if err := breaker.ExportSpans(ctx, spans); !errors.Is(err, errCircuitOpen) {
+        t.Fatalf("open circuit exported: %v", err)
+    }
+    if inner.calls != 2 {
+        t.Errorf("open circuit reached the exporter: %d calls", inner.calls)
+    }
+
+    // The trial after the cooldown fails and reopens the circuit
+    now = now.Add(time.Minute)
+    breaker.ExportSpans(ctx, spans)
+    if got := breaker.State(); got != circuitOpen {
+        t.Fatalf("after a failed trial: %s, want open", got)
+    }
+
+    // A successful trial closes it
+    now = now.Add(time.Minute)
+    inner.fail = false
+    if err := breaker.ExportSpans(ctx, spans); err != nil {
+        t.Fatalf("trial export: %v", err)
+    }
+    if got := breaker.State(); got != circuitClosed {
+        t.Fatalf("after a successful trial: %s, want closed", got)
+    }
+}
+
+func TestObserveCircuitState(t *testing.T) {
+    reader := metric.NewManualReader()
+    provider := metric.NewMeterProvider(metric.WithReader(reader))
+    state := circuitHalfOpen
+    if err := observeCircuitState(provider.Meter("test"), func() circuitState { return state }); err != nil {
+        t.Fatal(err)
+    }
+
+    var rm metricdata.ResourceMetrics
+    if err := reader.Collect(context.Background(), &rm); err != nil {
+        t.Fatal(err)
+    }
+    gauge, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64])
+    if !ok || len(gauge.DataPoints) != 1 {
+        t.Fatalf("unexpected data: %#v", rm.ScopeMetrics[0].Metrics[0].Data)
+    }
+    if got := gauge.DataPoints[0].Value; got != int64(circuitHalfOpen) {
+        t.Errorf("gauge = %d, want %d", got, circuitHalfOpen)
+    }
+}
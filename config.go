@@ -0,0 +1,573 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/url"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+// Default truncation length for exception.stacktrace, independent of the
+// general attribute value limit applied by the SDK.
+const defaultStacktraceLimit = 8 * 1024
+
+type config struct {
+    TracesExporter     string            `json:"traces_exporter"`
+    ZipkinEndpoint     string            `json:"zipkin_endpoint"`
+    KafkaBrokers       []string          `json:"kafka_brokers,omitempty"`
+    KafkaTopic         string            `json:"kafka_topic"`
+    ProtoFilePath      string            `json:"proto_file_path"`
+    ProtoFileEncoding  exportEncoding    `json:"proto_file_encoding"`
+    XRayDaemonAddress  string            `json:"xray_daemon_address"`
+    WebSocketAddress   string            `json:"websocket_address"`
+    WebSocketOrigins   []string          `json:"websocket_origins"`
+    ElasticsearchURL   string            `json:"elasticsearch_url"`
+    ElasticsearchIndex string            `json:"elasticsearch_index"`
+    ESMappingFile      string            `json:"elasticsearch_mapping"`
+    SpanProcessor      string            `json:"span_processor"`
+    MicroBatchInterval time.Duration     `json:"micro_batch_interval"`
+    MicroBatchMaxSize  int               `json:"micro_batch_max_size"`
+    FlushAlignInterval time.Duration     `json:"flush_align_interval"`
+    StacktraceLimit    int               `json:"stacktrace_limit"`
+    MaxEntryEvents     int               `json:"max_entry_events"`
+    MaxExceptionChain  int               `json:"max_exception_chain"`
+    SpanNameStrategy   spanNameStrategy  `json:"span_name_strategy"`
+    HostInterface      string            `json:"host_interface"`
+    HostnameTemplate   *hostnameTemplate `json:"-"`
+    HostnameSources    []HostnameSource  `json:"-"`
+    SamplingRatio      float64           `json:"sampling_ratio"`
+    SamplingAdjusted   bool              `json:"sampling_adjusted_count"`
+    TraceShape         bool              `json:"trace_shape_attributes"`
+    TraceDuration      bool              `json:"trace_duration_attribute"`
+    MaskIDs            bool              `json:"mask_ids"`
+    RedactIDs          bool              `json:"redact_ids"`
+    SlowSpanThreshold  time.Duration     `json:"slow_span_threshold"`
+    SlowSpanPrefixes   []prefixThreshold `json:"-"`
+    SLORules           sloRules          `json:"-"`
+    ExportConcurrency  int               `json:"export_concurrency"`
+    ExportSequence     bool              `json:"export_sequence"`
+    ExportBatchSpans   bool              `json:"export_batch_spans"`
+    StatsDAddress      string            `json:"statsd_address"`
+    StatsDPrefix       string            `json:"statsd_prefix"`
+    StatsDNameKeys     []string          `json:"statsd_name_keys,omitempty"`
+    StatsDSpanNames    []string          `json:"statsd_span_names,omitempty"`
+    DownsampleWindow   time.Duration     `json:"downsample_window"`
+    DownsampleKeys     []string          `json:"downsample_keys,omitempty"`
+    BreakerFailures    int               `json:"circuit_breaker_failures"`
+    BreakerCooldown    time.Duration     `json:"circuit_breaker_cooldown"`
+    ResourceValueLimit int               `json:"resource_value_limit"`
+    ResourcePreset     string            `json:"resource_preset"`
+    ResourceRetries    int               `json:"resource_detection_retries"`
+    ResourceBackoff    time.Duration     `json:"resource_detection_backoff"`
+    ResourceDropKeys   []string          `json:"dropped_resource_keys,omitempty"`
+    ValidateResource   bool              `json:"validate_resource_conventions"`
+    DropRules          []dropRule        `json:"drop_rules,omitempty"`
+    FieldMapping       map[string]string `json:"log_field_mapping,omitempty"`
+    LogEntryIndent     string            `json:"log_entry_indent"`
+    StatusTemplate     string            `json:"status_template"`
+    InheritedKeys      []string          `json:"inherited_attributes,omitempty"`
+    AnnotationPattern  *regexp.Regexp    `json:"-"`
+    BodyTemplate       bool              `json:"body_template"`
+    BodyTokenPatterns  []tokenPattern    `json:"-"`
+    AggregateRepeated  bool              `json:"aggregate_repeated_attributes"`
+    AttributeKeyCase   keyCase           `json:"attribute_key_case"`
+    InlineResource     bool              `json:"inline_resource_attributes"`
+    TraceMarshalling   bool              `json:"trace_marshalling"`
+    SampleByRequestID  bool              `json:"sample_by_request_id"`
+    TraceIDFromRequest bool              `json:"trace_id_from_request_id"`
+    SamplingPriority   bool              `json:"honor_sampling_priority"`
+    WarnDroppedErrors  bool              `json:"warn_dropped_errors"`
+    AttributeAllowlist []string          `json:"attribute_allowlist,omitempty"`
+    AttributeDenylist  []string          `json:"attribute_denylist,omitempty"`
+    TruncateRules      []truncateRule    `json:"-"`
+    SpanKindRules      []spanKindRule    `json:"-"`
+    ZeroDurationPolicy durationPolicy    `json:"zero_duration_policy"`
+    MinSpanDuration    time.Duration     `json:"min_span_duration"`
+    ClockOffset        time.Duration     `json:"clock_offset"`
+    SessionSummary     bool              `json:"session_summary"`
+    LogRecordMetrics   bool              `json:"log_record_metrics"`
+    LogMetricLabels    []string          `json:"log_metric_labels,omitempty"`
+    PipelineMetrics    bool              `json:"pipeline_metrics"`
+    GeoIPDatabase      string            `json:"geoip_database"`
+    ProcessorOrder     []string          `json:"span_processor_order,omitempty"`
+    SpanWALPath        string            `json:"span_wal_path"`
+    OperationKeys      []string          `json:"split_operation_keys,omitempty"`
+    TimestampPrecision timePrecision     `json:"timestamp_precision"`
+    SamplingTargetRate float64           `json:"sampling_target_rate"`
+    SamplingInterval   time.Duration     `json:"sampling_adjust_interval"`
+    CheckEndpoints     bool              `json:"check_endpoints"`
+    ExporterWarmup     bool              `json:"exporter_warmup"`
+    WarmupTimeout      time.Duration     `json:"exporter_warmup_timeout"`
+    Timezone           *time.Location    `json:"-"`
+}
+
+// Load configuration from environment variables, falling back to defaults
+func loadConfig() config {
+    cfg := config{
+        TracesExporter:     envString("OTEL_TRACES_EXPORTER", "console"),
+        ZipkinEndpoint:     envString("OTEL_EXPORTER_ZIPKIN_ENDPOINT", defaultZipkinEndpoint),
+        KafkaBrokers:       envList("KAFKA_BROKERS"),
+        KafkaTopic:         envString("KAFKA_TOPIC", defaultKafkaTopic),
+        ProtoFilePath:      envString("PROTO_FILE_PATH", defaultProtoFilePath),
+        ProtoFileEncoding:  envExportEncoding("PROTO_FILE_ENCODING", encodingProtobuf),
+        XRayDaemonAddress:  envString("AWS_XRAY_DAEMON_ADDRESS", defaultXRayDaemonAddress),
+        WebSocketAddress:   envString("WEBSOCKET_ADDRESS", defaultWebSocketAddress),
+        WebSocketOrigins:   envList("WEBSOCKET_ALLOWED_ORIGINS"),
+        ElasticsearchURL:   envString("ELASTICSEARCH_URL", defaultElasticsearchEndpoint),
+        ElasticsearchIndex: envString("ELASTICSEARCH_INDEX", defaultElasticsearchIndex),
+        ESMappingFile:      os.Getenv("ELASTICSEARCH_MAPPING"),
+        SpanProcessor:      envString("SPAN_PROCESSOR", "batch"),
+        MicroBatchInterval: envDuration("MICROBATCH_INTERVAL", defaultMicroBatchInterval),
+        MicroBatchMaxSize:  envInt("MICROBATCH_MAX_SIZE", defaultMicroBatchMaxSize),
+        FlushAlignInterval: envDuration("FLUSH_ALIGN_INTERVAL", 0),
+        StacktraceLimit:    envInt("EXCEPTION_STACKTRACE_LIMIT", defaultStacktraceLimit),
+        MaxEntryEvents:     envInt("MAX_ENTRY_EVENTS", defaultMaxEntryEvents),
+        MaxExceptionChain:  envInt("MAX_EXCEPTION_CHAIN", defaultMaxExceptionChain),
+        SpanNameStrategy:   envSpanNameStrategy("SPAN_NAME_STRATEGY", spanNameFromEvent),
+        HostInterface:      os.Getenv("HOST_INTERFACE"),
+        HostnameTemplate:   envHostnameTemplate("HOSTNAME_TEMPLATE"),
+        HostnameSources:    envHostnameSources("HOSTNAME_SOURCES"),
+        SamplingRatio:      envFloat("SAMPLING_RATIO", 1),
+        SamplingAdjusted:   envBool("SAMPLING_ADJUSTED_COUNT", false),
+        TraceShape:         envBool("TRACE_SHAPE_ATTRIBUTES", false),
+        TraceDuration:      envBool("TRACE_DURATION_ATTRIBUTE", false),
+        MaskIDs:            envBool("MASK_IDS", false),
+        RedactIDs:          envBool("REDACT_IDS", false),
+        SlowSpanThreshold:  envDuration("SLOW_SPAN_THRESHOLD", 0),
+        SlowSpanPrefixes:   envPrefixThresholds("SLOW_SPAN_THRESHOLDS"),
+        SLORules:           envSLORules("SPAN_LATENCY_SLOS"),
+        ExportConcurrency:  envInt("EXPORT_CONCURRENCY", 0),
+        ExportSequence:     envBool("EXPORT_SEQUENCE", false),
+        ExportBatchSpans:   envBool("EXPORT_BATCH_SPANS", false),
+        StatsDAddress:      os.Getenv("STATSD_ADDRESS"),
+        StatsDPrefix:       envString("STATSD_PREFIX", defaultStatsDPrefix),
+        StatsDNameKeys:     envList("STATSD_NAME_KEYS"),
+        StatsDSpanNames:    envList("STATSD_SPAN_NAMES"),
+        DownsampleWindow:   envDuration("DOWNSAMPLE_WINDOW", 0),
+        DownsampleKeys:     envList("DOWNSAMPLE_KEYS"),
+        BreakerFailures:    envInt("CIRCUIT_BREAKER_FAILURES", 0),
+        BreakerCooldown:    envDuration("CIRCUIT_BREAKER_COOLDOWN", defaultCircuitBreakerCooldown),
+        ResourceValueLimit: envInt("RESOURCE_ATTRIBUTE_VALUE_LIMIT", defaultResourceValueLimit),
+        ResourcePreset:     os.Getenv("RESOURCE_PRESET"),
+        ResourceRetries:    envInt("RESOURCE_DETECTION_RETRIES", defaultResourceDetectionRetries),
+        ResourceBackoff:    envDuration("RESOURCE_DETECTION_BACKOFF", defaultResourceDetectionBackoff),
+        ResourceDropKeys:   envList("DROP_RESOURCE_KEYS"),
+        ValidateResource:   envBool("VALIDATE_RESOURCE_CONVENTIONS", false),
+        DropRules:          envDropRules("DROP_ENTRY_RULES"),
+        FieldMapping:       envFieldMapping("LOG_FIELD_MAPPING"),
+        LogEntryIndent:     envIndent("LOG_ENTRY_INDENT", "  "),
+        StatusTemplate:     os.Getenv("SPAN_STATUS_TEMPLATE"),
+        InheritedKeys:      envList("INHERITED_ATTRIBUTES"),
+        AnnotationPattern:  envAnnotationPattern("BODY_ANNOTATION_PATTERN", defaultAnnotationPattern),
+        BodyTemplate:       envBool("BODY_TEMPLATE", false),
+        BodyTokenPatterns:  envBodyTokenPatterns("BODY_TEMPLATE_PATTERNS"),
+        AggregateRepeated:  envBool("AGGREGATE_REPEATED_ATTRIBUTES", false),
+        AttributeKeyCase:   envKeyCase("ATTRIBUTE_KEY_CASE", keyCaseNone),
+        InlineResource:     envBool("INLINE_RESOURCE_ATTRIBUTES", false),
+        TraceMarshalling:   envBool("TRACE_MARSHALLING", false),
+        SampleByRequestID:  envBool("SAMPLE_BY_REQUEST_ID", false),
+        TraceIDFromRequest: envBool("TRACE_ID_FROM_REQUEST_ID", false),
+        SamplingPriority:   envBool("HONOR_SAMPLING_PRIORITY", false),
+        WarnDroppedErrors:  envBool("WARN_DROPPED_ERRORS", false),
+        AttributeAllowlist: envList("ATTRIBUTE_ALLOWLIST"),
+        AttributeDenylist:  envList("ATTRIBUTE_DENYLIST"),
+        TruncateRules:      envTruncateRules("ATTRIBUTE_TRUNCATION"),
+        SpanKindRules:      envSpanKindRules("SPAN_KIND_RULES"),
+        ZeroDurationPolicy: envZeroDurationPolicy("ZERO_DURATION_POLICY", zeroDurationKeep),
+        MinSpanDuration:    envDuration("MIN_SPAN_DURATION", defaultMinSpanDuration),
+        ClockOffset:        envDuration("CLOCK_OFFSET", 0),
+        SessionSummary:     envBool("SESSION_SUMMARY", false),
+        LogRecordMetrics:   envBool("LOG_RECORD_METRICS", false),
+        LogMetricLabels:    envList("LOG_METRIC_LABELS"),
+        PipelineMetrics:    envBool("PIPELINE_METRICS", false),
+        GeoIPDatabase:      os.Getenv("GEOIP_DATABASE"),
+        ProcessorOrder:     envList("SPAN_PROCESSOR_ORDER"),
+        SpanWALPath:        os.Getenv("SPAN_WAL_PATH"),
+        OperationKeys:      envList("SPLIT_OPERATION_KEYS"),
+        TimestampPrecision: envTimestampPrecision("TIMESTAMP_PRECISION", timestampNano),
+        SamplingTargetRate: envFloat("SAMPLING_TARGET_RATE", 0),
+        SamplingInterval:   envDuration("SAMPLING_ADJUST_INTERVAL", defaultSamplingAdjustInterval),
+        CheckEndpoints:     envBool("CHECK_ENDPOINTS", false),
+        ExporterWarmup:     envBool("EXPORTER_WARMUP", false),
+        WarmupTimeout:      envDuration("EXPORTER_WARMUP_TIMEOUT", defaultWarmupTimeout),
+        Timezone:           envLocation("LOG_ENTRY_TIMEZONE"),
+    }
+    if err := validateCombination(cfg); err != nil {
+        log.Fatal(err)
+    }
+    return cfg
+}
+
+// Check for settings that are each valid but make no sense together:
+//   - ATTRIBUTE_ALLOWLIST with ATTRIBUTE_DENYLIST, as only one filter mode
+//     can apply
+//   - TRACE_ID_FROM_REQUEST_ID with the xray exporter, whose trace IDs must
+//     start with a timestamp that hashed IDs lack
+//   - FLUSH_ALIGN_INTERVAL with SPAN_PROCESSOR=sync, which exports every span
+//     as it ends and so has nothing to flush
+//   - DOWNSAMPLE_KEYS without DOWNSAMPLE_WINDOW, which leaves downsampling off
+//   - REDACT_IDS with the xray exporter, as the random replacement IDs lose
+//     the timestamp X-Ray trace IDs start with
+//   - SAMPLING_TARGET_RATE with SAMPLING_RATIO=0, as the adaptive sampler
+//     only ever lowers the configured ratio and so never samples anything
+//   - STATSD_ADDRESS with SAMPLING_RATIO below 1 or SAMPLING_TARGET_RATE, as
+//     the StatsD counters only see sampled spans and undercount traffic
+//   - EXPORTER_WARMUP with an exporter that opens no connection (console,
+//     protofile, xray, websocket), which has nothing to warm up
+func validateCombination(cfg config) error {
+    var errs []error
+    if len(cfg.AttributeAllowlist) > 0 && len(cfg.AttributeDenylist) > 0 {
+        errs = append(errs, errors.New("ATTRIBUTE_ALLOWLIST and ATTRIBUTE_DENYLIST cannot both be set"))
+    }
+    if cfg.TraceIDFromRequest && cfg.TracesExporter == "xray" {
+        errs = append(errs, errors.New("TRACE_ID_FROM_REQUEST_ID cannot be used with the xray exporter, which needs timestamped trace IDs"))
+    }
+    if cfg.FlushAlignInterval > 0 && cfg.SpanProcessor == "sync" {
+        errs = append(errs, errors.New("FLUSH_ALIGN_INTERVAL has no effect with SPAN_PROCESSOR=sync"))
+    }
+    if len(cfg.DownsampleKeys) > 0 && cfg.DownsampleWindow <= 0 {
+        errs = append(errs, errors.New("DOWNSAMPLE_KEYS requires DOWNSAMPLE_WINDOW"))
+    }
+    if cfg.RedactIDs && cfg.TracesExporter == "xray" {
+        errs = append(errs, errors.New("REDACT_IDS cannot be used with the xray exporter, which needs timestamped trace IDs"))
+    }
+    if cfg.SamplingTargetRate > 0 && cfg.SamplingRatio <= 0 {
+        errs = append(errs, errors.New("SAMPLING_TARGET_RATE cannot raise SAMPLING_RATIO=0, so no span would be sampled"))
+    }
+    if cfg.StatsDAddress != "" && (cfg.SamplingRatio < 1 || cfg.SamplingTargetRate > 0) {
+        errs = append(errs, errors.New("STATSD_ADDRESS counts only sampled spans and cannot be used with SAMPLING_RATIO below 1 or SAMPLING_TARGET_RATE"))
+    }
+    if cfg.ExporterWarmup && exporterEndpoints(cfg) == nil {
+        errs = append(errs, fmt.Errorf("EXPORTER_WARMUP has no effect with the %s exporter, which opens no connection", cfg.TracesExporter))
+    }
+    return errors.Join(errs...)
+}
+
+// Write the effective configuration and resource attributes as JSON, with
+// credentials redacted
+func printConfig(w io.Writer, cfg config, resourceAttributes []attribute.KeyValue) error {
+    cfg.ZipkinEndpoint = redactURL(cfg.ZipkinEndpoint)
+    cfg.ElasticsearchURL = redactURL(cfg.ElasticsearchURL)
+
+    attrs := make(map[string]string, len(resourceAttributes))
+    for _, kv := range resourceAttributes {
+        attrs[string(kv.Key)] = kv.Value.Emit()
+    }
+
+    // Outer fields shadow the embedded ones of the same JSON name
+    effective := struct {
+        config
+        MicroBatchInterval string            `json:"micro_batch_interval"`
+        FlushAlignInterval string            `json:"flush_align_interval"`
+        ResourceBackoff    string            `json:"resource_detection_backoff"`
+        BreakerCooldown    string            `json:"circuit_breaker_cooldown"`
+        DownsampleWindow   string            `json:"downsample_window"`
+        SlowSpanThreshold  string            `json:"slow_span_threshold"`
+        SlowSpanThresholds string            `json:"slow_span_thresholds,omitempty"`
+        SLORules           string            `json:"span_latency_slos,omitempty"`
+        AnnotationPattern  string            `json:"body_annotation_pattern"`
+        BodyTokenPatterns  string            `json:"body_template_patterns,omitempty"`
+        SpanKindRules      string            `json:"span_kind_rules,omitempty"`
+        HostnameTemplate   string            `json:"hostname_template"`
+        HostnameSources    string            `json:"hostname_sources,omitempty"`
+        TruncateRules      string            `json:"attribute_truncation,omitempty"`
+        MinSpanDuration    string            `json:"min_span_duration"`
+        ClockOffset        string            `json:"clock_offset"`
+        SamplingInterval   string            `json:"sampling_adjust_interval"`
+        WarmupTimeout      string            `json:"exporter_warmup_timeout"`
+        Timezone           string            `json:"log_entry_timezone,omitempty"`
+        ResourceAttributes map[string]string `json:"resource_attributes"`
+    }{
+        config:             cfg,
+        MicroBatchInterval: cfg.MicroBatchInterval.String(),
+        FlushAlignInterval: cfg.FlushAlignInterval.String(),
+        ResourceBackoff:    cfg.ResourceBackoff.String(),
+        BreakerCooldown:    cfg.BreakerCooldown.String(),
+        DownsampleWindow:   cfg.DownsampleWindow.String(),
+        SlowSpanThreshold:  cfg.SlowSpanThreshold.String(),
+        SlowSpanThresholds: os.Getenv("SLOW_SPAN_THRESHOLDS"),
+        SLORules:           os.Getenv("SPAN_LATENCY_SLOS"),
+        AnnotationPattern:  cfg.AnnotationPattern.String(),
+        BodyTokenPatterns:  os.Getenv("BODY_TEMPLATE_PATTERNS"),
+        SpanKindRules:      os.Getenv("SPAN_KIND_RULES"),
+        HostnameTemplate:   os.Getenv("HOSTNAME_TEMPLATE"),
+        HostnameSources:    os.Getenv("HOSTNAME_SOURCES"),
+        TruncateRules:      os.Getenv("ATTRIBUTE_TRUNCATION"),
+        MinSpanDuration:    cfg.MinSpanDuration.String(),
+        ClockOffset:        cfg.ClockOffset.String(),
+        SamplingInterval:   cfg.SamplingInterval.String(),
+        WarmupTimeout:      cfg.WarmupTimeout.String(),
+        Timezone:           os.Getenv("LOG_ENTRY_TIMEZONE"),
+        ResourceAttributes: attrs,
+    }
+
+    enc := json.NewEncoder(w)
+    enc.SetIndent("", "  ")
+    return enc.Encode(effective)
+}
+
+// Hide the password of a URL with embedded credentials
+func redactURL(raw string) string {
+    u, err := url.Parse(raw)
+    if err == nil && u.Opaque == "" {
+        return u.Redacted()
+    }
+    // Unparseable, or a bare user:password@host read as an opaque URL: mask
+    // anything before the last @, which may be userinfo
+    at := strings.LastIndex(raw, "@")
+    if at < 0 {
+        return raw
+    }
+    scheme := ""
+    if i := strings.Index(raw, "://"); i >= 0 && i < at {
+        scheme = raw[:i+3]
+    }
+    return scheme + "xxxxx" + raw[at:]
+}
+
+func envString(name, def string) string {
+    if value := os.Getenv(name); value != "" {
+        return value
+    }
+    return def
+}
+
+func envInt(name string, def int) int {
+    value, ok := os.LookupEnv(name)
+    if !ok || value == "" {
+        return def
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    return n
+}
+
+func envSpanNameStrategy(name string, def spanNameStrategy) spanNameStrategy {
+    value := os.Getenv(name)
+    switch spanNameStrategy(value) {
+    case "":
+        return def
+    case spanNameFromEvent, spanNameFromException:
+        return spanNameStrategy(value)
+    }
+    log.Fatalf("invalid %s=%q: want %q or %q", name, value, spanNameFromEvent, spanNameFromException)
+    return def
+}
+
+func envZeroDurationPolicy(name string, def durationPolicy) durationPolicy {
+    value := os.Getenv(name)
+    switch durationPolicy(value) {
+    case "":
+        return def
+    case zeroDurationKeep, zeroDurationMin, zeroDurationSkip:
+        return durationPolicy(value)
+    }
+    log.Fatalf("invalid %s=%q: want %q, %q or %q", name, value, zeroDurationKeep, zeroDurationMin, zeroDurationSkip)
+    return def
+}
+
+func envTimestampPrecision(name string, def timePrecision) timePrecision {
+    value := os.Getenv(name)
+    switch timePrecision(value) {
+    case "":
+        return def
+    case timestampNano, timestampMilli, timestampSecond:
+        return timePrecision(value)
+    }
+    log.Fatalf("invalid %s=%q: want %q, %q or %q", name, value, timestampNano, timestampMilli, timestampSecond)
+    return def
+}
+
+func envExportEncoding(name string, def exportEncoding) exportEncoding {
+    value := os.Getenv(name)
+    switch exportEncoding(value) {
+    case "":
+        return def
+    case encodingProtobuf, encodingJSON:
+        return exportEncoding(value)
+    }
+    log.Fatalf("invalid %s=%q: want %q or %q", name, value, encodingProtobuf, encodingJSON)
+    return def
+}
+
+func envKeyCase(name string, def keyCase) keyCase {
+    value := os.Getenv(name)
+    switch keyCase(value) {
+    case "":
+        return def
+    case keyCaseNone, keyCaseLower, keyCaseUpper, keyCaseSnake:
+        return keyCase(value)
+    }
+    log.Fatalf("invalid %s=%q: want %q, %q, %q or %q", name, value, keyCaseNone, keyCaseLower, keyCaseUpper, keyCaseSnake)
+    return def
+}
+
+// Time zone by IANA name (or "Local"); unset means timestamps are left as
+// generated
+func envLocation(name string) *time.Location {
+    value := os.Getenv(name)
+    if value == "" {
+        return nil
+    }
+    loc, err := time.LoadLocation(value)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    return loc
+}
+
+func envFloat(name string, def float64) float64 {
+    value, ok := os.LookupEnv(name)
+    if !ok || value == "" {
+        return def
+    }
+    f, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    return f
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+    value, ok := os.LookupEnv(name)
+    if !ok || value == "" {
+        return def
+    }
+    d, err := time.ParseDuration(value)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    return d
+}
+
+func envBool(name string, def bool) bool {
+    value, ok := os.LookupEnv(name)
+    if !ok || value == "" {
+        return def
+    }
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    return b
+}
+
+func envPrefixThresholds(name string) []prefixThreshold {
+    thresholds, err := parsePrefixThresholds(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return thresholds
+}
+
+func envHostnameTemplate(name string) *hostnameTemplate {
+    value := os.Getenv(name)
+    if value == "" {
+        return nil
+    }
+    template, err := parseHostnameTemplate(value)
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return template
+}
+
+func envSLORules(name string) sloRules {
+    rules, err := parseSLORules(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return rules
+}
+
+func envDropRules(name string) []dropRule {
+    rules, err := parseDropRules(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return rules
+}
+
+func envFieldMapping(name string) map[string]string {
+    mapping, err := parseFieldMapping(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return mapping
+}
+
+// Like envString, but a variable set to the empty string is kept as-is so it
+// can select compact output
+func envIndent(name, def string) string {
+    if value, ok := os.LookupEnv(name); ok {
+        return value
+    }
+    return def
+}
+
+// Comma-separated list, with blank items dropped
+func envList(name string) []string {
+    var items []string
+    for _, item := range strings.Split(os.Getenv(name), ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// Regular expression whose first capture group is the annotation text
+func envAnnotationPattern(name, def string) *regexp.Regexp {
+    value := envString(name, def)
+    re, err := regexp.Compile(value)
+    if err != nil {
+        log.Fatalf("invalid %s=%q: %v", name, value, err)
+    }
+    if re.NumSubexp() < 1 {
+        log.Fatalf("invalid %s=%q: needs a capture group for the annotation", name, value)
+    }
+    return re
+}
+
+func envBodyTokenPatterns(name string) []tokenPattern {
+    patterns, err := parseBodyTokenPatterns(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return patterns
+}
+
+func envTruncateRules(name string) []truncateRule {
+    rules, err := parseTruncateRules(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return rules
+}
+
+func envSpanKindRules(name string) []spanKindRule {
+    rules, err := parseSpanKindRules(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return rules
+}
+
+func envHostnameSources(name string) []HostnameSource {
+    sources, err := parseHostnameSources(os.Getenv(name))
+    if err != nil {
+        log.Fatalf("invalid %s: %v", name, err)
+    }
+    return sources
+}
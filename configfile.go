@@ -0,0 +1,102 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+
+    "github.com/BurntSushi/toml"
+    "gopkg.in/yaml.v3"
+)
+
+// Setting names in a config file: the environment variables loadConfig reads
+var configFileKey = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Read the settings of a JSON, YAML or TOML config file, chosen by its
+// extension. Keys are the environment variable names loadConfig reads;
+// values are strings, numbers, booleans or lists of them, which become the
+// comma-separated form envList parses.
+func readConfigFile(path string) (map[string]string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var raw map[string]any
+    switch ext := strings.ToLower(filepath.Ext(path)); ext {
+    case ".json":
+        err = json.Unmarshal(data, &raw)
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &raw)
+    case ".toml":
+        err = toml.Unmarshal(data, &raw)
+    default:
+        return nil, fmt.Errorf("config file %s: unknown format %q, want .json, .yaml, .yml or .toml", path, ext)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("config file %s: %w", path, err)
+    }
+
+    settings := make(map[string]string, len(raw))
+    for key, value := range raw {
+        if !configFileKey.MatchString(key) {
+            return nil, fmt.Errorf("config file %s: key %q is not an environment variable name", path, key)
+        }
+        s, err := configFileValue(value)
+        if err != nil {
+            return nil, fmt.Errorf("config file %s: %s: %w", path, key, err)
+        }
+        settings[key] = s
+    }
+    return settings, nil
+}
+
+// Environment variable form of a decoded config file value
+func configFileValue(value any) (string, error) {
+    switch v := value.(type) {
+    case string:
+        return v, nil
+    case bool:
+        return strconv.FormatBool(v), nil
+    case int:
+        return strconv.Itoa(v), nil
+    case int64:
+        return strconv.FormatInt(v, 10), nil
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64), nil
+    case []any:
+        items := make([]string, len(v))
+        for i, item := range v {
+            if _, ok := item.([]any); ok {
+                return "", fmt.Errorf("nested lists are not supported")
+            }
+            s, err := configFileValue(item)
+            if err != nil {
+                return "", err
+            }
+            items[i] = s
+        }
+        return strings.Join(items, ","), nil
+    }
+    return "", fmt.Errorf("unsupported value of type %T", value)
+}
+
+// Set the environment variables of a config file that are not already set,
+// so loadConfig reads them with the real environment taking precedence
+func applyConfigFile(path string) error {
+    settings, err := readConfigFile(path)
+    if err != nil {
+        return err
+    }
+    for key, value := range settings {
+        if _, set := os.LookupEnv(key); !set {
+            if err := os.Setenv(key, value); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Config loaded with the settings of a config file set as environment
+// variables for the rest of the test
+func loadConfigFile(t *testing.T, path string) config {
+    t.Helper()
+    settings, err := readConfigFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for key, value := range settings {
+        t.Setenv(key, value)
+    }
+    return loadConfig()
+}
+
+func TestLoadTOMLConfigFile(t *testing.T) {
+    cfg := loadConfigFile(t, filepath.Join("testdata", "config.toml"))
+    if cfg.TracesExporter != "zipkin" || cfg.ZipkinEndpoint != "http://zipkin:9411/api/v2/spans" {
+        t.Errorf("exporter %q at %q", cfg.TracesExporter, cfg.ZipkinEndpoint)
+    }
+    if cfg.SamplingRatio != 0.25 || !cfg.SamplingAdjusted {
+        t.Errorf("sampling ratio %g, adjusted count %v", cfg.SamplingRatio, cfg.SamplingAdjusted)
+    }
+    if cfg.MicroBatchMaxSize != 128 || cfg.MicroBatchInterval != 250*time.Millisecond {
+        t.Errorf("microbatch of %d every %v", cfg.MicroBatchMaxSize, cfg.MicroBatchInterval)
+    }
+    if want := []string{"http.user_agent", "user.email"}; !reflect.DeepEqual(cfg.AttributeDenylist, want) {
+        t.Errorf("denylist = %q, want %q", cfg.AttributeDenylist, want)
+    }
+}
+
+func TestConfigFileFormatsAgree(t *testing.T) {
+    var want config
+    for i, name := range []string{"config.json", "config.yaml", "config.toml"} {
+        t.Run(name, func(t *testing.T) {
+            cfg := loadConfigFile(t, filepath.Join("testdata", name))
+            if i == 0 {
+                want = cfg
+                return
+            }
+            if !reflect.DeepEqual(cfg, want) {
+                t.Errorf("config from %s = %+v, want %+v as from config.json", name, cfg, want)
+            }
+        })
+    }
+}
+
+func TestApplyConfigFileKeepsEnvironment(t *testing.T) {
+    path := filepath.Join("testdata", "config.toml")
+    settings, err := readConfigFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Unset every key, with t.Setenv restoring them after the test
+    for key := range settings {
+        t.Setenv(key, "")
+        os.Unsetenv(key)
+    }
+    t.Setenv("OTEL_TRACES_EXPORTER", "console")
+    if err := applyConfigFile(path); err != nil {
+        t.Fatal(err)
+    }
+    if got := os.Getenv("OTEL_TRACES_EXPORTER"); got != "console" {
+        t.Errorf("OTEL_TRACES_EXPORTER = %q, want the environment's console", got)
+    }
+    if got := os.Getenv("SAMPLING_RATIO"); got != "0.25" {
+        t.Errorf("SAMPLING_RATIO = %q, want 0.25 from the file", got)
+    }
+}
+
+func TestReadConfigFileErrors(t *testing.T) {
+    dir := t.TempDir()
+    tests := []struct{ name, content, want string }{
+        {"config.ini", "OTEL_TRACES_EXPORTER=zipkin", "unknown format"},
+        {"lower.toml", `traces_exporter = "zipkin"`, "not an environment variable name"},
+        {"nested.yaml", "SPAN_KIND_RULES:\n  server: http.\n", "unsupported value"},
+        {"broken.json", `{"OTEL_TRACES_EXPORTER": `, "config file"},
+    }
+    for _, tt := range tests {
+        path := filepath.Join(dir, tt.name)
+        if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+            t.Fatal(err)
+        }
+        if _, err := readConfigFile(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+            t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+        }
+    }
+}
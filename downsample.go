@@ -0,0 +1,96 @@
+package main
+
+import (
+    "context"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Exporter wrapper collapsing runs of consecutive identical sibling spans in
+// a batch into the first span of the run, carrying repeat.count and ending
+// when the last one ended. Spans are identical when they share a trace, a
+// parent, a name and the values of the key attributes; a run is cut once a
+// span starts more than window after the first one. Spans that are the
+// parent of another span in the batch are never collapsed, so no child is
+// left pointing at a removed span.
+type downsampleExporter struct {
+    trace.SpanExporter
+    window time.Duration
+    keys   []attribute.Key
+}
+
+func newDownsampleExporter(exporter trace.SpanExporter, window time.Duration, keys []string) *downsampleExporter {
+    e := &downsampleExporter{SpanExporter: exporter, window: window}
+    for _, k := range keys {
+        e.keys = append(e.keys, attribute.Key(k))
+    }
+    return e
+}
+
+// Collapsed span, ending when the last span of its run ended
+type repeatedSpan struct {
+    trace.ReadOnlySpan
+    end time.Time
+}
+
+func (s repeatedSpan) EndTime() time.Time {
+    return s.end
+}
+
+func (e *downsampleExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    return e.SpanExporter.ExportSpans(ctx, e.downsample(spans))
+}
+
+func (e *downsampleExporter) downsample(spans []trace.ReadOnlySpan) []trace.ReadOnlySpan {
+    parents := make(map[oteltrace.SpanID]bool, len(spans))
+    for _, s := range spans {
+        parents[s.Parent().SpanID()] = true
+    }
+    out := make([]trace.ReadOnlySpan, 0, len(spans))
+    for i := 0; i < len(spans); {
+        first, end := spans[i], spans[i].EndTime()
+        key := e.keyOf(first)
+        j := i + 1
+        for ; j < len(spans) && !parents[first.SpanContext().SpanID()]; j++ {
+            s := spans[j]
+            if !sameRun(first, s) || parents[s.SpanContext().SpanID()] ||
+                s.StartTime().Sub(first.StartTime()) > e.window || e.keyOf(s) != key {
+                break
+            }
+            if s.EndTime().After(end) {
+                end = s.EndTime()
+            }
+        }
+        if n := j - i; n > 1 {
+            out = append(out, repeatedSpan{ReadOnlySpan: withAttributes(first, attribute.Int("repeat.count", n)), end: end})
+        } else {
+            out = append(out, first)
+        }
+        i = j
+    }
+    return out
+}
+
+// Whether b can join a run started by a: siblings of the same name
+func sameRun(a, b trace.ReadOnlySpan) bool {
+    return a.Name() == b.Name() &&
+        a.SpanContext().TraceID() == b.SpanContext().TraceID() &&
+        a.Parent().SpanID() == b.Parent().SpanID()
+}
+
+// Identity of a span's key attribute values
+func (e *downsampleExporter) keyOf(s trace.ReadOnlySpan) attribute.Distinct {
+    var kvs []attribute.KeyValue
+    for _, kv := range s.Attributes() {
+        for _, k := range e.keys {
+            if kv.Key == k {
+                kvs = append(kvs, kv)
+            }
+        }
+    }
+    set := attribute.NewSet(kvs...)
+    return set.Equivalent()
+}
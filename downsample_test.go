@@ -0,0 +1,70 @@
+package main
+
+import (
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func downsampleTestSpan(name string, traceID, id, parent byte, start time.Time) trace.ReadOnlySpan {
+    return tracetest.SpanStub{
+        Name:        name,
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{traceID}, SpanID: oteltrace.SpanID{id}}),
+        Parent:      oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{traceID}, SpanID: oteltrace.SpanID{parent}}),
+        StartTime:   start,
+        EndTime:     start.Add(time.Millisecond),
+    }.Snapshot()
+}
+
+func repeatCount(s trace.ReadOnlySpan) int64 {
+    for _, kv := range s.Attributes() {
+        if kv.Key == "repeat.count" {
+            return kv.Value.AsInt64()
+        }
+    }
+    return 1
+}
+
+func TestDownsampleCollapsesOnlySiblings(t *testing.T) {
+    start := time.Unix(100, 0)
+    e := newDownsampleExporter(tracetest.NewInMemoryExporter(), time.Second, nil)
+    out := e.downsample([]trace.ReadOnlySpan{
+        downsampleTestSpan("query", 1, 1, 9, start),
+        downsampleTestSpan("query", 1, 2, 9, start.Add(time.Millisecond)),
+        downsampleTestSpan("query", 1, 3, 9, start.Add(2*time.Millisecond)),
+        // Same name, another trace
+        downsampleTestSpan("query", 2, 4, 9, start.Add(3*time.Millisecond)),
+        // Same name, another parent
+        downsampleTestSpan("query", 2, 5, 8, start.Add(4*time.Millisecond)),
+    })
+    if len(out) != 3 {
+        t.Fatalf("got %d spans, want 3", len(out))
+    }
+    if got := repeatCount(out[0]); got != 3 {
+        t.Errorf("repeat.count = %d, want 3", got)
+    }
+    if want := start.Add(3 * time.Millisecond); !out[0].EndTime().Equal(want) {
+        t.Errorf("collapsed span ends at %v, want %v", out[0].EndTime(), want)
+    }
+    for _, s := range out[1:] {
+        if repeatCount(s) != 1 {
+            t.Errorf("span %s of another trace or parent was collapsed", s.SpanContext().SpanID())
+        }
+    }
+}
+
+func TestDownsampleKeepsParents(t *testing.T) {
+    start := time.Unix(100, 0)
+    e := newDownsampleExporter(tracetest.NewInMemoryExporter(), time.Second, []string{"k"})
+    out := e.downsample([]trace.ReadOnlySpan{
+        downsampleTestSpan("step", 1, 1, 9, start),
+        downsampleTestSpan("step", 1, 2, 9, start),
+        downsampleTestSpan("child", 1, 3, 2, start),
+    })
+    if len(out) != 3 {
+        t.Fatalf("got %d spans, want 3: the parent of child must not be collapsed", len(out))
+    }
+}
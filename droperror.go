@@ -0,0 +1,73 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Minimum time between two dropped-error warnings
+const droppedErrorWarnInterval = time.Minute
+
+// Sampler recording the spans the wrapped sampler drops instead of
+// discarding them, so droppedErrorProcessor can see their status when they
+// end. They are still not sampled and so never exported.
+type recordDroppedSampler struct {
+    next trace.Sampler
+}
+
+func (s recordDroppedSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    result := s.next.ShouldSample(p)
+    if result.Decision == trace.Drop {
+        result.Decision = trace.RecordOnly
+    }
+    return result
+}
+
+func (s recordDroppedSampler) Description() string {
+    return fmt.Sprintf("RecordDropped/%s", s.next.Description())
+}
+
+// Span processor warning when a span ending with an error status was not
+// sampled, as its error will not reach the backend. At most one warning is
+// logged per interval, counting the drops it stood in for.
+type droppedErrorProcessor struct {
+    interval time.Duration
+
+    mu         sync.Mutex
+    lastWarned time.Time
+    suppressed int
+}
+
+func newDroppedErrorProcessor(interval time.Duration) *droppedErrorProcessor {
+    return &droppedErrorProcessor{interval: interval}
+}
+
+func (p *droppedErrorProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *droppedErrorProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if s.SpanContext().IsSampled() || s.Status().Code != codes.Error {
+        return
+    }
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    now := time.Now()
+    if !p.lastWarned.IsZero() && now.Sub(p.lastWarned) < p.interval {
+        p.suppressed++
+        return
+    }
+    if p.suppressed > 0 {
+        log.Printf("Warning: sampling dropped error span %q (and %d more since the last warning); consider HONOR_SAMPLING_PRIORITY or a higher SAMPLING_RATIO", s.Name(), p.suppressed)
+    } else {
+        log.Printf("Warning: sampling dropped error span %q; consider HONOR_SAMPLING_PRIORITY or a higher SAMPLING_RATIO", s.Name())
+    }
+    p.lastWarned, p.suppressed = now, 0
+}
+
+func (p *droppedErrorProcessor) Shutdown(context.Context) error   { return nil }
+func (p *droppedErrorProcessor) ForceFlush(context.Context) error { return nil }
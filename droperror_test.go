@@ -0,0 +1,64 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "log"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestDroppedErrorSpanWarns(t *testing.T) {
+    var logs bytes.Buffer
+    saved := log.Writer()
+    log.SetOutput(&logs)
+    defer log.SetOutput(saved)
+
+    tp := trace.NewTracerProvider(
+        trace.WithSampler(recordDroppedSampler{next: trace.NeverSample()}),
+        trace.WithSpanProcessor(newDroppedErrorProcessor(droppedErrorWarnInterval)),
+    )
+    tracer := tp.Tracer("test")
+    for i := 0; i < 3; i++ {
+        _, span := tracer.Start(context.Background(), "checkout")
+        span.SetStatus(codes.Error, "payment declined")
+        span.End()
+    }
+    _, span := tracer.Start(context.Background(), "browse")
+    span.End()
+
+    if n := strings.Count(logs.String(), "sampling dropped error span"); n != 1 {
+        t.Errorf("%d warnings, want 1 within the interval:\n%s", n, logs.String())
+    }
+}
+
+func TestRecordedOnlySpansAreNotCounted(t *testing.T) {
+    stats := newSessionStats()
+    next := &collectingProcessor{}
+    duration := newTraceDurationProcessor(next)
+    tp := trace.NewTracerProvider(
+        trace.WithSampler(recordDroppedSampler{next: trace.NeverSample()}),
+        trace.WithSpanProcessor(newTraceShapeProcessor()),
+        trace.WithSpanProcessor(stats),
+        trace.WithSpanProcessor(duration),
+    )
+    ctx, root := tp.Tracer("test").Start(context.Background(), "root")
+    _, child := tp.Tracer("test").Start(ctx, "child")
+    child.End()
+    root.End()
+
+    if n := stats.created.Load(); n != 0 {
+        t.Errorf("session counted %d unsampled spans", n)
+    }
+    if len(duration.traces) != 0 || len(next.spans) != 2 {
+        t.Errorf("trace duration held %d traces and passed on %d spans, want 0 and 2", len(duration.traces), len(next.spans))
+    }
+    for _, s := range next.spans {
+        if len(s.Attributes()) != 0 {
+            t.Errorf("unsampled span %q has attributes %v", s.Name(), s.Attributes())
+        }
+    }
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+// What to do with an entry whose Duration is missing or zero
+type durationPolicy string
+
+const (
+    // Leave the span as it is
+    zeroDurationKeep durationPolicy = "keep"
+    // Stretch the span to at least the configured minimum duration
+    zeroDurationMin durationPolicy = "min"
+    // Record no span for the entry
+    zeroDurationSkip durationPolicy = "skip"
+)
+
+const defaultMinSpanDuration = time.Microsecond
+
+// How far an explicit Duration may differ from EndTimestamp - Timestamp
+// before computeDuration warns
+const durationMismatchTolerance = time.Millisecond
+
+// Correction added to entry timestamps from a host with a known clock skew,
+// set from CLOCK_OFFSET at startup
+var clockOffset time.Duration
+
+// Clock read for entry span, operation and event timestamps. Tests replace it
+// with a fixed clock so every duration is zero and exporter output is
+// byte-stable between runs.
+var spanClock = time.Now
+
+// Current time for span timestamps, corrected by clockOffset
+func spanNow() time.Time {
+    return spanClock().Add(clockOffset)
+}
+
+// Parsed Duration of the entry; missing, invalid and negative values are zero
+func entryDuration(l LogEntry) time.Duration {
+    d, err := time.ParseDuration(strings.TrimSpace(l.Duration))
+    if err != nil || d < 0 {
+        return 0
+    }
+    return d
+}
+
+// Derive Duration from Timestamp and EndTimestamp. An explicit Duration is
+// kept, with a warning logged when it disagrees with the timestamps. Entries
+// without an EndTimestamp are left unchanged.
+func computeDuration(l *LogEntry) error {
+    if l.EndTimestamp == "" {
+        return nil
+    }
+    start, err := parseTimestamp(l.Timestamp)
+    if err != nil {
+        return err
+    }
+    end, err := parseTimestamp(l.EndTimestamp)
+    if err != nil {
+        return err
+    }
+    d := end.Sub(start)
+    if d < 0 {
+        return fmt.Errorf("EndTimestamp %q is before Timestamp %q", l.EndTimestamp, l.Timestamp)
+    }
+
+    if l.Duration == "" {
+        l.Duration = d.String()
+        return nil
+    }
+    explicit, err := time.ParseDuration(strings.TrimSpace(l.Duration))
+    if err != nil {
+        return fmt.Errorf("invalid Duration %q: %w", l.Duration, err)
+    }
+    if diff := (explicit - d).Abs(); diff > durationMismatchTolerance {
+        log.Printf("Duration %s does not match timestamps (%s apart)", explicit, d)
+    }
+    return nil
+}
+
+// End the entry's span, stretching it to MinSpanDuration under the min
+// policy when the entry has no Duration
+func endEntrySpan(span trace.Span, l LogEntry, cfg config) {
+    end := spanNow()
+    if cfg.ZeroDurationPolicy == zeroDurationMin && entryDuration(l) == 0 {
+        if s, ok := span.(interface{ StartTime() time.Time }); ok && end.Sub(s.StartTime()) < cfg.MinSpanDuration {
+            end = s.StartTime().Add(cfg.MinSpanDuration)
+        }
+    }
+    span.End(trace.WithTimestamp(end))
+}
+
+// Span for an entry skipped by the zero-duration policy: it records nothing
+// but has a valid, unsampled span context, so the entry still gets
+// log.sampled and a traceparent and its child spans are dropped with it
+func skippedEntrySpan(ctx context.Context) (context.Context, trace.Span) {
+    var ids randomIDGenerator
+    traceID := trace.SpanContextFromContext(ctx).TraceID()
+    if !traceID.IsValid() {
+        traceID, _ = ids.NewIDs(ctx)
+    }
+    sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: ids.NewSpanID(ctx, traceID)})
+    ctx = trace.ContextWithSpanContext(ctx, sc)
+    return ctx, trace.SpanFromContext(ctx)
+}
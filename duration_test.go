@@ -0,0 +1,220 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "log"
+    "regexp"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestZeroDurationPolicies(t *testing.T) {
+    for _, policy := range []durationPolicy{zeroDurationKeep, zeroDurationMin, zeroDurationSkip} {
+        t.Run(string(policy), func(t *testing.T) {
+            recorder := tracetest.NewSpanRecorder()
+            tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+            cfg := config{ZeroDurationPolicy: policy, MinSpanDuration: time.Hour}
+            entry := LogEntry{Body: "no duration", Attributes: map[string]string{}}
+
+            ctx, span := startEntrySpan(context.Background(), tp.Tracer("test"), entry, cfg)
+            endEntrySpan(span, entry, cfg)
+            injectContextIntoEntry(ctx, &entry)
+
+            ended := recorder.Ended()
+            switch policy {
+            case zeroDurationSkip:
+                if len(ended) != 0 {
+                    t.Fatalf("recorded %d spans, want none", len(ended))
+                }
+                if !span.SpanContext().IsValid() || span.SpanContext().IsSampled() {
+                    t.Errorf("skipped span context %v, want valid and unsampled", span.SpanContext())
+                }
+                if entry.Attributes["traceparent"] == "" {
+                    t.Error("skipped entry has no traceparent")
+                }
+            case zeroDurationMin:
+                if len(ended) != 1 {
+                    t.Fatalf("recorded %d spans, want 1", len(ended))
+                }
+                if d := ended[0].EndTime().Sub(ended[0].StartTime()); d != time.Hour {
+                    t.Errorf("span lasts %v, want the minimum of 1h", d)
+                }
+            case zeroDurationKeep:
+                if len(ended) != 1 {
+                    t.Fatalf("recorded %d spans, want 1", len(ended))
+                }
+                if d := ended[0].EndTime().Sub(ended[0].StartTime()); d >= time.Hour {
+                    t.Errorf("span lasts %v, want it left as timed", d)
+                }
+            }
+        })
+    }
+}
+
+func TestEntrySpanTimedOnWallClock(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+    entry := LogEntry{Timestamp: "2001-02-03T04:05:06Z", Duration: "5s"}
+    before := time.Now()
+    _, span := startEntrySpan(context.Background(), tp.Tracer("test"), entry, config{})
+    endEntrySpan(span, entry, config{})
+
+    s := recorder.Ended()[0]
+    if s.StartTime().Before(before) || s.EndTime().After(time.Now()) {
+        t.Errorf("span %v-%v not timed on the wall clock", s.StartTime(), s.EndTime())
+    }
+}
+
+func TestComputeDuration(t *testing.T) {
+    var logged bytes.Buffer
+    saved := log.Writer()
+    log.SetOutput(&logged)
+    defer log.SetOutput(saved)
+
+    const start = "2024-03-01T12:00:00.000000000Z"
+    tests := []struct {
+        name         string
+        entry        LogEntry
+        wantDuration string
+        wantErr      bool
+        wantWarning  bool
+    }{
+        {"derived", LogEntry{Timestamp: start, EndTimestamp: "2024-03-01T12:00:01.500000000Z"}, "1.5s", false, false},
+        {"no end", LogEntry{Timestamp: start, Duration: "3s"}, "3s", false, false},
+        {"explicit agrees", LogEntry{Timestamp: start, EndTimestamp: "2024-03-01T12:00:02Z", Duration: "2s"}, "2s", false, false},
+        {"explicit disagrees", LogEntry{Timestamp: start, EndTimestamp: "2024-03-01T12:00:02Z", Duration: "5s"}, "5s", false, true},
+        {"end before start", LogEntry{Timestamp: start, EndTimestamp: "2024-03-01T11:59:59Z"}, "", true, false},
+        {"bad end", LogEntry{Timestamp: start, EndTimestamp: "yesterday"}, "", true, false},
+        {"bad duration", LogEntry{Timestamp: start, EndTimestamp: "2024-03-01T12:00:02Z", Duration: "soon"}, "soon", true, false},
+    }
+    for _, tt := range tests {
+        logged.Reset()
+        l := tt.entry
+        err := computeDuration(&l)
+        if (err != nil) != tt.wantErr {
+            t.Errorf("%s: computeDuration error = %v, want error %v", tt.name, err, tt.wantErr)
+        }
+        if l.Duration != tt.wantDuration {
+            t.Errorf("%s: Duration = %q, want %q", tt.name, l.Duration, tt.wantDuration)
+        }
+        if warned := strings.Contains(logged.String(), "does not match timestamps"); warned != tt.wantWarning {
+            t.Errorf("%s: mismatch warning logged %v, want %v", tt.name, warned, tt.wantWarning)
+        }
+    }
+}
+
+func TestClockOffsetAppliedToSpanTimestamps(t *testing.T) {
+    saved := clockOffset
+    clockOffset = -90 * time.Second
+    defer func() { clockOffset = saved }()
+
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+    entry := LogEntry{Body: "skewed host", Attributes: map[string]string{"op.db": "query"}}
+    cfg := config{OperationKeys: []string{"op.db"}}
+    before := time.Now().Add(clockOffset)
+    _, span := startEntrySpan(context.Background(), tp.Tracer("test"), entry, cfg)
+    endEntrySpan(span, entry, cfg)
+    after := time.Now().Add(clockOffset)
+
+    ended := recorder.Ended()
+    if len(ended) == 0 {
+        t.Fatal("no spans recorded")
+    }
+    // The entry span and its operation child both use the corrected clock
+    for _, s := range ended {
+        if s.StartTime().Before(before) || s.EndTime().After(after) || s.EndTime().Before(s.StartTime()) {
+            t.Errorf("%s: %v-%v outside the offset window %v-%v", s.Name(), s.StartTime(), s.EndTime(), before, after)
+        }
+    }
+}
+
+// Read span timestamps from a clock stopped at at for the rest of the test,
+// so every entry span, operation and event lasts zero
+func useFixedSpanClock(t *testing.T, at time.Time) {
+    t.Helper()
+    saved := spanClock
+    spanClock = func() time.Time { return at }
+    t.Cleanup(func() { spanClock = saved })
+}
+
+// ID generator counting up from 1, for byte-stable exporter output
+type sequentialIDs struct {
+    next uint64
+}
+
+func (g *sequentialIDs) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+    var traceID oteltrace.TraceID
+    g.next++
+    binary.BigEndian.PutUint64(traceID[8:], g.next)
+    return traceID, g.NewSpanID(ctx, traceID)
+}
+
+func (g *sequentialIDs) NewSpanID(context.Context, oteltrace.TraceID) oteltrace.SpanID {
+    var spanID oteltrace.SpanID
+    g.next++
+    binary.BigEndian.PutUint64(spanID[:], g.next)
+    return spanID
+}
+
+func TestFixedSpanClockOutputIsReproducible(t *testing.T) {
+    at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    useFixedSpanClock(t, at)
+    entry := LogEntry{
+        Body:       "checkout [trace:cart loaded] failed",
+        Attributes: map[string]string{"op.db": "select;update"},
+        Exception:  map[string]string{"exception.type": "QueryError", "exception.message": "deadlock detected"},
+    }
+    cfg := config{OperationKeys: []string{"op.db"}, AnnotationPattern: regexp.MustCompile(defaultAnnotationPattern)}
+
+    render := func() (string, []trace.ReadOnlySpan) {
+        var buf bytes.Buffer
+        exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(&buf))
+        if err != nil {
+            t.Fatal(err)
+        }
+        recorder := tracetest.NewSpanRecorder()
+        tp := trace.NewTracerProvider(
+            trace.WithSyncer(exporter),
+            trace.WithSpanProcessor(recorder),
+            trace.WithIDGenerator(&sequentialIDs{}),
+            trace.WithResource(resource.Empty()),
+        )
+        _, span := startEntrySpan(context.Background(), tp.Tracer("test"), entry, cfg)
+        endEntrySpan(span, entry, cfg)
+        if err := tp.Shutdown(context.Background()); err != nil {
+            t.Fatal(err)
+        }
+        return buf.String(), recorder.Ended()
+    }
+
+    first, spans := render()
+    if second, _ := render(); second != first {
+        t.Errorf("output differs between runs:\n%s\n---\n%s", first, second)
+    }
+    if len(spans) != 3 {
+        t.Fatalf("recorded %d spans, want the entry and two operations", len(spans))
+    }
+    if len(spans[2].Events()) != 2 {
+        t.Errorf("entry span has %d events, want the exception and the annotation", len(spans[2].Events()))
+    }
+    for _, s := range spans {
+        if !s.StartTime().Equal(at) || !s.EndTime().Equal(at) {
+            t.Errorf("%s: %v-%v, want both at %v", s.Name(), s.StartTime(), s.EndTime(), at)
+        }
+        for _, e := range s.Events() {
+            if !e.Time.Equal(at) {
+                t.Errorf("%s: event %s at %v, want %v", s.Name(), e.Name, e.Time, at)
+            }
+        }
+    }
+}
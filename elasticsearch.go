@@ -0,0 +1,230 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+    defaultElasticsearchEndpoint = "http://localhost:9200"
+    defaultElasticsearchIndex    = "otel-spans"
+)
+
+// Span document indexed into OpenSearch/Elasticsearch
+type esSpanDocument struct {
+    Timestamp  time.Time         `json:"@timestamp"`
+    TraceID    string            `json:"trace.id"`
+    SpanID     string            `json:"span.id"`
+    ParentID   string            `json:"parent.id,omitempty"`
+    Name       string            `json:"name"`
+    Kind       string            `json:"kind"`
+    EndTime    time.Time         `json:"end_time"`
+    DurationUs int64             `json:"duration_us"`
+    Status     string            `json:"status"`
+    StatusMsg  string            `json:"status_message,omitempty"`
+    Attributes map[string]string `json:"attributes,omitempty"`
+    Resource   map[string]string `json:"resource,omitempty"`
+    Scope      string            `json:"scope,omitempty"`
+}
+
+// Exporter bulk-indexing spans into an OpenSearch/Elasticsearch index, one
+// _bulk request per export call. When a mapping file is configured it is
+// used to create the index before the first export.
+type elasticsearchExporter struct {
+    endpoint string
+    index    string
+    mapping  []byte
+    client   *http.Client
+
+    mu           sync.Mutex
+    indexCreated bool
+    stopped      bool
+}
+
+func newElasticsearchExporter(endpoint, index, mappingPath string) (*elasticsearchExporter, error) {
+    if endpoint == "" {
+        endpoint = defaultElasticsearchEndpoint
+    }
+    if index == "" {
+        index = defaultElasticsearchIndex
+    }
+    e := &elasticsearchExporter{
+        endpoint: strings.TrimRight(endpoint, "/"),
+        index:    index,
+        client:   http.DefaultClient,
+    }
+    if mappingPath != "" {
+        mapping, err := os.ReadFile(mappingPath)
+        if err != nil {
+            return nil, fmt.Errorf("elasticsearch mapping: %w", err)
+        }
+        if !json.Valid(mapping) {
+            return nil, fmt.Errorf("elasticsearch mapping %s is not valid JSON", mappingPath)
+        }
+        e.mapping = mapping
+    }
+    return e, nil
+}
+
+func (e *elasticsearchExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    e.mu.Lock()
+    stopped, needIndex := e.stopped, e.mapping != nil && !e.indexCreated
+    e.mu.Unlock()
+    if stopped || len(spans) == 0 {
+        return nil
+    }
+    if needIndex {
+        if err := e.createIndex(ctx); err != nil {
+            return err
+        }
+    }
+
+    var body bytes.Buffer
+    action, err := json.Marshal(map[string]map[string]string{"index": {"_index": e.index}})
+    if err != nil {
+        return err
+    }
+    for _, s := range spans {
+        doc, err := json.Marshal(toESSpanDocument(s))
+        if err != nil {
+            return err
+        }
+        body.Write(action)
+        body.WriteByte('\n')
+        body.Write(doc)
+        body.WriteByte('\n')
+    }
+
+    resp, err := e.do(ctx, http.MethodPost, e.endpoint+"/_bulk", "application/x-ndjson", &body)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("elasticsearch bulk request to %s returned %s", redactURL(e.endpoint), resp.Status)
+    }
+    return bulkResponseError(resp.Body)
+}
+
+// Create the index with the configured mapping, treating an existing index
+// as success
+func (e *elasticsearchExporter) createIndex(ctx context.Context) error {
+    resp, err := e.do(ctx, http.MethodPut, e.endpoint+"/"+e.index, "application/json", bytes.NewReader(e.mapping))
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode == http.StatusBadRequest {
+        var result struct {
+            Error struct {
+                Type string `json:"type"`
+            } `json:"error"`
+        }
+        if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Error.Type == "resource_already_exists_exception" {
+            resp.StatusCode = http.StatusOK
+        }
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("elasticsearch create index %q returned %s", e.index, resp.Status)
+    }
+    e.mu.Lock()
+    e.indexCreated = true
+    e.mu.Unlock()
+    return nil
+}
+
+func (e *elasticsearchExporter) do(ctx context.Context, method, url, contentType string, body io.Reader) (*http.Response, error) {
+    req, err := http.NewRequestWithContext(ctx, method, url, body)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", contentType)
+    resp, err := e.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("elasticsearch export: %w", err)
+    }
+    return resp, nil
+}
+
+// Error for the items a bulk response reports as failed, if any
+func bulkResponseError(r io.Reader) error {
+    var result struct {
+        Errors bool `json:"errors"`
+        Items  []map[string]struct {
+            Status int `json:"status"`
+            Error  struct {
+                Type   string `json:"type"`
+                Reason string `json:"reason"`
+            } `json:"error"`
+        } `json:"items"`
+    }
+    if err := json.NewDecoder(r).Decode(&result); err != nil {
+        return fmt.Errorf("elasticsearch bulk response: %w", err)
+    }
+    if !result.Errors {
+        return nil
+    }
+    failed, first := 0, ""
+    for _, item := range result.Items {
+        for _, op := range item {
+            if op.Status >= 300 {
+                if failed == 0 {
+                    first = fmt.Sprintf("%s: %s", op.Error.Type, op.Error.Reason)
+                }
+                failed++
+            }
+        }
+    }
+    return fmt.Errorf("elasticsearch bulk indexing failed for %d of %d spans, first error %s", failed, len(result.Items), first)
+}
+
+func (e *elasticsearchExporter) Shutdown(ctx context.Context) error {
+    e.mu.Lock()
+    e.stopped = true
+    e.mu.Unlock()
+    return ctx.Err()
+}
+
+func toESSpanDocument(s trace.ReadOnlySpan) esSpanDocument {
+    doc := esSpanDocument{
+        Timestamp:  s.StartTime(),
+        TraceID:    s.SpanContext().TraceID().String(),
+        SpanID:     s.SpanContext().SpanID().String(),
+        Name:       s.Name(),
+        Kind:       s.SpanKind().String(),
+        EndTime:    s.EndTime(),
+        DurationUs: s.EndTime().Sub(s.StartTime()).Microseconds(),
+        Status:     s.Status().Code.String(),
+        Scope:      s.InstrumentationScope().Name,
+    }
+    if s.Parent().SpanID().IsValid() {
+        doc.ParentID = s.Parent().SpanID().String()
+    }
+    if s.Status().Code == codes.Error {
+        doc.StatusMsg = s.Status().Description
+    }
+    if attrs := s.Attributes(); len(attrs) > 0 {
+        doc.Attributes = make(map[string]string, len(attrs))
+        for _, kv := range attrs {
+            doc.Attributes[string(kv.Key)] = kv.Value.Emit()
+        }
+    }
+    if res := s.Resource(); res != nil && res.Len() > 0 {
+        doc.Resource = make(map[string]string, res.Len())
+        for _, kv := range res.Attributes() {
+            doc.Resource[string(kv.Key)] = kv.Value.Emit()
+        }
+    }
+    return doc
+}
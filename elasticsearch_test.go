@@ -0,0 +1,164 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Mock Elasticsearch recording index creation and bulk requests, answering
+// bulk requests with bulkReply
+type mockElasticsearch struct {
+    mu        sync.Mutex
+    mappings  map[string]string
+    actions   []map[string]map[string]string
+    documents []esSpanDocument
+    bulkReply string
+}
+
+func (m *mockElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    switch {
+    case r.Method == http.MethodPut:
+        body, _ := io.ReadAll(r.Body)
+        m.mappings[strings.TrimPrefix(r.URL.Path, "/")] = string(body)
+        w.Write([]byte(`{"acknowledged": true}`))
+    case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+        if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+            http.Error(w, "bad content type "+ct, http.StatusBadRequest)
+            return
+        }
+        scanner := bufio.NewScanner(r.Body)
+        for scanner.Scan() {
+            var action map[string]map[string]string
+            if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+                http.Error(w, "malformed bulk body", http.StatusBadRequest)
+                return
+            }
+            var doc esSpanDocument
+            if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+                http.Error(w, err.Error(), http.StatusBadRequest)
+                return
+            }
+            m.actions = append(m.actions, action)
+            m.documents = append(m.documents, doc)
+        }
+        w.Write([]byte(m.bulkReply))
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+func elasticsearchTestSpans() []trace.ReadOnlySpan {
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    traceID := oteltrace.TraceID{0x4b, 0xf9}
+    root := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}})
+    return tracetest.SpanStubs{
+        {
+            Name:        "GET /users",
+            SpanKind:    oteltrace.SpanKindServer,
+            SpanContext: root,
+            StartTime:   start,
+            EndTime:     start.Add(150 * time.Millisecond),
+            Attributes:  []attribute.KeyValue{attribute.Int("http.status_code", 500)},
+            Status:      trace.Status{Code: codes.Error, Description: "boom"},
+        },
+        {
+            Name:        "SELECT users",
+            SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{2}}),
+            Parent:      root,
+            StartTime:   start,
+            EndTime:     start.Add(20 * time.Millisecond),
+        },
+    }.Snapshots()
+}
+
+func TestElasticsearchExporterBulkIndexes(t *testing.T) {
+    mock := &mockElasticsearch{mappings: map[string]string{}, bulkReply: `{"errors": false, "items": []}`}
+    server := httptest.NewServer(mock)
+    defer server.Close()
+
+    mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+    mapping := `{"mappings": {"properties": {"trace.id": {"type": "keyword"}}}}`
+    if err := os.WriteFile(mappingPath, []byte(mapping), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    e, err := newElasticsearchExporter(server.URL+"/", "spans-test", mappingPath)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx := context.Background()
+    spans := elasticsearchTestSpans()
+    if err := e.ExportSpans(ctx, spans); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(ctx, spans[:1]); err != nil {
+        t.Fatal(err)
+    }
+
+    mock.mu.Lock()
+    defer mock.mu.Unlock()
+    if len(mock.mappings) != 1 || mock.mappings["spans-test"] != mapping {
+        t.Errorf("index created with %v, want the mapping once", mock.mappings)
+    }
+    if len(mock.documents) != 3 {
+        t.Fatalf("indexed %d documents, want 3", len(mock.documents))
+    }
+    for _, action := range mock.actions {
+        if action["index"]["_index"] != "spans-test" {
+            t.Errorf("bulk action %v", action)
+        }
+    }
+    root, child := mock.documents[0], mock.documents[1]
+    if root.TraceID != (oteltrace.TraceID{0x4b, 0xf9}).String() || root.Name != "GET /users" || root.Kind != "server" {
+        t.Errorf("root document = %+v", root)
+    }
+    if root.DurationUs != 150000 || root.Status != "Error" || root.StatusMsg != "boom" || root.Attributes["http.status_code"] != "500" {
+        t.Errorf("root document = %+v", root)
+    }
+    if child.ParentID != root.SpanID || child.StatusMsg != "" {
+        t.Errorf("child document = %+v", child)
+    }
+}
+
+func TestElasticsearchExporterReturnsIndexingErrors(t *testing.T) {
+    mock := &mockElasticsearch{mappings: map[string]string{}, bulkReply: `{"errors": true, "items": [
+        {"index": {"status": 201}},
+        {"index": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [duration_us]"}}}
+    ]}`}
+    server := httptest.NewServer(mock)
+    defer server.Close()
+
+    e, err := newElasticsearchExporter(server.URL, "", "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    err = e.ExportSpans(context.Background(), elasticsearchTestSpans())
+    if err == nil || !strings.Contains(err.Error(), "1 of 2 spans") || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+        t.Errorf("ExportSpans error = %v", err)
+    }
+    if len(mock.actions) == 0 || mock.actions[0]["index"]["_index"] != defaultElasticsearchIndex {
+        t.Errorf("bulk actions = %v, want the default index", mock.actions)
+    }
+
+    server.Close()
+    if err := e.ExportSpans(context.Background(), elasticsearchTestSpans()); err == nil {
+        t.Error("export to a stopped server succeeded")
+    }
+}
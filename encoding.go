@@ -0,0 +1,183 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+
+    tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+)
+
+// Serialization of exported batches written to a file
+type exportEncoding string
+
+const (
+    // Binary OTLP messages, each prefixed with its length as a uvarint
+    encodingProtobuf exportEncoding = "protobuf"
+    // OTLP/JSON messages, one per line, with hex trace and span IDs and
+    // numeric enums
+    encodingJSON exportEncoding = "json"
+)
+
+func (e exportEncoding) serializer() spanSerializer {
+    if e == encodingJSON {
+        return jsonSerializer{}
+    }
+    return protobufSerializer{}
+}
+
+// Writes and reads back one TracesData message per exported batch
+type spanSerializer interface {
+    // Encoded message, framed so it can be appended to a stream
+    Marshal(data *tracepb.TracesData) ([]byte, error)
+    // Next message of the stream, or io.EOF once it is exhausted
+    Unmarshal(r *bufio.Reader) (*tracepb.TracesData, error)
+}
+
+type protobufSerializer struct{}
+
+func (protobufSerializer) Marshal(data *tracepb.TracesData) ([]byte, error) {
+    msg, err := proto.Marshal(data)
+    if err != nil {
+        return nil, err
+    }
+    frame := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(msg)), uint64(len(msg)))
+    return append(frame, msg...), nil
+}
+
+func (protobufSerializer) Unmarshal(r *bufio.Reader) (*tracepb.TracesData, error) {
+    size, err := binary.ReadUvarint(r)
+    if err != nil {
+        return nil, err
+    }
+    if size > maxProtoMessageSize {
+        return nil, fmt.Errorf("message of %d bytes exceeds limit", size)
+    }
+    buf := make([]byte, size)
+    if _, err := io.ReadFull(r, buf); err != nil {
+        return nil, err
+    }
+    var data tracepb.TracesData
+    if err := proto.Unmarshal(buf, &data); err != nil {
+        return nil, err
+    }
+    return &data, nil
+}
+
+type jsonSerializer struct{}
+
+// Fields of spans and links holding IDs, which OTLP/JSON writes as hex
+// where the protobuf JSON mapping uses base64
+var otlpJSONIDFields = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+func (jsonSerializer) Marshal(data *tracepb.TracesData) ([]byte, error) {
+    msg, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(data)
+    if err != nil {
+        return nil, err
+    }
+    msg, err = recodeJSONIDs(msg, func(id string) (string, error) {
+        b, err := base64.StdEncoding.DecodeString(id)
+        return hex.EncodeToString(b), err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return append(msg, '\n'), nil
+}
+
+func (jsonSerializer) Unmarshal(r *bufio.Reader) (*tracepb.TracesData, error) {
+    for {
+        line, err := readLine(r, maxProtoMessageSize)
+        if len(bytes.TrimSpace(line)) == 0 {
+            if err == nil {
+                continue
+            }
+            return nil, err
+        }
+        if err != nil && !errors.Is(err, io.EOF) {
+            return nil, err
+        }
+        line, err = recodeJSONIDs(line, func(id string) (string, error) {
+            b, err := hex.DecodeString(id)
+            return base64.StdEncoding.EncodeToString(b), err
+        })
+        if err != nil {
+            return nil, err
+        }
+        var data tracepb.TracesData
+        if err := protojson.Unmarshal(line, &data); err != nil {
+            return nil, err
+        }
+        return &data, nil
+    }
+}
+
+// Next line of r, newline included, failing once it grows past limit bytes
+// so an oversized line is never buffered whole
+func readLine(r *bufio.Reader, limit int) ([]byte, error) {
+    var line []byte
+    for {
+        chunk, err := r.ReadSlice('\n')
+        if len(line)+len(chunk) > limit {
+            return nil, fmt.Errorf("message exceeds limit of %d bytes", limit)
+        }
+        line = append(line, chunk...)
+        if !errors.Is(err, bufio.ErrBufferFull) {
+            return line, err
+        }
+    }
+}
+
+// Message with every ID field's string value passed through convert
+func recodeJSONIDs(msg []byte, convert func(string) (string, error)) ([]byte, error) {
+    dec := json.NewDecoder(bytes.NewReader(msg))
+    dec.UseNumber()
+    var tree any
+    if err := dec.Decode(&tree); err != nil {
+        return nil, err
+    }
+    var walk func(v any) error
+    walk = func(v any) error {
+        switch v := v.(type) {
+        case map[string]any:
+            for k, field := range v {
+                if id, ok := field.(string); ok && otlpJSONIDFields[k] {
+                    converted, err := convert(id)
+                    if err != nil {
+                        return fmt.Errorf("%s %q: %w", k, id, err)
+                    }
+                    v[k] = converted
+                    continue
+                }
+                if err := walk(field); err != nil {
+                    return err
+                }
+            }
+        case []any:
+            for _, item := range v {
+                if err := walk(item); err != nil {
+                    return err
+                }
+            }
+        }
+        return nil
+    }
+    if err := walk(tree); err != nil {
+        return nil, err
+    }
+    var out bytes.Buffer
+    enc := json.NewEncoder(&out)
+    enc.SetEscapeHTML(false)
+    if err := enc.Encode(tree); err != nil {
+        return nil, err
+    }
+    return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
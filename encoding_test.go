@@ -0,0 +1,135 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestExportEncodingsRoundTrip(t *testing.T) {
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    traceID := oteltrace.TraceID{0x4b, 0xf9, 1}
+    root := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}, TraceFlags: oteltrace.FlagsSampled})
+    batches := [][]trace.ReadOnlySpan{
+        tracetest.SpanStubs{{
+            Name:        "GET /users",
+            SpanKind:    oteltrace.SpanKindServer,
+            SpanContext: root,
+            StartTime:   start,
+            EndTime:     start.Add(150 * time.Millisecond),
+            Attributes:  []attribute.KeyValue{attribute.Int("http.status_code", 500), attribute.StringSlice("tags", []string{"a", "b"})},
+            Status:      trace.Status{Code: codes.Error, Description: "boom"},
+        }}.Snapshots(),
+        tracetest.SpanStubs{{
+            Name:        "SELECT users",
+            SpanKind:    oteltrace.SpanKindClient,
+            SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{2}, TraceFlags: oteltrace.FlagsSampled}),
+            Parent:      root,
+            StartTime:   start.Add(time.Millisecond),
+            EndTime:     start.Add(20 * time.Millisecond),
+            Attributes:  []attribute.KeyValue{attribute.Float64("db.rows", 1.5), attribute.Bool("cached", true)},
+        }}.Snapshots(),
+    }
+
+    for _, encoding := range []exportEncoding{encodingProtobuf, encodingJSON} {
+        t.Run(string(encoding), func(t *testing.T) {
+            path := filepath.Join(t.TempDir(), "spans.out")
+            e, err := newProtoFileExporter(path, encoding)
+            if err != nil {
+                t.Fatal(err)
+            }
+            for _, batch := range batches {
+                if err := e.ExportSpans(context.Background(), batch); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            if err := e.Shutdown(context.Background()); err != nil {
+                t.Fatal(err)
+            }
+
+            got, detected, err := readSpanFile(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if detected != encoding {
+                t.Errorf("detected encoding %q, want %q", detected, encoding)
+            }
+            if len(got) != 2 {
+                t.Fatalf("read %d spans, want 2", len(got))
+            }
+            for i, want := range []trace.ReadOnlySpan{batches[0][0], batches[1][0]} {
+                s := got[i]
+                if s.Name() != want.Name() || s.SpanKind() != want.SpanKind() ||
+                    s.SpanContext().TraceID() != want.SpanContext().TraceID() ||
+                    s.SpanContext().SpanID() != want.SpanContext().SpanID() ||
+                    s.Parent().SpanID() != want.Parent().SpanID() ||
+                    !s.StartTime().Equal(want.StartTime()) || !s.EndTime().Equal(want.EndTime()) ||
+                    s.Status() != want.Status() {
+                    t.Errorf("span %d = %+v, want %+v", i, s, want)
+                }
+                if gotAttrs, wantAttrs := attribute.NewSet(s.Attributes()...), attribute.NewSet(want.Attributes()...); !gotAttrs.Equals(&wantAttrs) {
+                    t.Errorf("span %d attributes = %v, want %v", i, s.Attributes(), want.Attributes())
+                }
+            }
+        })
+    }
+}
+
+func TestJSONEncodingUsesHexIDs(t *testing.T) {
+    traceID := oteltrace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+    parent := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{0xa, 0xb}})
+    spans := tracetest.SpanStubs{{
+        Name:        "GET /users",
+        SpanKind:    oteltrace.SpanKindServer,
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{0, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}}),
+        Parent:      parent,
+        Links:       []trace.Link{{SpanContext: parent}},
+        Attributes:  []attribute.KeyValue{attribute.String("traceId", "not an ID")},
+    }}.Snapshots()
+
+    line, err := jsonSerializer{}.Marshal(toProtoTracesData(spans))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`,
+        `"spanId":"00f067aa0ba902b7"`,
+        `"parentSpanId":"0a0b000000000000"`,
+        `"kind":2`,
+        `"stringValue":"not an ID"`,
+    } {
+        if !strings.Contains(string(line), want) {
+            t.Errorf("JSON line lacks %s:\n%s", want, line)
+        }
+    }
+
+    data, err := jsonSerializer{}.Unmarshal(bufio.NewReader(bytes.NewReader(line)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    got := fromProtoTracesData(data)
+    if len(got) != 1 || got[0].SpanContext.TraceID() != traceID || got[0].Parent.SpanID() != parent.SpanID() ||
+        len(got[0].Links) != 1 || got[0].Links[0].SpanContext.SpanID() != parent.SpanID() {
+        t.Errorf("read back %+v", got)
+    }
+}
+
+func TestReadLineIsBounded(t *testing.T) {
+    r := bufio.NewReaderSize(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\nafter"), 16)
+    if line, err := readLine(r, 50); err != nil || string(line) != "short\n" {
+        t.Errorf("first line = %q, %v", line, err)
+    }
+    if _, err := readLine(r, 50); err == nil || !strings.Contains(err.Error(), "exceeds limit of 50 bytes") {
+        t.Errorf("oversized line: err = %v", err)
+    }
+}
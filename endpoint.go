@@ -0,0 +1,107 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// Dial timeout used when the context carries no deadline
+const defaultEndpointCheckTimeout = 5 * time.Second
+
+// Check that a collector endpoint accepts TCP connections. The endpoint may be
+// a URL (the port defaults from its scheme) or a bare host:port.
+func CheckEndpoint(ctx context.Context, endpoint string) error {
+    address, err := endpointAddress(endpoint)
+    if err != nil {
+        return err
+    }
+    if _, ok := ctx.Deadline(); !ok {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, defaultEndpointCheckTimeout)
+        defer cancel()
+    }
+
+    var dialer net.Dialer
+    conn, err := dialer.DialContext(ctx, "tcp", address)
+    if err != nil {
+        return fmt.Errorf("endpoint %s unreachable: %w", redactURL(endpoint), err)
+    }
+    return conn.Close()
+}
+
+// host:port to dial for an endpoint URL or address
+func endpointAddress(endpoint string) (string, error) {
+    endpoint = strings.TrimSpace(endpoint)
+    if endpoint == "" {
+        return "", fmt.Errorf("empty endpoint")
+    }
+    if !strings.Contains(endpoint, "://") {
+        if strings.Contains(endpoint, "@") {
+            return "", fmt.Errorf("invalid endpoint %q: credentials need a URL", redactURL(endpoint))
+        }
+        if _, _, err := net.SplitHostPort(endpoint); err != nil {
+            // The address error repeats the endpoint, so only its reason is kept
+            reason := err.Error()
+            var addrErr *net.AddrError
+            if errors.As(err, &addrErr) {
+                reason = addrErr.Err
+            }
+            return "", fmt.Errorf("invalid endpoint %q: %s", redactURL(endpoint), reason)
+        }
+        return endpoint, nil
+    }
+
+    u, err := url.Parse(endpoint)
+    if err != nil {
+        var urlErr *url.Error
+        if errors.As(err, &urlErr) {
+            err = urlErr.Err
+        }
+        return "", fmt.Errorf("invalid endpoint %q: %w", redactURL(endpoint), err)
+    }
+    if u.Hostname() == "" {
+        return "", fmt.Errorf("invalid endpoint %q: no host", redactURL(endpoint))
+    }
+    port := u.Port()
+    if port == "" {
+        switch u.Scheme {
+        case "http":
+            port = "80"
+        case "https":
+            port = "443"
+        default:
+            return "", fmt.Errorf("invalid endpoint %q: no port for scheme %q", redactURL(endpoint), u.Scheme)
+        }
+    }
+    return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// Endpoints the configured traces exporter connects to over TCP
+func exporterEndpoints(cfg config) []string {
+    switch cfg.TracesExporter {
+    case "zipkin":
+        return []string{cfg.ZipkinEndpoint}
+    case "kafka":
+        return cfg.KafkaBrokers
+    case "elasticsearch":
+        return []string{cfg.ElasticsearchURL}
+    }
+    return nil
+}
+
+// Startup self-test: check every endpoint of the configured exporter and
+// return one error per unreachable endpoint
+func checkExporterEndpoints(ctx context.Context, cfg config) []error {
+    var errs []error
+    for _, endpoint := range exporterEndpoints(cfg) {
+        if err := CheckEndpoint(ctx, endpoint); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errs
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+    "context"
+    "log"
+    "maps"
+    "sync"
+)
+
+// Callback adding computed fields (e.g. a geo-IP lookup from host.ip) to a
+// log entry before its span is created
+type Enricher func(ctx context.Context, l *LogEntry)
+
+var (
+    enrichersMu sync.RWMutex
+    enrichers   []Enricher
+)
+
+// Register an enricher; enrichers run in registration order
+func RegisterEnricher(fn Enricher) {
+    if fn == nil {
+        return
+    }
+    enrichersMu.Lock()
+    defer enrichersMu.Unlock()
+    enrichers = append(enrichers, fn)
+}
+
+// Run the registered enrichers on a copy of the entry, so the caller's
+// Attributes map is left untouched. A panicking enricher is logged and
+// skipped; the others still run.
+func enrichEntry(ctx context.Context, l LogEntry) LogEntry {
+    enrichersMu.RLock()
+    registered := enrichers
+    enrichersMu.RUnlock()
+    if len(registered) == 0 {
+        return l
+    }
+
+    l.Attributes = maps.Clone(l.Attributes)
+    if l.Attributes == nil {
+        l.Attributes = map[string]string{}
+    }
+    for i, fn := range registered {
+        runEnricher(ctx, i, fn, &l)
+    }
+    return l
+}
+
+func runEnricher(ctx context.Context, index int, fn Enricher, l *LogEntry) {
+    defer func() {
+        if r := recover(); r != nil {
+            log.Printf("Enricher %d panicked, skipping: %v", index, r)
+        }
+    }()
+    fn(ctx, l)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+    "context"
+    "testing"
+)
+
+// Replace the registered enrichers for the duration of a test
+func withEnrichers(t *testing.T, fns ...Enricher) {
+    t.Helper()
+    enrichersMu.Lock()
+    saved := enrichers
+    enrichers = nil
+    enrichersMu.Unlock()
+    t.Cleanup(func() {
+        enrichersMu.Lock()
+        enrichers = saved
+        enrichersMu.Unlock()
+    })
+    for _, fn := range fns {
+        RegisterEnricher(fn)
+    }
+}
+
+func TestEnricherAttributesAppearOnSpan(t *testing.T) {
+    withEnrichers(t,
+        func(_ context.Context, l *LogEntry) { l.Attributes["enriched.by"] = "first" },
+        func(context.Context, *LogEntry) { panic("lookup failed") },
+        func(_ context.Context, l *LogEntry) {
+            l.Attributes["enriched.after"] = l.Attributes["enriched.by"]
+        })
+
+    original := map[string]string{"http.method": "GET"}
+    span := recordEntrySpan(t, LogEntry{Body: "hello", Attributes: original}, config{})
+    AssertSpanAttribute(t, span, "http.method", "GET")
+    AssertSpanAttribute(t, span, "enriched.by", "first")
+    // Later enrichers still run after a panic and see earlier results
+    AssertSpanAttribute(t, span, "enriched.after", "first")
+    if len(original) != 1 {
+        t.Errorf("enrichers modified the caller's attributes: %v", original)
+    }
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "context"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Default marker syntax for span annotations embedded in log bodies
+const defaultAnnotationPattern = `\[trace:([^\]]+)\]`
+
+// Start a span for the log entry with its events, body annotations, operation
+// child spans and status recorded, after running the registered enrichers.
+// The caller owns the span and must end it with endEntrySpan.
+func startEntrySpan(ctx context.Context, tracer trace.Tracer, l LogEntry, cfg config) (context.Context, trace.Span) {
+    // log.body.length counts the characters (runes) of the body as logged,
+    // without annotation markers or enricher changes, rather than bytes, so
+    // multibyte text is not reported as longer than it reads
+    logged, _ := extractBodyAnnotations(l.Body, cfg.AnnotationPattern)
+    l = enrichEntry(ctx, l)
+    named := l
+    var annotations []string
+    named.Body, annotations = extractBodyAnnotations(l.Body, cfg.AnnotationPattern)
+
+    if cfg.ZeroDurationPolicy == zeroDurationSkip && entryDuration(l) == 0 {
+        return skippedEntrySpan(ctx)
+    }
+
+    // Attributes are set at start so samplers can see them
+    attrs := append(normalizeAttributeKeys(stringAttributes(l.Attributes), cfg.AttributeKeyCase),
+        attribute.String("log.schema.version", l.schemaVersion()),
+        attribute.Int("log.body.length", utf8.RuneCountInString(logged)),
+    )
+    if cfg.BodyTemplate {
+        attrs = append(attrs, attribute.String("log.body.template", bodyTemplate(named.Body, cfg.BodyTokenPatterns)))
+    }
+    if cfg.AggregateRepeated {
+        attrs = aggregateRepeatedAttributes(attrs)
+    }
+    start := spanNow()
+    ctx, span := tracer.Start(ctx, spanNameForEntry(named, cfg.SpanNameStrategy),
+        trace.WithSpanKind(spanKindForEntry(l, cfg.SpanKindRules)),
+        trace.WithAttributes(attrs...),
+        trace.WithTimestamp(start))
+    events := entryEvents(l, cfg.StacktraceLimit)
+    for _, annotation := range annotations {
+        events = append(events, entryEvent{name: annotation, priority: priorityAnnotation})
+    }
+    recordEntryEvents(span, l, events, cfg.MaxEntryEvents)
+    recordEntryOperations(ctx, tracer, l, cfg.OperationKeys, start)
+    setEntryStatus(span, l, cfg.StatusTemplate)
+    return ctx, span
+}
+
+// Pull annotation markers out of a log body, returning the remaining text
+// and the first capture group of each marker in order of appearance
+func extractBodyAnnotations(body string, pattern *regexp.Regexp) (string, []string) {
+    if pattern == nil {
+        return body, nil
+    }
+    var annotations []string
+    for _, m := range pattern.FindAllStringSubmatch(body, -1) {
+        if annotation := strings.TrimSpace(m[1]); annotation != "" {
+            annotations = append(annotations, annotation)
+        }
+    }
+    if len(annotations) == 0 {
+        return body, nil
+    }
+    rest := pattern.ReplaceAllString(body, " ")
+    return strings.Join(strings.Fields(rest), " "), annotations
+}
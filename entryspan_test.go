@@ -0,0 +1,112 @@
+package main
+
+import (
+    "context"
+    "regexp"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Record the span startEntrySpan creates for l under cfg
+func recordEntrySpan(t *testing.T, l LogEntry, cfg config) trace.ReadOnlySpan {
+    t.Helper()
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+    _, span := startEntrySpan(context.Background(), tp.Tracer("test"), l, cfg)
+    span.End()
+    for _, s := range recorder.Ended() {
+        if s.SpanContext().Equal(span.SpanContext()) {
+            return s
+        }
+    }
+    t.Fatal("entry span not recorded")
+    return nil
+}
+
+func TestEntryEventCapCoversAnnotations(t *testing.T) {
+    l := LogEntry{
+        Body:      "failed [trace:retry] [trace:backoff]",
+        Exception: map[string]string{"exception.type": "E", "exception.message": "boom"},
+        EventData: map[string]string{"event.name": "request_error"},
+    }
+    cfg := config{AnnotationPattern: regexp.MustCompile(defaultAnnotationPattern), MaxEntryEvents: 2}
+    events := recordEntrySpan(t, l, cfg).Events()
+    if len(events) != 2 {
+        t.Fatalf("recorded %d events, want the cap of 2", len(events))
+    }
+    if events[0].Name != "exception" {
+        t.Errorf("first event %q, want exception", events[0].Name)
+    }
+}
+
+func TestExtractBodyAnnotations(t *testing.T) {
+    pattern := regexp.MustCompile(defaultAnnotationPattern)
+    for _, tc := range []struct {
+        body        string
+        rest        string
+        annotations []string
+    }{
+        {"plain body", "plain body", nil},
+        {"cache miss [trace:cache.miss] for user", "cache miss for user", []string{"cache.miss"}},
+        {"[trace: retry ] request [trace:backoff]", "request", []string{"retry", "backoff"}},
+        {"empty [trace: ] marker", "empty [trace: ] marker", nil},
+    } {
+        rest, annotations := extractBodyAnnotations(tc.body, pattern)
+        if rest != tc.rest || strings.Join(annotations, ",") != strings.Join(tc.annotations, ",") {
+            t.Errorf("extractBodyAnnotations(%q) = %q, %q; want %q, %q", tc.body, rest, annotations, tc.rest, tc.annotations)
+        }
+    }
+}
+
+func TestEntryEventCapKeepsHighestPriority(t *testing.T) {
+    l := LogEntry{
+        Body:      "[trace:first] request [trace:second]",
+        Exception: map[string]string{"exception.type": "E", "exception.message": "boom"},
+        EventData: map[string]string{"event.name": "request_error", "event.type": "error"},
+    }
+    cfg := config{AnnotationPattern: regexp.MustCompile(defaultAnnotationPattern), MaxEntryEvents: 2}
+    var names []string
+    for _, ev := range recordEntrySpan(t, l, cfg).Events() {
+        names = append(names, ev.Name)
+    }
+    if got, want := strings.Join(names, ","), "exception,request_error"; got != want {
+        t.Errorf("kept events %s, want %s", got, want)
+    }
+
+    // Annotations rank below the entry's own events
+    l.Exception = nil
+    names = nil
+    for _, ev := range recordEntrySpan(t, l, cfg).Events() {
+        names = append(names, ev.Name)
+    }
+    if got, want := strings.Join(names, ","), "request_error,first"; got != want {
+        t.Errorf("kept events %s, want %s", got, want)
+    }
+}
+
+func TestEntrySpanBodyLengthCountsRunes(t *testing.T) {
+    tests := []struct {
+        body string
+        want int
+    }{
+        {"", 0},
+        {"hello world", 11},
+        {"héllo wörld", 11},
+        {"日本語のログ", 6},
+        {"deploy 🚀 done", 13},
+    }
+    for _, tt := range tests {
+        span := recordEntrySpan(t, LogEntry{Body: tt.body}, config{})
+        AssertSpanAttribute(t, span, "log.body.length", tt.want)
+    }
+}
+
+func TestEntrySpanBodyLengthExcludesAnnotations(t *testing.T) {
+    withEnrichers(t, func(_ context.Context, l *LogEntry) { l.Body += " [trace:enriched]" })
+    cfg := config{AnnotationPattern: regexp.MustCompile(defaultAnnotationPattern)}
+    span := recordEntrySpan(t, LogEntry{Body: "checkout [trace:cart loaded] done"}, cfg)
+    AssertSpanAttribute(t, span, "log.body.length", len("checkout done"))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+    "log"
+    "os"
+    "regexp"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+var envTemplatePattern = regexp.MustCompile(`\$\{ENV:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Replace ${ENV:VAR_NAME} references with the variable's value; unset
+// variables resolve to an empty string and log a warning
+func resolveEnvTemplate(value string) string {
+    return envTemplatePattern.ReplaceAllStringFunc(value, func(ref string) string {
+        name := envTemplatePattern.FindStringSubmatch(ref)[1]
+        v, ok := os.LookupEnv(name)
+        if !ok {
+            log.Printf("Warning: environment variable %s referenced in %q is not set", name, value)
+        }
+        return v
+    })
+}
+
+// Resolve environment templates in every string attribute value
+func resolveEnvTemplates(attrs []attribute.KeyValue) []attribute.KeyValue {
+    out := make([]attribute.KeyValue, len(attrs))
+    for i, kv := range attrs {
+        if kv.Value.Type() == attribute.STRING {
+            kv = kv.Key.String(resolveEnvTemplate(kv.Value.AsString()))
+        }
+        out[i] = kv
+    }
+    return out
+}
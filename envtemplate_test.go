@@ -0,0 +1,41 @@
+package main
+
+import (
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestResolveEnvTemplate(t *testing.T) {
+    t.Setenv("DEPLOY_ENV", "staging")
+    t.Setenv("REGION", "eu-west-1")
+    for _, tc := range []struct{ value, want string }{
+        {"${ENV:DEPLOY_ENV}", "staging"},
+        {"api-${ENV:DEPLOY_ENV}-${ENV:REGION}", "api-staging-eu-west-1"},
+        {"${ENV:UNSET_TEMPLATE_VAR}-x", "-x"},
+        {"${DEPLOY_ENV}", "${DEPLOY_ENV}"},
+        {"plain", "plain"},
+    } {
+        if got := resolveEnvTemplate(tc.value); got != tc.want {
+            t.Errorf("resolveEnvTemplate(%q) = %q, want %q", tc.value, got, tc.want)
+        }
+    }
+}
+
+func TestResolveEnvTemplatesInResourceEnvironment(t *testing.T) {
+    t.Setenv("DEPLOY_ENV", "staging")
+    t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=${ENV:DEPLOY_ENV},team=core")
+
+    attrs := resolveEnvTemplates(append(resource.Environment().Attributes(), attribute.Int("replicas", 3)))
+    got := attribute.NewSet(attrs...)
+    if v, _ := got.Value("deployment.environment"); v.AsString() != "staging" {
+        t.Errorf("deployment.environment = %q", v.Emit())
+    }
+    if v, _ := got.Value("team"); v.AsString() != "core" {
+        t.Errorf("team = %q", v.Emit())
+    }
+    if v, _ := got.Value("replicas"); v.AsInt64() != 3 {
+        t.Errorf("replicas = %s", v.Emit())
+    }
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// Default number of wrapped causes recorded by LogEntryFromError
+const defaultMaxExceptionChain = 10
+
+// Causes recorded by LogEntryFromError, set from MAX_EXCEPTION_CHAIN at startup
+var maxExceptionChain = defaultMaxExceptionChain
+
+// Exception key counting the causes left out by the chain cap
+const causesOmittedKey = "exception.causes_omitted"
+
+// Implemented by errors that carry their own stack trace
+type stackTracer interface {
+    StackTrace() string
+}
+
+// Build an error LogEntry from a Go error. The exception map holds the
+// outermost error; each wrapped cause found via errors.Unwrap is added as
+// exception.cause.N.type and exception.cause.N.message, starting at 1.
+func LogEntryFromError(err error) LogEntry {
+    return logEntryFromError(err, maxExceptionChain)
+}
+
+// LogEntryFromError recording at most maxCauses wrapped causes; the number
+// of causes left out is recorded as exception.causes_omitted, an integer
+// attribute of the exception event. A maxCauses of zero or less records none.
+func logEntryFromError(err error, maxCauses int) LogEntry {
+    now := time.Now()
+    entry := LogEntry{
+        Timestamp:            formatTimestamp(now),
+        ObservedTimestamp:    formatTimestamp(now),
+        SeverityText:         "ERROR",
+        SeverityNumber:       "17",
+        Resource:             map[string]string{},
+        InstrumentationScope: map[string]string{},
+        Attributes:           map[string]string{},
+        EventData:            map[string]string{},
+        Exception:            map[string]string{},
+        Status:               "failed",
+        LogLevel:             "error",
+    }
+    if err == nil {
+        return entry
+    }
+
+    entry.Body = err.Error()
+    entry.Exception["exception.type"] = fmt.Sprintf("%T", err)
+    entry.Exception["exception.message"] = err.Error()
+    if st, ok := err.(stackTracer); ok {
+        entry.Exception["exception.stacktrace"] = st.StackTrace()
+    }
+
+    omitted := 0
+    for i, cause := 1, errors.Unwrap(err); cause != nil; i, cause = i+1, errors.Unwrap(cause) {
+        if i > maxCauses {
+            omitted++
+            continue
+        }
+        prefix := fmt.Sprintf("exception.cause.%d.", i)
+        entry.Exception[prefix+"type"] = fmt.Sprintf("%T", cause)
+        entry.Exception[prefix+"message"] = cause.Error()
+    }
+    if omitted > 0 {
+        entry.Exception[causesOmittedKey] = strconv.Itoa(omitted)
+    }
+    return entry
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+func TestLogEntryFromErrorCapsChain(t *testing.T) {
+    err := errors.New("root cause")
+    for i := 0; i < 5; i++ {
+        err = fmt.Errorf("layer %d: %w", i, err)
+    }
+    entry := logEntryFromError(err, 3)
+    if _, ok := entry.Exception["exception.cause.3.message"]; !ok {
+        t.Error("cause 3 not recorded")
+    }
+    if _, ok := entry.Exception["exception.cause.4.message"]; ok {
+        t.Error("cause beyond the cap recorded")
+    }
+
+    var omitted attribute.KeyValue
+    for _, kv := range exceptionAttributes(entry.Exception, 0) {
+        if kv.Key == causesOmittedKey {
+            omitted = kv
+        }
+    }
+    if omitted.Value.Type() != attribute.INT64 || omitted.Value.AsInt64() != 2 {
+        t.Errorf("%s = %v, want the integer 2", causesOmittedKey, omitted.Value.Emit())
+    }
+}
+
+type queryError struct {
+    query string
+    err   error
+}
+
+func (e *queryError) Error() string      { return "query " + e.query + ": " + e.err.Error() }
+func (e *queryError) Unwrap() error      { return e.err }
+func (e *queryError) StackTrace() string { return "main.runQuery\n\tdb.go:42" }
+
+func TestLogEntryFromWrappedError(t *testing.T) {
+    err := fmt.Errorf("load orders: %w", &queryError{query: "SELECT 1", err: errors.New("no rows")})
+    entry := LogEntryFromError(err)
+
+    want := map[string]string{
+        "exception.type":            "*fmt.wrapError",
+        "exception.message":         "load orders: query SELECT 1: no rows",
+        "exception.cause.1.type":    "*main.queryError",
+        "exception.cause.1.message": "query SELECT 1: no rows",
+        "exception.cause.2.type":    "*errors.errorString",
+        "exception.cause.2.message": "no rows",
+    }
+    for key, v := range want {
+        if entry.Exception[key] != v {
+            t.Errorf("%s = %q, want %q", key, entry.Exception[key], v)
+        }
+    }
+    if _, ok := entry.Exception["exception.cause.3.type"]; ok {
+        t.Error("cause recorded past the end of the chain")
+    }
+    if entry.Body != err.Error() || entry.SeverityText != "ERROR" || entry.Status != "failed" {
+        t.Errorf("entry = %+v", entry)
+    }
+    if !isErrorEntry(entry) {
+        t.Error("entry from an error is not an error entry")
+    }
+
+    traced := LogEntryFromError(&queryError{query: "SELECT 2", err: errors.New("boom")})
+    if traced.Exception["exception.stacktrace"] != "main.runQuery\n\tdb.go:42" {
+        t.Errorf("stacktrace = %q", traced.Exception["exception.stacktrace"])
+    }
+
+    if empty := LogEntryFromError(nil); empty.Body != "" || len(empty.Exception) != 0 {
+        t.Errorf("entry for a nil error = %+v", empty)
+    }
+}
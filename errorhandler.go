@@ -0,0 +1,15 @@
+package main
+
+import (
+    "log"
+
+    "go.opentelemetry.io/otel"
+)
+
+// Log SDK-internal errors (failed exports and the like) tagged with
+// source=otel-sdk so they stand apart from application output
+func sdkErrorHandler(logger *log.Logger) otel.ErrorHandler {
+    return otel.ErrorHandlerFunc(func(err error) {
+        logger.Printf("source=otel-sdk error=%q", err.Error())
+    })
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "log"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSDKErrorHandlerReceivesExportFailures(t *testing.T) {
+    var buf bytes.Buffer
+    saved := otel.GetErrorHandler()
+    otel.SetErrorHandler(sdkErrorHandler(log.New(&buf, "", 0)))
+    defer otel.SetErrorHandler(saved)
+
+    // The SDK reports a failed synchronous export through the global handler
+    tp := trace.NewTracerProvider(trace.WithSyncer(&flakyExporter{fail: true}))
+    _, span := tp.Tracer("test").Start(context.Background(), "op")
+    span.End()
+
+    got := buf.String()
+    if !strings.Contains(got, "source=otel-sdk") || !strings.Contains(got, "collector down") {
+        t.Errorf("handler output = %q", got)
+    }
+}
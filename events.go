@@ -0,0 +1,119 @@
+package main
+
+import (
+    "sort"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Default cap on span events recorded per LogEntry, matching the SDK's
+// default span event count limit
+const defaultMaxEntryEvents = 128
+
+// Event priorities, lowest first: exceptions, then error events, then the
+// rest, with annotations from the body kept last
+const (
+    priorityException = iota
+    priorityErrorEvent
+    priorityEvent
+    priorityAnnotation
+)
+
+type entryEvent struct {
+    name     string
+    priority int
+    attrs    []attribute.KeyValue
+}
+
+// Record the events of the LogEntry (its own and those from its body) on the
+// span, keeping only the maxEvents most important ones when there are more
+// (zero or less means no cap)
+func recordEntryEvents(span trace.Span, l LogEntry, events []entryEvent, maxEvents int) {
+    sort.SliceStable(events, func(i, j int) bool { return events[i].priority < events[j].priority })
+    if maxEvents > 0 && len(events) > maxEvents {
+        events = events[:maxEvents]
+    }
+    for _, e := range events {
+        span.AddEvent(e.name, trace.WithAttributes(e.attrs...), trace.WithTimestamp(spanNow()))
+    }
+
+    if len(l.Exception) > 0 {
+        span.SetAttributes(attribute.String("error.class",
+            classifyError(l.Exception["exception.type"], l.Exception["exception.message"])))
+    }
+}
+
+// Candidate span events carried by a LogEntry
+func entryEvents(l LogEntry, stacktraceLimit int) []entryEvent {
+    var events []entryEvent
+    if len(l.Exception) > 0 {
+        events = append(events, entryEvent{
+            name:     "exception",
+            priority: priorityException,
+            attrs:    exceptionAttributes(l.Exception, stacktraceLimit),
+        })
+    }
+    if len(l.EventData) > 0 {
+        name := l.EventData["event.name"]
+        if name == "" {
+            name = "event"
+        }
+        priority := priorityEvent
+        if l.EventData["event.type"] == "error" {
+            priority = priorityErrorEvent
+        }
+        events = append(events, entryEvent{
+            name:     name,
+            priority: priority,
+            attrs:    stringAttributes(l.EventData),
+        })
+    }
+    return events
+}
+
+// Map entries as string attributes, ordered by key
+func stringAttributes(m map[string]string) []attribute.KeyValue {
+    attrs := make([]attribute.KeyValue, 0, len(m))
+    for _, k := range sortedKeys(m) {
+        attrs = append(attrs, attribute.String(k, m[k]))
+    }
+    return attrs
+}
+
+// Fold attributes sharing a key into one string slice attribute holding
+// every value in order, at the position of the key's first occurrence, rather
+// than letting the last one win. Keys seen once are kept as they are.
+func aggregateRepeatedAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+    values := make(map[attribute.Key][]string, len(attrs))
+    for _, kv := range attrs {
+        values[kv.Key] = append(values[kv.Key], kv.Value.Emit())
+    }
+    if len(values) == len(attrs) {
+        return attrs
+    }
+
+    out := make([]attribute.KeyValue, 0, len(values))
+    for _, kv := range attrs {
+        vs, ok := values[kv.Key]
+        if !ok {
+            continue
+        }
+        delete(values, kv.Key)
+        if len(vs) == 1 {
+            out = append(out, kv)
+        } else {
+            out = append(out, attribute.StringSlice(string(kv.Key), vs))
+        }
+    }
+    return out
+}
+
+func sortedKeys(m map[string]string) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
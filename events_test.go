@@ -0,0 +1,29 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+func TestAggregateRepeatedAttributes(t *testing.T) {
+    attrs := []attribute.KeyValue{
+        attribute.String("tag", "a"),
+        attribute.String("http.method", "GET"),
+        attribute.Int("tag", 2),
+        attribute.String("tag", "c"),
+    }
+    want := []attribute.KeyValue{
+        attribute.StringSlice("tag", []string{"a", "2", "c"}),
+        attribute.String("http.method", "GET"),
+    }
+    if got := aggregateRepeatedAttributes(attrs); !reflect.DeepEqual(got, want) {
+        t.Errorf("aggregateRepeatedAttributes = %v, want %v", got, want)
+    }
+
+    unique := []attribute.KeyValue{attribute.String("a", "1"), attribute.String("b", "2")}
+    if got := aggregateRepeatedAttributes(unique); !reflect.DeepEqual(got, unique) {
+        t.Errorf("unique keys changed to %v", got)
+    }
+}
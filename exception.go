@@ -0,0 +1,100 @@
+package main
+
+import (
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+const truncatedMarker = "...[truncated]"
+
+// Attributes for the "exception" event, with the stacktrace truncated
+func exceptionAttributes(exception map[string]string, stacktraceLimit int) []attribute.KeyValue {
+    attrs := make([]attribute.KeyValue, 0, len(exception))
+    for _, k := range sortedKeys(exception) {
+        v := exception[k]
+        switch k {
+        case "exception.stacktrace":
+            v = truncateString(v, stacktraceLimit)
+        case causesOmittedKey:
+            if n, err := strconv.Atoi(v); err == nil {
+                attrs = append(attrs, attribute.Int(k, n))
+                continue
+            }
+        }
+        attrs = append(attrs, attribute.String(k, v))
+    }
+    return attrs
+}
+
+// Keywords for each error class, checked in order so that e.g. a
+// "connection timed out" is classified as a timeout rather than network.
+var errorClassRules = []struct {
+    class    string
+    keywords []string
+}{
+    {"timeout", []string{"timeout", "timed out", "deadline exceeded"}},
+    {"db", []string{"database", "sql", "db", "query", "deadlock"}},
+    {"network", []string{"network", "connection", "dial", "socket", "dns", "unreachable", "eof"}},
+    {"validation", []string{"validation", "invalid", "malformed", "required", "parse"}},
+}
+
+// Classify an error into a coarse category from its type and message.
+// Keywords match whole words only, so "db" does not match "feedback".
+func classifyError(exceptionType, message string) string {
+    text := " " + strings.Join(errorWords(exceptionType+" "+message), " ") + " "
+    for _, rule := range errorClassRules {
+        for _, keyword := range rule.keywords {
+            if strings.Contains(text, " "+keyword+" ") {
+                return rule.class
+            }
+        }
+    }
+    return "unknown"
+}
+
+// Lower-cased words of s, split on anything but letters and digits and at
+// camel-case boundaries, e.g. "SQLException" gives "sql" and "exception"
+func errorWords(s string) []string {
+    var words []string
+    for _, field := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+        runes := []rune(field)
+        start := 0
+        for i := 1; i < len(runes); i++ {
+            if !unicode.IsUpper(runes[i]) {
+                continue
+            }
+            afterLower := !unicode.IsUpper(runes[i-1])
+            acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+            if afterLower || acronymEnd {
+                words = append(words, strings.ToLower(string(runes[start:i])))
+                start = i
+            }
+        }
+        words = append(words, strings.ToLower(string(runes[start:])))
+    }
+    return words
+}
+
+// Shorten s to at most limit bytes, truncation marker included, with
+// truncateEndString. A limit of zero or less disables truncation.
+func truncateString(s string, limit int) string {
+    if limit <= 0 || len(s) <= limit {
+        return s
+    }
+    return truncateEndString(s, limit)
+}
+
+// Longest prefix of s that is at most n bytes and ends on a rune boundary
+func cutUTF8(s string, n int) string {
+    if len(s) <= n {
+        return s
+    }
+    for n > 0 && !utf8.RuneStart(s[n]) {
+        n--
+    }
+    return s[:n]
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+    "strings"
+    "testing"
+    "unicode/utf8"
+)
+
+func TestExceptionStacktraceTruncated(t *testing.T) {
+    stacktrace := strings.Repeat("at com.example.Handler.serve(Handler.java:42) → ", 400)
+    attrs := exceptionAttributes(map[string]string{
+        "exception.type":       "DatabaseError",
+        "exception.stacktrace": stacktrace,
+    }, defaultStacktraceLimit)
+
+    var got string
+    for _, kv := range attrs {
+        if kv.Key == "exception.stacktrace" {
+            got = kv.Value.AsString()
+        }
+    }
+    if !strings.HasSuffix(got, truncatedMarker) {
+        t.Fatalf("stacktrace not marked as truncated: %d bytes", len(got))
+    }
+    if len(got) > defaultStacktraceLimit || len(got) < defaultStacktraceLimit-utf8.UTFMax {
+        t.Errorf("stacktrace is %d bytes, want the %d byte limit, marker included", len(got), defaultStacktraceLimit)
+    }
+    if !utf8.ValidString(got) {
+        t.Error("truncated stacktrace is not valid UTF-8")
+    }
+    if short := truncateString("at main.go:1", defaultStacktraceLimit); short != "at main.go:1" {
+        t.Errorf("short stacktrace changed to %q", short)
+    }
+    if unlimited := truncateString(stacktrace, 0); unlimited != stacktrace {
+        t.Error("a zero limit truncated the stacktrace")
+    }
+}
+
+func TestClassifyError(t *testing.T) {
+    tests := []struct {
+        exceptionType, message string
+        want                   string
+    }{
+        {"net.OpError", "dial tcp 10.0.0.1:5432: connection timed out", "timeout"},
+        {"context.DeadlineExceeded", "context deadline exceeded", "timeout"},
+        {"*pq.Error", "deadlock detected", "db"},
+        {"SQLException", "syntax error", "db"},
+        {"IOError", "connection reset by peer", "network"},
+        {"", "unexpected EOF", "network"},
+        {"ValueError", "invalid literal for int()", "validation"},
+        {"", "field email is required", "validation"},
+        {"RuntimeError", "something broke", "unknown"},
+        {"RuntimeError", "feedback loop failed", "unknown"},
+        {"", "none thereof", "unknown"},
+        {"MySQLishError", "sqlite-like store", "unknown"},
+        {"NetworkError", "timeouts exhausted", "network"},
+        {"", "DB unavailable", "db"},
+        {"", "", "unknown"},
+    }
+    for _, tt := range tests {
+        if got := classifyError(tt.exceptionType, tt.message); got != tt.want {
+            t.Errorf("classifyError(%q, %q) = %q, want %q", tt.exceptionType, tt.message, got, tt.want)
+        }
+    }
+}
+
+func TestEntrySpanErrorClass(t *testing.T) {
+    span := recordEntrySpan(t, LogEntry{
+        Body:      "query failed",
+        Exception: map[string]string{"exception.type": "QueryError", "exception.message": "statement timeout"},
+    }, config{})
+    AssertSpanAttribute(t, span, "error.class", "timeout")
+}
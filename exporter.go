@@ -0,0 +1,31 @@
+package main
+
+import (
+    "fmt"
+    "io"
+
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Build the span exporter selected by OTEL_TRACES_EXPORTER; console output
+// is written to stdout
+func newExporter(cfg config, stdout io.Writer) (trace.SpanExporter, error) {
+    switch cfg.TracesExporter {
+    case "console":
+        return stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(stdout))
+    case "zipkin":
+        return newZipkinExporter(cfg.ZipkinEndpoint), nil
+    case "kafka":
+        return newKafkaExporter(cfg.KafkaBrokers, cfg.KafkaTopic)
+    case "protofile":
+        return newProtoFileExporter(cfg.ProtoFilePath, cfg.ProtoFileEncoding)
+    case "xray":
+        return newXRayExporter(cfg.XRayDaemonAddress)
+    case "websocket":
+        return newWebSocketExporter(cfg.WebSocketAddress, cfg.WebSocketOrigins)
+    case "elasticsearch":
+        return newElasticsearchExporter(cfg.ElasticsearchURL, cfg.ElasticsearchIndex, cfg.ESMappingFile)
+    }
+    return nil, fmt.Errorf("unknown traces exporter %q", cfg.TracesExporter)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter wrapper allowing at most limit concurrent ExportSpans calls
+type concurrencyLimitedExporter struct {
+    trace.SpanExporter
+    sem chan struct{}
+}
+
+func newConcurrencyLimitedExporter(exporter trace.SpanExporter, limit int) *concurrencyLimitedExporter {
+    return &concurrencyLimitedExporter{SpanExporter: exporter, sem: make(chan struct{}, limit)}
+}
+
+func (e *concurrencyLimitedExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    select {
+    case e.sem <- struct{}{}:
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+    defer func() { <-e.sem }()
+    return e.SpanExporter.ExportSpans(ctx, spans)
+}
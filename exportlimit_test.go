@@ -0,0 +1,62 @@
+package main
+
+import (
+    "context"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Exporter tracking the most ExportSpans calls in flight at once
+type concurrencyTrackingExporter struct {
+    tracetest.InMemoryExporter
+    active, peak atomic.Int32
+}
+
+func (e *concurrencyTrackingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    n := e.active.Add(1)
+    defer e.active.Add(-1)
+    for {
+        peak := e.peak.Load()
+        if n <= peak || e.peak.CompareAndSwap(peak, n) {
+            break
+        }
+    }
+    time.Sleep(2 * time.Millisecond)
+    return nil
+}
+
+func TestConcurrencyLimitedExporterNeverExceedsLimit(t *testing.T) {
+    inner := &concurrencyTrackingExporter{}
+    e := newConcurrencyLimitedExporter(inner, 3)
+    spans := tracetest.SpanStubs{{Name: "op"}}.Snapshots()
+
+    var wg sync.WaitGroup
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if err := e.ExportSpans(context.Background(), spans); err != nil {
+                t.Error(err)
+            }
+        }()
+    }
+    wg.Wait()
+    if peak := inner.peak.Load(); peak > 3 {
+        t.Errorf("%d concurrent exports, limit 3", peak)
+    }
+}
+
+func TestConcurrencyLimitedExporterHonorsContext(t *testing.T) {
+    e := newConcurrencyLimitedExporter(&concurrencyTrackingExporter{}, 1)
+    e.sem <- struct{}{}
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if err := e.ExportSpans(ctx, nil); err != context.DeadlineExceeded {
+        t.Errorf("err = %v, want the context deadline", err)
+    }
+}
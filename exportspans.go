@@ -0,0 +1,71 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Exporter wrapper tracing the export pipeline itself: every ExportSpans call
+// gets an "export batch" span recording the batch size, parented under a
+// long-lived "exporter" span that ends on shutdown. These meta-spans come
+// from a separate provider that hands them straight to the wrapped exporter,
+// so exporting them never produces further meta-spans.
+type batchSpanExporter struct {
+    trace.SpanExporter
+    provider *trace.TracerProvider
+    tracer   oteltrace.Tracer
+    root     oteltrace.Span
+    rootCtx  context.Context
+}
+
+func newBatchSpanExporter(exporter trace.SpanExporter, res *resource.Resource, name string) *batchSpanExporter {
+    provider := trace.NewTracerProvider(
+        trace.WithResource(res),
+        trace.WithSyncer(keepOpenExporter{exporter}),
+    )
+    tracer := provider.Tracer("export-pipeline")
+    rootCtx, root := tracer.Start(context.Background(), "exporter",
+        oteltrace.WithAttributes(attribute.String("exporter.name", name)))
+    return &batchSpanExporter{
+        SpanExporter: exporter,
+        provider:     provider,
+        tracer:       tracer,
+        root:         root,
+        rootCtx:      rootCtx,
+    }
+}
+
+func (e *batchSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    _, span := e.tracer.Start(e.rootCtx, "export batch",
+        oteltrace.WithAttributes(attribute.Int("export.batch.size", len(spans))))
+    err := e.SpanExporter.ExportSpans(ctx, spans)
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+    return err
+}
+
+// End the exporter span and flush it before shutting the wrapped exporter down
+func (e *batchSpanExporter) Shutdown(ctx context.Context) error {
+    e.root.End()
+    if err := e.provider.Shutdown(ctx); err != nil {
+        return err
+    }
+    return e.SpanExporter.Shutdown(ctx)
+}
+
+// Leaves shutting down the shared exporter to batchSpanExporter
+type keepOpenExporter struct {
+    trace.SpanExporter
+}
+
+func (keepOpenExporter) Shutdown(context.Context) error {
+    return nil
+}
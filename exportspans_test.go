@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// In-memory exporter that keeps its spans on shutdown and counts shutdowns
+type retainingExporter struct {
+    *tracetest.InMemoryExporter
+    shutdowns int
+}
+
+func (e *retainingExporter) Shutdown(context.Context) error {
+    e.shutdowns++
+    return nil
+}
+
+func TestBatchSpansRecordBatchSize(t *testing.T) {
+    inner := &retainingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+    e := newBatchSpanExporter(inner, resource.Empty(), "test")
+    ctx := context.Background()
+    if err := e.ExportSpans(ctx, tracetest.SpanStubs{{Name: "a"}, {Name: "b"}, {Name: "c"}}.Snapshots()); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(ctx, tracetest.SpanStubs{{Name: "d"}}.Snapshots()); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.Shutdown(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    var batches []trace.ReadOnlySpan
+    var root trace.ReadOnlySpan
+    for _, s := range inner.GetSpans().Snapshots() {
+        switch s.Name() {
+        case "export batch":
+            batches = append(batches, s)
+        case "exporter":
+            root = s
+        }
+    }
+    if len(batches) != 2 || root == nil {
+        t.Fatalf("got %d batch spans and root %v, want 2 and an exporter span", len(batches), root)
+    }
+    AssertSpanAttribute(t, batches[0], "export.batch.size", 3)
+    AssertSpanAttribute(t, batches[1], "export.batch.size", 1)
+    AssertSpanAttribute(t, root, "exporter.name", "test")
+    for _, b := range batches {
+        if b.Parent().SpanID() != root.SpanContext().SpanID() {
+            t.Errorf("batch span parent %s, want the exporter span %s", b.Parent().SpanID(), root.SpanContext().SpanID())
+        }
+    }
+    // 4 exported spans, 2 batch spans and the exporter span; exporting the
+    // meta-spans produced no further ones
+    if n := len(inner.GetSpans()); n != 7 {
+        t.Errorf("exported %d spans, want 7", n)
+    }
+    if inner.shutdowns != 1 {
+        t.Errorf("wrapped exporter shut down %d times, want 1", inner.shutdowns)
+    }
+}
+
+// Exporter rejecting batches that contain a span with the given name
+type rejectingExporter struct {
+    *tracetest.InMemoryExporter
+    reject string
+}
+
+func (e rejectingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    for _, s := range spans {
+        if s.Name() == e.reject {
+            return errors.New("rejected " + e.reject)
+        }
+    }
+    return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestBatchSpanRecordsExportError(t *testing.T) {
+    inner := rejectingExporter{InMemoryExporter: tracetest.NewInMemoryExporter(), reject: "a"}
+    e := newBatchSpanExporter(inner, resource.Empty(), "test")
+    if err := e.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "a"}}.Snapshots()); err == nil {
+        t.Fatal("export error not returned")
+    }
+    spans := inner.GetSpans().Snapshots()
+    if len(spans) != 1 || spans[0].Name() != "export batch" {
+        t.Fatalf("exported %v, want only the batch span", spans)
+    }
+    AssertSpanAttribute(t, spans[0], "export.batch.size", 1)
+    if status := spans[0].Status(); status.Code != codes.Error || status.Description != "rejected a" {
+        t.Errorf("batch span status = %+v", status)
+    }
+}
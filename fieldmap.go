@@ -0,0 +1,85 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "strings"
+)
+
+// Source key to LogEntry JSON key renames applied before decoding ingested
+// entries, set from LOG_FIELD_MAPPING at startup
+var logFieldMapping map[string]string
+
+// Parse "source=target" pairs separated by commas, e.g.
+// "msg=Body,level=LogLevel". Targets are LogEntry JSON keys, each mapped
+// from at most one source so the renamed value never depends on map order.
+func parseFieldMapping(value string) (map[string]string, error) {
+    known := make(map[string]bool)
+    t := reflect.TypeOf(LogEntry{})
+    for i := 0; i < t.NumField(); i++ {
+        name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+        known[name] = true
+    }
+
+    var mapping map[string]string
+    sources := make(map[string]string)
+    for _, pair := range strings.Split(value, ",") {
+        pair = strings.TrimSpace(pair)
+        if pair == "" {
+            continue
+        }
+        source, target, ok := strings.Cut(pair, "=")
+        source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+        if !ok || source == "" || target == "" {
+            return nil, fmt.Errorf("invalid field mapping %q: want source=target", pair)
+        }
+        if !known[target] {
+            return nil, fmt.Errorf("invalid field mapping %q: %q is not a LogEntry field", pair, target)
+        }
+        if other, ok := sources[target]; ok && other != source {
+            return nil, fmt.Errorf("invalid field mapping %q: %q is already mapped from %q", pair, target, other)
+        }
+        sources[target] = source
+        if mapping == nil {
+            mapping = make(map[string]string)
+        }
+        mapping[source] = target
+    }
+    return mapping, nil
+}
+
+// Rename the top-level keys of a JSON object per mapping. A mapped key
+// replaces any value already under its target.
+func remapFields(data []byte, mapping map[string]string) ([]byte, error) {
+    if len(mapping) == 0 {
+        return data, nil
+    }
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal(data, &fields); err != nil {
+        return nil, err
+    }
+    renamed := make(map[string]json.RawMessage, len(fields))
+    for k, v := range fields {
+        if _, mapped := mapping[k]; !mapped {
+            renamed[k] = v
+        }
+    }
+    for source, target := range mapping {
+        if v, ok := fields[source]; ok {
+            renamed[target] = v
+        }
+    }
+    return json.Marshal(renamed)
+}
+
+// Decode an ingested entry, first renaming its fields per logFieldMapping
+func decodeLogEntry(data []byte) (LogEntry, error) {
+    var l LogEntry
+    data, err := remapFields(data, logFieldMapping)
+    if err != nil {
+        return l, err
+    }
+    err = json.Unmarshal(data, &l)
+    return l, err
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestParseFieldMappingRejectsDuplicateTargets(t *testing.T) {
+    _, err := parseFieldMapping("msg=Body,message=Body")
+    if err == nil || !strings.Contains(err.Error(), `already mapped from "msg"`) {
+        t.Errorf("err = %v, want a duplicate target error", err)
+    }
+    if _, err := parseFieldMapping("msg=Body,msg=Body,level=LogLevel"); err != nil {
+        t.Errorf("repeated pair: %v", err)
+    }
+}
+
+func TestDecodeLogEntryRemapsFields(t *testing.T) {
+    mapping, err := parseFieldMapping("msg=Body,level=LogLevel")
+    if err != nil {
+        t.Fatal(err)
+    }
+    saved := logFieldMapping
+    logFieldMapping = mapping
+    defer func() { logFieldMapping = saved }()
+
+    l, err := decodeLogEntry([]byte(`{"msg":"hello","Body":"ignored","level":"WARN"}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if l.Body != "hello" || l.LogLevel != "WARN" {
+        t.Errorf("decoded Body=%q LogLevel=%q", l.Body, l.LogLevel)
+    }
+}
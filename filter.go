@@ -0,0 +1,67 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "sync/atomic"
+)
+
+// Drop rule matching entries whose attribute Key equals Value
+type dropRule struct {
+    Key   string `json:"key"`
+    Value string `json:"value"`
+}
+
+func (r dropRule) String() string {
+    return r.Key + "=" + r.Value
+}
+
+// Report whether any rule matches the entry's attributes
+func shouldDropEntry(l LogEntry, rules []dropRule) bool {
+    for _, rule := range rules {
+        if v, ok := l.Attributes[rule.Key]; ok && v == rule.Value {
+            return true
+        }
+    }
+    return false
+}
+
+// Ingest filter applying drop rules and counting the entries it drops
+type entryFilter struct {
+    rules   []dropRule
+    dropped atomic.Int64
+}
+
+func newEntryFilter(rules []dropRule) *entryFilter {
+    return &entryFilter{rules: rules}
+}
+
+// Report whether the entry should be kept, counting it if dropped
+func (f *entryFilter) Allow(l LogEntry) bool {
+    if shouldDropEntry(l, f.rules) {
+        f.dropped.Add(1)
+        return false
+    }
+    return true
+}
+
+func (f *entryFilter) Dropped() int64 {
+    return f.dropped.Load()
+}
+
+// Parse "key=value" rules separated by commas, e.g. "http.target=/healthz"
+func parseDropRules(value string) ([]dropRule, error) {
+    var rules []dropRule
+    for _, pair := range strings.Split(value, ",") {
+        pair = strings.TrimSpace(pair)
+        if pair == "" {
+            continue
+        }
+        key, v, ok := strings.Cut(pair, "=")
+        if !ok || strings.TrimSpace(key) == "" {
+            return nil, fmt.Errorf("invalid drop rule %q: want key=value", pair)
+        }
+        rules = append(rules, dropRule{Key: strings.TrimSpace(key), Value: strings.TrimSpace(v)})
+    }
+    return rules, nil
+}
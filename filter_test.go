@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestEntryFilterDropsAndCountsMatches(t *testing.T) {
+    rules, err := parseDropRules("http.target=/healthz, http.user_agent=kube-probe")
+    if err != nil {
+        t.Fatal(err)
+    }
+    f := newEntryFilter(rules)
+    for _, tc := range []struct {
+        attrs map[string]string
+        keep  bool
+    }{
+        {map[string]string{"http.target": "/healthz"}, false},
+        {map[string]string{"http.target": "/api/orders", "http.user_agent": "kube-probe"}, false},
+        {map[string]string{"http.target": "/api/orders"}, true},
+        {map[string]string{"http.target": "/healthz/deep"}, true},
+        {nil, true},
+    } {
+        if got := f.Allow(LogEntry{Attributes: tc.attrs}); got != tc.keep {
+            t.Errorf("Allow(%v) = %v, want %v", tc.attrs, got, tc.keep)
+        }
+    }
+    if n := f.Dropped(); n != 2 {
+        t.Errorf("Dropped() = %d, want 2", n)
+    }
+}
+
+func TestParseDropRulesRejectsMissingKey(t *testing.T) {
+    for _, value := range []string{"http.target", "=/healthz"} {
+        if _, err := parseDropRules(value); err == nil {
+            t.Errorf("%q: no error", value)
+        }
+    }
+}
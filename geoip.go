@@ -0,0 +1,115 @@
+package main
+
+import (
+    "context"
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "net/netip"
+    "os"
+    "strings"
+)
+
+// Geographic location of an IP address
+type geoLocation struct {
+    CountryISOCode string
+    Region         string
+}
+
+// Pluggable GeoIP database
+type geoIPLookup interface {
+    Lookup(ip netip.Addr) (geoLocation, bool)
+}
+
+// GeoIP database loaded from a CSV file of "cidr,country_iso_code,region"
+// rows; blank lines and lines starting with # are ignored. The most specific
+// matching network wins.
+type csvGeoIPDatabase struct {
+    networks []geoNetwork
+}
+
+type geoNetwork struct {
+    prefix   netip.Prefix
+    location geoLocation
+}
+
+func loadGeoIPDatabase(path string) (*csvGeoIPDatabase, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, fmt.Errorf("geoip database: %w", err)
+    }
+    defer f.Close()
+    return parseGeoIPDatabase(f)
+}
+
+func parseGeoIPDatabase(r io.Reader) (*csvGeoIPDatabase, error) {
+    cr := csv.NewReader(r)
+    cr.Comment = '#'
+    cr.FieldsPerRecord = -1
+    cr.TrimLeadingSpace = true
+
+    db := &csvGeoIPDatabase{}
+    for {
+        record, err := cr.Read()
+        if errors.Is(err, io.EOF) {
+            return db, nil
+        }
+        if err != nil {
+            return nil, fmt.Errorf("geoip database: %w", err)
+        }
+        if len(record) < 2 || len(record) > 3 {
+            line, _ := cr.FieldPos(0)
+            return nil, fmt.Errorf("geoip database line %d: want cidr,country_iso_code[,region]", line)
+        }
+        prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+        if err != nil {
+            line, _ := cr.FieldPos(0)
+            return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+        }
+        network := geoNetwork{prefix: prefix.Masked()}
+        network.location.CountryISOCode = strings.TrimSpace(record[1])
+        if len(record) == 3 {
+            network.location.Region = strings.TrimSpace(record[2])
+        }
+        db.networks = append(db.networks, network)
+    }
+}
+
+func (db *csvGeoIPDatabase) Lookup(ip netip.Addr) (geoLocation, bool) {
+    ip = ip.Unmap()
+    best := -1
+    var location geoLocation
+    for _, n := range db.networks {
+        if n.prefix.Bits() > best && n.prefix.Contains(ip) {
+            best = n.prefix.Bits()
+            location = n.location
+        }
+    }
+    return location, best >= 0
+}
+
+// Enricher adding geo.country.iso_code and geo.region from the entry's
+// host.ip. Private, loopback and other non-routable addresses are skipped.
+func geoIPEnricher(db geoIPLookup) Enricher {
+    return func(_ context.Context, l *LogEntry) {
+        raw := l.IPAddress
+        if raw == "" {
+            raw = l.Resource["host.ip"]
+        }
+        ip, err := netip.ParseAddr(raw)
+        if err != nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+            return
+        }
+        location, ok := db.Lookup(ip)
+        if !ok {
+            return
+        }
+        if location.CountryISOCode != "" {
+            l.Attributes["geo.country.iso_code"] = location.CountryISOCode
+        }
+        if location.Region != "" {
+            l.Attributes["geo.region"] = location.Region
+        }
+    }
+}
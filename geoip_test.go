@@ -0,0 +1,79 @@
+package main
+
+import (
+    "context"
+    "net/netip"
+    "strings"
+    "testing"
+)
+
+type stubGeoIPLookup map[netip.Addr]geoLocation
+
+func (s stubGeoIPLookup) Lookup(ip netip.Addr) (geoLocation, bool) {
+    location, ok := s[ip]
+    return location, ok
+}
+
+func TestGeoIPEnricher(t *testing.T) {
+    stub := stubGeoIPLookup{netip.MustParseAddr("203.0.113.7"): {CountryISOCode: "AU", Region: "NSW"}}
+    enrich := geoIPEnricher(stub)
+    tests := []struct {
+        name  string
+        entry LogEntry
+        want  map[string]string
+    }{
+        {"fixture ip", LogEntry{IPAddress: "203.0.113.7"}, map[string]string{"geo.country.iso_code": "AU", "geo.region": "NSW"}},
+        {"resource host.ip", LogEntry{Resource: map[string]string{"host.ip": "203.0.113.7"}}, map[string]string{"geo.country.iso_code": "AU", "geo.region": "NSW"}},
+        {"private", LogEntry{IPAddress: "10.1.2.3"}, map[string]string{}},
+        {"loopback", LogEntry{IPAddress: "127.0.0.1"}, map[string]string{}},
+        {"unknown", LogEntry{IPAddress: "198.51.100.1"}, map[string]string{}},
+        {"invalid", LogEntry{IPAddress: "not-an-ip"}, map[string]string{}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            l := tt.entry
+            l.Attributes = map[string]string{}
+            enrich(context.Background(), &l)
+            if len(l.Attributes) != len(tt.want) {
+                t.Fatalf("attributes = %v, want %v", l.Attributes, tt.want)
+            }
+            for k, v := range tt.want {
+                if l.Attributes[k] != v {
+                    t.Errorf("%s = %q, want %q", k, l.Attributes[k], v)
+                }
+            }
+        })
+    }
+}
+
+func TestCSVGeoIPDatabaseMostSpecificMatch(t *testing.T) {
+    db, err := parseGeoIPDatabase(strings.NewReader(`# cidr,country,region
+203.0.113.0/24,AU
+203.0.113.0/28,AU,NSW
+2001:db8::/32,DE,BE
+`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        ip   string
+        want geoLocation
+        ok   bool
+    }{
+        {"203.0.113.7", geoLocation{"AU", "NSW"}, true},
+        {"203.0.113.200", geoLocation{"AU", ""}, true},
+        {"::ffff:203.0.113.7", geoLocation{"AU", "NSW"}, true},
+        {"2001:db8::1", geoLocation{"DE", "BE"}, true},
+        {"198.51.100.1", geoLocation{}, false},
+    }
+    for _, tt := range tests {
+        got, ok := db.Lookup(netip.MustParseAddr(tt.ip))
+        if ok != tt.ok || got != tt.want {
+            t.Errorf("Lookup(%s) = %+v, %v, want %+v, %v", tt.ip, got, ok, tt.want, tt.ok)
+        }
+    }
+
+    if _, err := parseGeoIPDatabase(strings.NewReader("not-a-cidr,AU\n")); err == nil {
+        t.Error("invalid network parsed without error")
+    }
+}
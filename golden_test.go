@@ -0,0 +1,142 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "io"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// Fields of the stdout exporter output that change from run to run
+var volatileSpanFields = map[string]bool{
+    "TraceID":   true,
+    "SpanID":    true,
+    "StartTime": true,
+    "EndTime":   true,
+    "Time":      true,
+}
+
+// Stdout exporter output (a stream of JSON spans) re-indented with volatile
+// fields replaced by a placeholder and object keys sorted, so only changes
+// to the output format or the recorded data show up in a diff
+func normalizeSpanOutput(t *testing.T, data []byte) []byte {
+    t.Helper()
+    var out bytes.Buffer
+    enc := json.NewEncoder(&out)
+    enc.SetEscapeHTML(false)
+    enc.SetIndent("", "  ")
+    dec := json.NewDecoder(bytes.NewReader(data))
+    for {
+        var span any
+        if err := dec.Decode(&span); errors.Is(err, io.EOF) {
+            break
+        } else if err != nil {
+            t.Fatalf("span output: %v", err)
+        }
+        if err := enc.Encode(stripVolatileFields(span)); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return out.Bytes()
+}
+
+func stripVolatileFields(v any) any {
+    switch v := v.(type) {
+    case map[string]any:
+        for k, field := range v {
+            if volatileSpanFields[k] {
+                v[k] = "<" + k + ">"
+                continue
+            }
+            v[k] = stripVolatileFields(field)
+        }
+    case []any:
+        for i, item := range v {
+            v[i] = stripVolatileFields(item)
+        }
+    }
+    return v
+}
+
+// Compare normalized span output with testdata/<name>.golden, rewriting the
+// golden file instead when the -update flag is set
+func assertGoldenSpans(t *testing.T, name string, output []byte) {
+    t.Helper()
+    got := normalizeSpanOutput(t, output)
+    path := filepath.Join("testdata", name+".golden")
+    if *updateGolden {
+        if err := os.WriteFile(path, got, 0o644); err != nil {
+            t.Fatal(err)
+        }
+        return
+    }
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("%v (run with -update to create it)", err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("span output does not match %s (run with -update to accept it):\n%s", path, got)
+    }
+}
+
+func TestExampleSpanGolden(t *testing.T) {
+    entry := exampleLogEntry(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), "example-host", "10.0.0.1", "00:11:22:33:44:55")
+    if err := computeDuration(&entry); err != nil {
+        t.Fatal(err)
+    }
+    cfg := loadConfig()
+
+    var buf bytes.Buffer
+    exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(&buf))
+    if err != nil {
+        t.Fatal(err)
+    }
+    tp := trace.NewTracerProvider(trace.WithSyncer(exporter), trace.WithResource(resource.Empty()))
+    _, span := startEntrySpan(context.Background(), tp.Tracer("example-tracer"), entry, cfg)
+    endEntrySpan(span, entry, cfg)
+    if err := tp.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+
+    assertGoldenSpans(t, "example_span", buf.Bytes())
+}
+
+func TestNormalizeSpanOutputStripsVolatileFields(t *testing.T) {
+    output := []byte(`{"Name":"a","SpanContext":{"TraceID":"01","SpanID":"02"},"StartTime":"2024-01-01T00:00:00Z","Events":[{"Name":"e","Time":"2024-01-01T00:00:01Z"}]}
+{"Name":"b","EndTime":"2024-01-01T00:00:02Z"}
+`)
+    want := `{
+  "Events": [
+    {
+      "Name": "e",
+      "Time": "<Time>"
+    }
+  ],
+  "Name": "a",
+  "SpanContext": {
+    "SpanID": "<SpanID>",
+    "TraceID": "<TraceID>"
+  },
+  "StartTime": "<StartTime>"
+}
+{
+  "EndTime": "<EndTime>",
+  "Name": "b"
+}
+`
+    if got := string(normalizeSpanOutput(t, output)); got != want {
+        t.Errorf("normalized output:\n%s\nwant:\n%s", got, want)
+    }
+}
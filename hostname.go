@@ -0,0 +1,79 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "strings"
+)
+
+// Where a hostname can come from, tried in order by resolveHostname
+type HostnameSource struct {
+    // "env" (the variable named by Value), "file" (the first line of the
+    // file at Value), "os" (os.Hostname) or "static" (Value itself)
+    Kind  string
+    Value string
+}
+
+func (s HostnameSource) String() string {
+    if s.Value == "" {
+        return s.Kind
+    }
+    return s.Kind + ":" + s.Value
+}
+
+// Hostname from this source, or false when it has none
+func (s HostnameSource) lookup() (string, bool) {
+    var name string
+    switch s.Kind {
+    case "env":
+        name = os.Getenv(s.Value)
+    case "file":
+        data, err := os.ReadFile(s.Value)
+        if err != nil {
+            return "", false
+        }
+        name, _, _ = strings.Cut(string(data), "\n")
+    case "os":
+        name, _ = os.Hostname()
+    case "static":
+        name = s.Value
+    }
+    name = strings.TrimSpace(name)
+    return name, name != ""
+}
+
+// Hostname from the first source in the chain that has one, or "" when none
+// does
+func resolveHostname(sources []HostnameSource) string {
+    for _, s := range sources {
+        if name, ok := s.lookup(); ok {
+            return name
+        }
+    }
+    return ""
+}
+
+// Parse a comma-separated source chain, e.g.
+// "env:NODE_NAME,file:/etc/hostname,os,static:localhost"
+func parseHostnameSources(value string) ([]HostnameSource, error) {
+    var sources []HostnameSource
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        kind, v, _ := strings.Cut(item, ":")
+        source := HostnameSource{Kind: strings.TrimSpace(kind), Value: strings.TrimSpace(v)}
+        switch source.Kind {
+        case "env", "file", "static":
+            if source.Value == "" {
+                return nil, fmt.Errorf("invalid hostname source %q: want %s:<value>", item, source.Kind)
+            }
+        case "os":
+        default:
+            return nil, fmt.Errorf("invalid hostname source %q: want env, file, os or static", item)
+        }
+        sources = append(sources, source)
+    }
+    return sources, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+func TestResolveHostnameFirstAvailableSourceWins(t *testing.T) {
+    dir := t.TempDir()
+    hostnameFile := filepath.Join(dir, "hostname")
+    if err := os.WriteFile(hostnameFile, []byte("  file-host\nignored\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    blankFile := filepath.Join(dir, "blank")
+    if err := os.WriteFile(blankFile, []byte("\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("TEST_NODE_NAME", "env-host")
+    t.Setenv("TEST_EMPTY_NAME", "")
+
+    sources, err := parseHostnameSources("env:TEST_UNSET_NAME, env:TEST_EMPTY_NAME, file:" + filepath.Join(dir, "missing") +
+        ", file:" + blankFile + ", file:" + hostnameFile + ", env:TEST_NODE_NAME, static:fallback")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        name string
+        skip int
+        want string
+    }{
+        {"file after unavailable sources", 0, "file-host"},
+        {"env", 5, "env-host"},
+        {"static", 6, "fallback"},
+        {"none", len(sources), ""},
+    }
+    for _, tt := range tests {
+        if got := resolveHostname(sources[tt.skip:]); got != tt.want {
+            t.Errorf("%s: resolveHostname = %q, want %q", tt.name, got, tt.want)
+        }
+    }
+
+    osName, err := os.Hostname()
+    if err == nil {
+        if got := resolveHostname([]HostnameSource{{Kind: "env", Value: "TEST_UNSET_NAME"}, {Kind: "os"}}); got != osName {
+            t.Errorf("os source = %q, want %q", got, osName)
+        }
+    }
+}
+
+func TestParseHostnameSources(t *testing.T) {
+    got, err := parseHostnameSources("env:NODE_NAME,file:/etc/hostname,os,static:localhost")
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []HostnameSource{{"env", "NODE_NAME"}, {"file", "/etc/hostname"}, {"os", ""}, {"static", "localhost"}}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("parseHostnameSources = %v, want %v", got, want)
+    }
+    for _, value := range []string{"env", "static:", "dns:example.com"} {
+        if _, err := parseHostnameSources(value); err == nil {
+            t.Errorf("parseHostnameSources(%q) succeeded, want error", value)
+        }
+    }
+}
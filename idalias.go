@@ -0,0 +1,90 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "regexp"
+    "strings"
+    "sync"
+)
+
+// Matches trace and span IDs in both the stdouttrace output (TraceID/SpanID)
+// and the LogEntry JSON (TraceId/SpanId), indented or compact
+var idFieldPattern = regexp.MustCompile(`"(TraceI[Dd]|SpanI[Dd])":(\s*)"([0-9a-fA-F]+)"`)
+
+// Matches a W3C traceparent value such as the one injected into entries
+var traceparentPattern = regexp.MustCompile(`\b([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})\b`)
+
+// Display transform replacing trace and span IDs with short, stable aliases
+// such as trace-1 and span-2. The same ID always maps to the same alias.
+type idAliaser struct {
+    mu     sync.Mutex
+    traces map[string]string
+    spans  map[string]string
+}
+
+func newIDAliaser() *idAliaser {
+    return &idAliaser{traces: make(map[string]string), spans: make(map[string]string)}
+}
+
+// Alias for a trace ID, or the ID unchanged if it is all zeros
+func (a *idAliaser) TraceAlias(id string) string {
+    return a.alias(a.traces, "trace", id)
+}
+
+// Alias for a span ID, or the ID unchanged if it is all zeros
+func (a *idAliaser) SpanAlias(id string) string {
+    return a.alias(a.spans, "span", id)
+}
+
+func (a *idAliaser) alias(m map[string]string, prefix, id string) string {
+    if strings.Trim(id, "0") == "" {
+        return id
+    }
+    id = strings.ToLower(id)
+
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if alias, ok := m[id]; ok {
+        return alias
+    }
+    alias := fmt.Sprintf("%s-%d", prefix, len(m)+1)
+    m[id] = alias
+    return alias
+}
+
+// Replace every trace/span ID field and traceparent in s with aliases
+func (a *idAliaser) Mask(s string) string {
+    s = idFieldPattern.ReplaceAllStringFunc(s, func(field string) string {
+        m := idFieldPattern.FindStringSubmatch(field)
+        var alias string
+        if strings.HasPrefix(m[1], "Trace") {
+            alias = a.TraceAlias(m[3])
+        } else {
+            alias = a.SpanAlias(m[3])
+        }
+        return fmt.Sprintf(`"%s":%s"%s"`, m[1], m[2], alias)
+    })
+    return traceparentPattern.ReplaceAllStringFunc(s, func(tp string) string {
+        m := traceparentPattern.FindStringSubmatch(tp)
+        return fmt.Sprintf("%s-%s-%s-%s", m[1], a.TraceAlias(m[2]), a.SpanAlias(m[3]), m[4])
+    })
+}
+
+// Wrap w so everything written through it has its IDs masked. Each Write is
+// expected to carry whole JSON documents, as the stdouttrace encoder does.
+func (a *idAliaser) Writer(w io.Writer) io.Writer {
+    return aliasWriter{a: a, w: w}
+}
+
+type aliasWriter struct {
+    a *idAliaser
+    w io.Writer
+}
+
+func (w aliasWriter) Write(p []byte) (int, error) {
+    if _, err := io.WriteString(w.w, w.a.Mask(string(p))); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestMaskRemovesEveryRealID(t *testing.T) {
+    aliases := newIDAliaser()
+    var console bytes.Buffer
+    exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(aliases.Writer(&console)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    tp := trace.NewTracerProvider(trace.WithSyncer(exporter))
+
+    ctx, span := tp.Tracer("test").Start(context.Background(), "entry")
+    entry := LogEntry{Attributes: map[string]string{}}
+    injectContextIntoEntry(ctx, &entry)
+    span.End()
+    if err := tp.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if entry.Attributes["traceparent"] == "" {
+        t.Fatal("no traceparent injected")
+    }
+
+    for _, indent := range []string{"", "  "} {
+        data, err := entry.Marshal(indent)
+        if err != nil {
+            t.Fatal(err)
+        }
+        output := aliases.Mask(string(data)) + console.String()
+        for _, id := range []string{span.SpanContext().TraceID().String(), span.SpanContext().SpanID().String()} {
+            if strings.Contains(output, id) {
+                t.Errorf("indent %q: masked output contains real ID %s:\n%s", indent, id, output)
+            }
+        }
+    }
+    if want := "00-trace-1-span-1-01"; !strings.Contains(aliases.Mask(entry.Attributes["traceparent"]), want) {
+        t.Errorf("traceparent masked as %q, want %q", aliases.Mask(entry.Attributes["traceparent"]), want)
+    }
+}
+
+func TestAliasesAreConsistent(t *testing.T) {
+    a := newIDAliaser()
+    first := "4bf92f3577b34da6a3ce929d0e0e4736"
+    second := "00f067aa0ba902b700f067aa0ba902b7"
+    if got := a.TraceAlias(first); got != "trace-1" {
+        t.Errorf("first trace alias = %q", got)
+    }
+    if got := a.TraceAlias(second); got != "trace-2" {
+        t.Errorf("second trace alias = %q", got)
+    }
+    if got := a.TraceAlias(strings.ToUpper(first)); got != "trace-1" {
+        t.Errorf("same ID in upper case aliased as %q, want trace-1", got)
+    }
+    if got := a.SpanAlias("00f067aa0ba902b7"); got != "span-1" {
+        t.Errorf("span alias = %q, want its own numbering", got)
+    }
+    if zero := strings.Repeat("0", 32); a.TraceAlias(zero) != zero {
+        t.Error("all-zero trace ID aliased")
+    }
+
+    // The same ID gets the same alias wherever it appears
+    masked := a.Mask(`{"TraceID":"` + second + `"} {"TraceId": "` + second + `"}`)
+    if strings.Count(masked, "trace-2") != 2 || strings.Contains(masked, second) {
+        t.Errorf("masked output %s", masked)
+    }
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Span processor copying selected attributes from the parent span in the
+// start context onto each new child. Only attributes already set on the
+// parent when the child starts are inherited; remote parents carry none.
+type inheritAttributesProcessor struct {
+    keys map[attribute.Key]struct{}
+}
+
+func newInheritAttributesProcessor(keys []string) *inheritAttributesProcessor {
+    p := &inheritAttributesProcessor{keys: make(map[attribute.Key]struct{}, len(keys))}
+    for _, k := range keys {
+        p.keys[attribute.Key(k)] = struct{}{}
+    }
+    return p
+}
+
+func (p *inheritAttributesProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    ps, ok := oteltrace.SpanFromContext(parent).(trace.ReadOnlySpan)
+    if !ok {
+        return
+    }
+    var inherited []attribute.KeyValue
+    for _, kv := range ps.Attributes() {
+        if _, ok := p.keys[kv.Key]; ok {
+            inherited = append(inherited, kv)
+        }
+    }
+    s.SetAttributes(inherited...)
+}
+
+func (p *inheritAttributesProcessor) OnEnd(trace.ReadOnlySpan) {}
+
+func (p *inheritAttributesProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *inheritAttributesProcessor) ForceFlush(context.Context) error { return nil }
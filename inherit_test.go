@@ -0,0 +1,42 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestChildInheritsSelectedParentAttributes(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(
+        trace.WithSpanProcessor(newInheritAttributesProcessor([]string{"tenant.id"})),
+        trace.WithSpanProcessor(recorder))
+    tracer := tp.Tracer("test")
+
+    ctx, parent := tracer.Start(context.Background(), "parent", oteltrace.WithAttributes(
+        attribute.String("tenant.id", "acme"),
+        attribute.String("user.id", "u-1")))
+    _, child := tracer.Start(ctx, "child")
+    child.End()
+    parent.End()
+
+    var got trace.ReadOnlySpan
+    for _, s := range recorder.Ended() {
+        if s.Name() == "child" {
+            got = s
+        }
+    }
+    if got == nil {
+        t.Fatal("child span not recorded")
+    }
+    AssertSpanAttribute(t, got, "tenant.id", "acme")
+    for _, kv := range got.Attributes() {
+        if kv.Key == "user.id" {
+            t.Errorf("child inherited unselected attribute %s", kv.Key)
+        }
+    }
+}
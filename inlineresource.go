@@ -0,0 +1,48 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Span processor copying the span's resource attributes onto the span itself
+// before handing it to the next processor, for backends that don't model
+// resources. Attributes already set on the span take precedence.
+type inlineResourceProcessor struct {
+    next trace.SpanProcessor
+}
+
+func newInlineResourceProcessor(next trace.SpanProcessor) *inlineResourceProcessor {
+    return &inlineResourceProcessor{next: next}
+}
+
+func (p *inlineResourceProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *inlineResourceProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if res := s.Resource(); res != nil {
+        existing := make(map[attribute.Key]struct{})
+        for _, kv := range s.Attributes() {
+            existing[kv.Key] = struct{}{}
+        }
+        var inlined []attribute.KeyValue
+        for _, kv := range res.Attributes() {
+            if _, ok := existing[kv.Key]; !ok {
+                inlined = append(inlined, kv)
+            }
+        }
+        s = withAttributes(s, inlined...)
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *inlineResourceProcessor) Shutdown(ctx context.Context) error {
+    return p.next.Shutdown(ctx)
+}
+
+func (p *inlineResourceProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
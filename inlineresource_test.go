@@ -0,0 +1,33 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestInlineResourceCopiesResourceAttributes(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    res := resource.NewSchemaless(
+        attribute.String("service.name", "otelprac2"),
+        attribute.String("deployment.environment", "prod"))
+    tp := trace.NewTracerProvider(
+        trace.WithResource(res),
+        trace.WithSpanProcessor(newInlineResourceProcessor(recorder)))
+    _, span := tp.Tracer("test").Start(context.Background(), "op",
+        oteltrace.WithAttributes(attribute.String("deployment.environment", "staging")))
+    span.End()
+
+    ended := recorder.Ended()
+    if len(ended) != 1 {
+        t.Fatalf("recorded %d spans, want 1", len(ended))
+    }
+    AssertSpanAttribute(t, ended[0], "service.name", "otelprac2")
+    // The span's own value wins over the resource's
+    AssertSpanAttribute(t, ended[0], "deployment.environment", "staging")
+}
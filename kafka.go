@@ -0,0 +1,73 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/segmentio/kafka-go"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const defaultKafkaTopic = "otel-spans"
+
+// How long the writer waits for more messages before sending a partial
+// batch. Every export call writes synchronously with all its messages at
+// once, so waiting the default second would only delay each export.
+const kafkaBatchTimeout = 5 * time.Millisecond
+
+// The part of *kafka.Writer the exporter uses
+type kafkaMessageWriter interface {
+    WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+    Close() error
+}
+
+// Exporter publishing each span as a JSON message (the same span stub the
+// stdouttrace exporter prints) to a Kafka topic, keyed by trace ID so a
+// trace's spans land on one partition
+type kafkaExporter struct {
+    writer kafkaMessageWriter
+}
+
+func newKafkaExporter(brokers []string, topic string) (*kafkaExporter, error) {
+    if len(brokers) == 0 {
+        return nil, fmt.Errorf("kafka exporter needs at least one broker")
+    }
+    if topic == "" {
+        topic = defaultKafkaTopic
+    }
+    return &kafkaExporter{writer: &kafka.Writer{
+        Addr:         kafka.TCP(brokers...),
+        Topic:        topic,
+        Balancer:     &kafka.Hash{},
+        BatchTimeout: kafkaBatchTimeout,
+    }}, nil
+}
+
+// Produce all spans of the export call in a single batch
+func (e *kafkaExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if len(spans) == 0 {
+        return nil
+    }
+    msgs := make([]kafka.Message, 0, len(spans))
+    for _, s := range spans {
+        value, err := json.Marshal(tracetest.SpanStubFromReadOnlySpan(s))
+        if err != nil {
+            return err
+        }
+        msgs = append(msgs, kafka.Message{
+            Key:   []byte(s.SpanContext().TraceID().String()),
+            Value: value,
+        })
+    }
+    if err := e.writer.WriteMessages(ctx, msgs...); err != nil {
+        return fmt.Errorf("kafka export: %w", err)
+    }
+    return nil
+}
+
+func (e *kafkaExporter) Shutdown(context.Context) error {
+    return e.writer.Close()
+}
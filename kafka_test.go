@@ -0,0 +1,87 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "testing"
+
+    "github.com/segmentio/kafka-go"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// In-memory stand-in for a Kafka broker connection
+type mockKafkaWriter struct {
+    msgs   []kafka.Message
+    err    error
+    closed bool
+}
+
+func (w *mockKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+    if w.err != nil {
+        return w.err
+    }
+    w.msgs = append(w.msgs, msgs...)
+    return nil
+}
+
+func (w *mockKafkaWriter) Close() error {
+    w.closed = true
+    return nil
+}
+
+func TestKafkaExporterPublishesSpans(t *testing.T) {
+    writer := &mockKafkaWriter{}
+    tp := trace.NewTracerProvider(trace.WithSyncer(&kafkaExporter{writer: writer}))
+    ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+    _, child := tp.Tracer("test").Start(ctx, "child")
+    child.End()
+    parent.End()
+    if err := tp.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+
+    if len(writer.msgs) != 2 {
+        t.Fatalf("published %d messages, want 2", len(writer.msgs))
+    }
+    for i, want := range []string{"child", "parent"} {
+        msg := writer.msgs[i]
+        if got := string(msg.Key); got != parent.SpanContext().TraceID().String() {
+            t.Errorf("message %d keyed %q, want the trace ID", i, got)
+        }
+        var stub struct{ Name string }
+        if err := json.Unmarshal(msg.Value, &stub); err != nil {
+            t.Fatalf("message %d: %v", i, err)
+        }
+        if stub.Name != want {
+            t.Errorf("message %d holds span %q, want %q", i, stub.Name, want)
+        }
+    }
+    if !writer.closed {
+        t.Error("writer not closed on shutdown")
+    }
+}
+
+func TestKafkaExporterReportsWriteErrors(t *testing.T) {
+    writer := &mockKafkaWriter{err: errors.New("broker down")}
+    e := &kafkaExporter{writer: writer}
+    err := e.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "s"}}.Snapshots())
+    if err == nil || !errors.Is(err, writer.err) {
+        t.Errorf("export error %v, want it to wrap %v", err, writer.err)
+    }
+}
+
+func TestNewKafkaExporterDoesNotWaitForBatches(t *testing.T) {
+    e, err := newKafkaExporter([]string{"localhost:9092"}, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    w := e.writer.(*kafka.Writer)
+    if w.BatchTimeout != kafkaBatchTimeout || w.Topic != defaultKafkaTopic {
+        t.Errorf("writer batch timeout %v topic %q, want %v %q", w.BatchTimeout, w.Topic, kafkaBatchTimeout, defaultKafkaTopic)
+    }
+    if _, err := newKafkaExporter(nil, ""); err == nil {
+        t.Error("no error without brokers")
+    }
+}
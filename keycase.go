@@ -0,0 +1,61 @@
+package main
+
+import (
+    "strings"
+    "unicode"
+
+    "go.opentelemetry.io/otel/attribute"
+)
+
+// Case entry attribute keys are normalized to, so keys from sources with
+// inconsistent casing land on the same backend field
+type keyCase string
+
+const (
+    // Keys are left as they are
+    keyCaseNone  keyCase = "none"
+    keyCaseLower keyCase = "lower"
+    keyCaseUpper keyCase = "upper"
+    // camelCase words split with underscores, then lowercased
+    keyCaseSnake keyCase = "snake"
+)
+
+func (c keyCase) apply(key string) string {
+    switch c {
+    case keyCaseLower:
+        return strings.ToLower(key)
+    case keyCaseUpper:
+        return strings.ToUpper(key)
+    case keyCaseSnake:
+        return snakeCase(key)
+    }
+    return key
+}
+
+// "httpStatusCode" -> "http_status_code"; separators such as "." are kept
+func snakeCase(key string) string {
+    var b strings.Builder
+    runes := []rune(key)
+    for i, r := range runes {
+        if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+            b.WriteByte('_')
+        }
+        b.WriteRune(unicode.ToLower(r))
+    }
+    return b.String()
+}
+
+// Attributes with their keys converted to c, in the same order. Keys that
+// collide after conversion are left as duplicates for the duplicate-key
+// policy: folded together with AGGREGATE_REPEATED_ATTRIBUTES, otherwise the
+// last one wins.
+func normalizeAttributeKeys(attrs []attribute.KeyValue, c keyCase) []attribute.KeyValue {
+    if c == "" || c == keyCaseNone {
+        return attrs
+    }
+    out := make([]attribute.KeyValue, len(attrs))
+    for i, kv := range attrs {
+        out[i] = attribute.KeyValue{Key: attribute.Key(c.apply(string(kv.Key))), Value: kv.Value}
+    }
+    return out
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestKeyCaseApply(t *testing.T) {
+    tests := []struct {
+        c    keyCase
+        key  string
+        want string
+    }{
+        {keyCaseNone, "HTTP.Method", "HTTP.Method"},
+        {keyCaseLower, "HTTP.Method", "http.method"},
+        {keyCaseUpper, "http.method", "HTTP.METHOD"},
+        {keyCaseSnake, "httpStatusCode", "http_status_code"},
+        {keyCaseSnake, "db.rowCount2Value", "db.row_count2_value"},
+        {keyCaseSnake, "HTTPMethod", "httpmethod"},
+    }
+    for _, tt := range tests {
+        if got := tt.c.apply(tt.key); got != tt.want {
+            t.Errorf("%s.apply(%q) = %q, want %q", tt.c, tt.key, got, tt.want)
+        }
+    }
+}
+
+func TestMixedCaseKeysNormalizeAndCollide(t *testing.T) {
+    l := LogEntry{Body: "request", Attributes: map[string]string{
+        "User.ID":     "u-1",
+        "user.id":     "u-2",
+        "HTTP.Method": "GET",
+    }}
+
+    // Last one wins by default, in sorted key order
+    span := recordEntrySpan(t, l, config{AttributeKeyCase: keyCaseLower})
+    AssertSpanAttribute(t, span, "http.method", "GET")
+    AssertSpanAttribute(t, span, "user.id", "u-2")
+
+    // Aggregation folds the colliding keys instead
+    span = recordEntrySpan(t, l, config{AttributeKeyCase: keyCaseLower, AggregateRepeated: true})
+    var got []string
+    for _, kv := range span.Attributes() {
+        if kv.Key == "user.id" {
+            got = kv.Value.AsStringSlice()
+        }
+        if kv.Key == "User.ID" {
+            t.Errorf("unnormalized key %s kept", kv.Key)
+        }
+    }
+    if want := []string{"u-1", "u-2"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("user.id = %q, want %q", got, want)
+    }
+}
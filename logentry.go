@@ -0,0 +1,117 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Check the entry's fields, returning every problem found joined into one
+// error. Host IP and MAC addresses and the parent span ID may be empty but
+// must parse when set.
+func (l LogEntry) Validate() error {
+    var errs []error
+    if l.ParentSpanID != "" {
+        if _, err := trace.SpanIDFromHex(l.ParentSpanID); err != nil {
+            errs = append(errs, fmt.Errorf("parent_span_id %q is not a 16-digit hex span ID", l.ParentSpanID))
+        }
+    }
+    if l.IPAddress != "" && net.ParseIP(l.IPAddress) == nil {
+        errs = append(errs, fmt.Errorf("host.ip %q is not a valid IP address", l.IPAddress))
+    }
+    if l.MacAddress != "" {
+        if _, err := net.ParseMAC(l.MacAddress); err != nil {
+            errs = append(errs, fmt.Errorf("host.mac %q is not a valid MAC address", l.MacAddress))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// Copy of the entry with its timestamps rendered in loc; timestamps that do
+// not parse are left as they are
+func (l LogEntry) InTimezone(loc *time.Location) LogEntry {
+    for _, ts := range []*string{&l.Timestamp, &l.ObservedTimestamp, &l.EndTimestamp} {
+        if t, err := parseTimestamp(*ts); err == nil {
+            *ts = formatTimestamp(t.In(loc))
+        }
+    }
+    return l
+}
+
+// Copy of the entry without the resource attributes whose key is in keys,
+// removed from its Resource and, for host.name, host.ip and host.mac, from
+// the top-level host fields, which are left out of the JSON when empty
+func (l LogEntry) withoutResourceKeys(keys []string) LogEntry {
+    if len(keys) == 0 {
+        return l
+    }
+    resource := make(map[string]string, len(l.Resource))
+    for k, v := range l.Resource {
+        resource[k] = v
+    }
+    for _, k := range keys {
+        delete(resource, k)
+        switch k {
+        case "host.name":
+            l.Hostname = ""
+        case "host.ip":
+            l.IPAddress = ""
+        case "host.mac":
+            l.MacAddress = ""
+        }
+    }
+    if l.Resource != nil {
+        l.Resource = resource
+    }
+    return l
+}
+
+// Version of the LogEntry schema, recorded as log.schema.version on entry
+// spans and in marshalled entries. Bump it whenever LogEntry fields change.
+// Version 2 added parent_span_id.
+const logSchemaVersion = "2"
+
+// Schema version the entry was written with, the current one if unset
+func (l LogEntry) schemaVersion() string {
+    if l.SchemaVersion != "" {
+        return l.SchemaVersion
+    }
+    return logSchemaVersion
+}
+
+// Marshal the entry as JSON, indenting nested levels with indent; an empty
+// indent produces compact output. Entries without a schema version get the
+// current one.
+func (l LogEntry) Marshal(indent string) ([]byte, error) {
+    l.SchemaVersion = l.schemaVersion()
+    if indent == "" {
+        return json.Marshal(l)
+    }
+    return json.MarshalIndent(l, "", indent)
+}
+
+// Marshal inside a child span recording the output size and how long
+// marshalling took
+func (l LogEntry) marshalTraced(ctx context.Context, tracer trace.Tracer, indent string) ([]byte, error) {
+    _, span := tracer.Start(ctx, "marshal-log-entry")
+    defer span.End()
+
+    start := time.Now()
+    data, err := l.Marshal(indent)
+    span.SetAttributes(
+        attribute.Int("json.size_bytes", len(data)),
+        attribute.Float64("json.marshal_duration_ms", float64(time.Since(start).Microseconds())/1000),
+    )
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    return data, err
+}
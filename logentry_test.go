@@ -0,0 +1,184 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLogEntryMarshalIndent(t *testing.T) {
+    l := LogEntry{Body: "hello", Attributes: map[string]string{"http.method": "GET"}}
+
+    compact, err := l.Marshal("")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if bytes.ContainsAny(compact, "\n\t") {
+        t.Errorf("compact output has line breaks or tabs: %s", compact)
+    }
+
+    tabbed, err := l.Marshal("\t")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(string(tabbed), "\n\t\"Body\": \"hello\"") || !strings.Contains(string(tabbed), "\n\t\t\"http.method\": \"GET\"") {
+        t.Errorf("tab-indented output:\n%s", tabbed)
+    }
+
+    var fromCompact, fromTabbed map[string]any
+    if err := json.Unmarshal(compact, &fromCompact); err != nil {
+        t.Fatal(err)
+    }
+    if err := json.Unmarshal(tabbed, &fromTabbed); err != nil {
+        t.Fatal(err)
+    }
+    if len(fromCompact) != len(fromTabbed) || fromCompact["Body"] != fromTabbed["Body"] {
+        t.Error("compact and indented output differ in content")
+    }
+}
+
+func TestMarshalTracedRecordsSize(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    tracer := trace.NewTracerProvider(trace.WithSpanProcessor(recorder)).Tracer("test")
+    ctx, parent := tracer.Start(context.Background(), "entry")
+
+    data, err := LogEntry{Body: "hello"}.marshalTraced(ctx, tracer, "")
+    parent.End()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    var span trace.ReadOnlySpan
+    for _, s := range recorder.Ended() {
+        if s.Name() == "marshal-log-entry" {
+            span = s
+        }
+    }
+    if span == nil {
+        t.Fatal("no marshal-log-entry span")
+    }
+    if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+        t.Error("marshal span is not a child of the entry span")
+    }
+    AssertSpanAttribute(t, span, "json.size_bytes", len(data))
+    for _, kv := range span.Attributes() {
+        if kv.Key == "json.marshal_duration_ms" && kv.Value.AsFloat64() < 0 {
+            t.Errorf("negative marshal duration %v", kv.Value.AsFloat64())
+        }
+    }
+}
+
+func TestLogEntryValidate(t *testing.T) {
+    tests := []struct {
+        name    string
+        entry   LogEntry
+        wantErr []string
+    }{
+        {"empty", LogEntry{}, nil},
+        {"valid IPv4 and MAC", LogEntry{IPAddress: "10.0.0.5", MacAddress: "02:42:ac:11:00:02"}, nil},
+        {"valid IPv6", LogEntry{IPAddress: "2001:db8::1", MacAddress: "02-42-AC-11-00-02"}, nil},
+        {"bad IP", LogEntry{IPAddress: "10.0.0.256"}, []string{`host.ip "10.0.0.256"`}},
+        {"bad MAC", LogEntry{MacAddress: "02:42:ac:11:00"}, []string{`host.mac "02:42:ac:11:00"`}},
+        {"both bad", LogEntry{IPAddress: "localhost", MacAddress: "nope", ParentSpanID: "xyz"},
+            []string{`host.ip "localhost"`, `host.mac "nope"`, `parent_span_id "xyz"`}},
+    }
+    for _, tt := range tests {
+        err := tt.entry.Validate()
+        if (err != nil) != (len(tt.wantErr) > 0) {
+            t.Errorf("%s: Validate() = %v", tt.name, err)
+            continue
+        }
+        for _, want := range tt.wantErr {
+            if !strings.Contains(err.Error(), want) {
+                t.Errorf("%s: error %q does not mention %s", tt.name, err, want)
+            }
+        }
+    }
+}
+
+func TestInTimezoneAcrossDSTBoundary(t *testing.T) {
+    loc, err := time.LoadLocation("America/New_York")
+    if err != nil {
+        t.Skipf("time zone data unavailable: %v", err)
+    }
+    saved := timestampLayout
+    timestampLayout = time.RFC3339Nano
+    defer func() { timestampLayout = saved }()
+
+    // Clocks in New York went forward at 2024-03-10 07:00 UTC
+    l := LogEntry{
+        Timestamp:         "2024-03-10T06:30:00Z",
+        EndTimestamp:      "2024-03-10T07:30:00Z",
+        ObservedTimestamp: "not a timestamp",
+    }
+    got := l.InTimezone(loc)
+    if got.Timestamp != "2024-03-10T01:30:00-05:00" {
+        t.Errorf("Timestamp before the change = %q", got.Timestamp)
+    }
+    if got.EndTimestamp != "2024-03-10T03:30:00-04:00" {
+        t.Errorf("EndTimestamp after the change = %q", got.EndTimestamp)
+    }
+    if got.ObservedTimestamp != l.ObservedTimestamp {
+        t.Errorf("unparseable timestamp rewritten as %q", got.ObservedTimestamp)
+    }
+    if l.Timestamp != "2024-03-10T06:30:00Z" {
+        t.Error("InTimezone modified the original entry")
+    }
+
+    // The instants are unchanged, only their rendering
+    start, _ := parseTimestamp(got.Timestamp)
+    end, _ := parseTimestamp(got.EndTimestamp)
+    if d := end.Sub(start); d != time.Hour {
+        t.Errorf("converted timestamps are %s apart, want 1h", d)
+    }
+}
+
+func TestSchemaVersionReflectsConstant(t *testing.T) {
+    span := recordEntrySpan(t, LogEntry{Body: "hello"}, config{})
+    AssertSpanAttribute(t, span, "log.schema.version", logSchemaVersion)
+
+    data, err := LogEntry{Body: "hello"}.Marshal("")
+    if err != nil {
+        t.Fatal(err)
+    }
+    var out map[string]any
+    if err := json.Unmarshal(data, &out); err != nil {
+        t.Fatal(err)
+    }
+    if out["log.schema.version"] != logSchemaVersion {
+        t.Errorf("marshalled log.schema.version = %v, want %s", out["log.schema.version"], logSchemaVersion)
+    }
+}
+
+func TestDroppedResourceKeysAbsentFromEntryJSON(t *testing.T) {
+    entry := exampleLogEntry(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "web-1", "10.0.0.1", "02:42:ac:11:00:02")
+    data, err := entry.withoutResourceKeys([]string{"host.mac", "host.ip"}).Marshal("")
+    if err != nil {
+        t.Fatal(err)
+    }
+    var fields map[string]any
+    if err := json.Unmarshal(data, &fields); err != nil {
+        t.Fatal(err)
+    }
+    resource, _ := fields["Resource"].(map[string]any)
+    for _, key := range []string{"host.mac", "host.ip"} {
+        if _, ok := fields[key]; ok {
+            t.Errorf("%s still marshalled at the top level: %s", key, data)
+        }
+        if _, ok := resource[key]; ok {
+            t.Errorf("%s still marshalled in Resource: %s", key, data)
+        }
+    }
+    if fields["host.name"] != "web-1" || resource["host.name"] != "web-1" {
+        t.Errorf("kept host.name missing: %s", data)
+    }
+    if entry.Resource["host.mac"] == "" || entry.MacAddress == "" {
+        t.Error("the original entry was modified")
+    }
+}
@@ -2,24 +2,28 @@ package main
 
 import (
     "context"
-    "encoding/json"
+    "flag"
+    "io"
     "log"
     "net"
     "os"
+    "strconv"
     "time"
 
     "go.opentelemetry.io/otel"
-    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
     "go.opentelemetry.io/otel/sdk/resource"
     "go.opentelemetry.io/otel/sdk/trace"
     "go.opentelemetry.io/otel/attribute"
+    oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type LogEntry struct {
     Timestamp           string              `json:"Timestamp"`
     ObservedTimestamp   string              `json:"ObservedTimestamp"`
+    EndTimestamp        string              `json:"EndTimestamp,omitempty"`
     TraceID             string              `json:"TraceId"`
     SpanID              string              `json:"SpanId"`
+    ParentSpanID        string              `json:"parent_span_id,omitempty"`
     SeverityText        string              `json:"SeverityText"`
     SeverityNumber      string              `json:"SeverityNumber"`
     Body                string              `json:"Body"`
@@ -31,105 +35,109 @@ type LogEntry struct {
     Duration            string              `json:"Duration"`
     Status              string              `json:"Status"`
     LogLevel            string              `json:"LogLevel"`
-    Hostname            string              `json:"host.name"`
-    IPAddress           string              `json:"host.ip"`
-    MacAddress          string              `json:"host.mac"`
+    Hostname            string              `json:"host.name,omitempty"`
+    IPAddress           string              `json:"host.ip,omitempty"`
+    MacAddress          string              `json:"host.mac,omitempty"`
+    SchemaVersion       string              `json:"log.schema.version"`
 }
 
-// Get system info (hostname, IP, MAC)
-func getSystemInfo() (string, string, string) {
+// Get system info (hostname, IP, MAC), preferring the named interface when set
+func getSystemInfo(preferredInterface string) (string, string, string) {
     hostname, _ := os.Hostname()
 
     // Get IP and MAC address
     interfaces, err := net.Interfaces()
     if err != nil {
-        log.Fatal(err)
+        log.Printf("Could not list network interfaces: %v", err)
+        return hostname, "", ""
     }
 
-    var ipAddress, macAddress string
-    for _, iface := range interfaces {
-        addrs, err := iface.Addrs()
-        if err != nil {
-            continue
-        }
+    selected, ipAddress, macAddress := selectInterface(interfaces, preferredInterface)
+    if preferredInterface != "" && selected != preferredInterface {
+        log.Printf("Preferred interface %q not found or has no IPv4 address, auto-selecting", preferredInterface)
+    }
 
-        for _, addr := range addrs {
-            ipNet, ok := addr.(*net.IPNet)
-            if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
-                ipAddress = ipNet.IP.String()
-                macAddress = iface.HardwareAddr.String()
-                break
+    return hostname, ipAddress, macAddress
+}
+
+// Pick the interface providing host.ip and host.mac, returning its name.
+// The preferred interface wins when it has an IPv4 address; otherwise the
+// first interface with both an IPv4 and a MAC address is used.
+func selectInterface(interfaces []net.Interface, preferredInterface string) (string, string, string) {
+    if preferredInterface != "" {
+        for _, iface := range interfaces {
+            if iface.Name != preferredInterface {
+                continue
+            }
+            if ipAddress := interfaceIPv4(iface); ipAddress != "" {
+                return iface.Name, ipAddress, iface.HardwareAddr.String()
             }
         }
+    }
+
+    var name, ipAddress, macAddress string
+    for _, iface := range interfaces {
+        if ip := interfaceIPv4(iface); ip != "" {
+            name = iface.Name
+            ipAddress = ip
+            macAddress = iface.HardwareAddr.String()
+        }
         if ipAddress != "" && macAddress != "" {
             break
         }
     }
-
-    return hostname, ipAddress, macAddress
+    return name, ipAddress, macAddress
 }
 
-func main() {
-    // Set up OpenTelemetry exporter
-    exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    // Get system information
-    hostname, ipAddress, macAddress := getSystemInfo()
+// Addresses of an interface, replaced in tests with fake interfaces
+var interfaceAddrs = func(iface net.Interface) ([]net.Addr, error) {
+    return iface.Addrs()
+}
 
-    // Set up Resource with Attributes
-    res, err := resource.New(
-        context.Background(),
-        resource.WithAttributes(
-            attribute.String("service.name", "web-backend"),
-            attribute.String("host.name", hostname),
-            attribute.String("host.ip", ipAddress),
-            attribute.String("host.mac", macAddress),
-        ),
-    )
+// First non-loopback IPv4 address of an interface, or "" if it has none
+func interfaceIPv4(iface net.Interface) string {
+    addrs, err := interfaceAddrs(iface)
     if err != nil {
-        log.Fatal(err)
+        return ""
     }
 
-    // Set up Trace Provider
-    tracerProvider := trace.NewTracerProvider(
-        trace.WithBatcher(exporter),
-        trace.WithResource(res),
-    )
-    defer func() {
-        if err := tracerProvider.Shutdown(context.Background()); err != nil {
-            log.Fatal(err)
+    for _, addr := range addrs {
+        ipNet, ok := addr.(*net.IPNet)
+        if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+            return ipNet.IP.String()
         }
-    }()
-
-    // Set the global trace provider
-    otel.SetTracerProvider(tracerProvider)
+    }
+    return ""
+}
 
-    // Use the tracer (example usage)
-    tracer := otel.Tracer("example-tracer")
-    _, span := tracer.Start(context.Background(), "example-span")
-    defer span.End()
+// Set log.sampled on the entry from the sampling decision of its span
+func markEntrySampled(l *LogEntry, sc oteltrace.SpanContext) {
+    if l.Attributes == nil {
+        l.Attributes = map[string]string{}
+    }
+    l.Attributes["log.sampled"] = strconv.FormatBool(sc.IsSampled())
+}
 
-    // Example Log Entry
-    logEntry := LogEntry{
-        Timestamp:         time.Now().Format(time.RFC3339),
-        ObservedTimestamp: time.Now().Add(100 * time.Millisecond).Format(time.RFC3339),
+// The example entry recorded by main, observed at now on the given host
+func exampleLogEntry(now time.Time, hostname, ipAddress, macAddress string) LogEntry {
+    return LogEntry{
+        Timestamp:         formatTimestamp(now),
+        ObservedTimestamp: formatTimestamp(now.Add(100 * time.Millisecond)),
         TraceID:           "abcd1234",
         SpanID:            "efgh5678",
         SeverityText:      "ERROR",
         SeverityNumber:    "17",
         Body:              "An error occurred while processing the request.",
         Resource: map[string]string{
-            "service.name": "web-backend",
+            "service.name": defaultServiceName,
             "host.name":    hostname,
             "host.ip":      ipAddress,
             "host.mac":     macAddress,
         },
         InstrumentationScope: map[string]string{
-            "Name":    "GoLogger",
-            "Version": "1.0.0",
+            "Name":      "GoLogger",
+            "Version":   "1.0.0",
+            "SchemaURL": "https://opentelemetry.io/schemas/1.24.0",
         },
         Attributes: map[string]string{
             "http.method":      "GET",
@@ -153,14 +161,408 @@ func main() {
         IPAddress: ipAddress,
         MacAddress: macAddress,
     }
+}
+
+func main() {
+    printConfigFlag := flag.Bool("print-config", false, "print the effective configuration as JSON and exit")
+    verboseFlag := flag.Bool("verbose", false, "trace host interface enumeration with a span per interface")
+    replayFlag := flag.String("replay", "", "re-export the spans of a protofile exporter file and exit")
+    cardinalityFlag := flag.String("cardinality", "", "print the distinct values per attribute key across a file of JSON log entries, one per line, and exit")
+    reportFlag := flag.String("report", "", "print a report (stats, dot or deps) of the protofile exporter file given as argument and exit")
+    diffFlag := flag.Bool("diff", false, "compare the spans of the two protofile exporter files given as arguments and exit, with status 1 if they differ")
+    configFlag := flag.String("config", "", "read settings from a JSON, YAML or TOML file (by extension), keyed by environment variable name; set environment variables take precedence")
+    flag.Parse()
+
+    // Offline analysis of span files, which needs no configuration
+    if *reportFlag != "" {
+        if flag.NArg() != 1 {
+            log.Fatal("-report needs a span file")
+        }
+        if err := writeSpanReport(os.Stdout, *reportFlag, flag.Arg(0)); err != nil {
+            log.Fatal(err)
+        }
+        return
+    }
+    if *diffFlag {
+        if flag.NArg() != 2 {
+            log.Fatal("-diff needs two span files")
+        }
+        differ, err := diffSpanFiles(os.Stdout, flag.Arg(0), flag.Arg(1))
+        if err != nil {
+            log.Fatal(err)
+        }
+        if differ {
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *configFlag != "" {
+        if err := applyConfigFile(*configFlag); err != nil {
+            log.Fatal(err)
+        }
+    }
+    cfg := loadConfig()
+    timestampLayout = cfg.TimestampPrecision.layout()
+    logFieldMapping = cfg.FieldMapping
+    clockOffset = cfg.ClockOffset
+    maxExceptionChain = cfg.MaxExceptionChain
+
+    // Report attribute cardinality of ingested entries instead of exporting
+    if *cardinalityFlag != "" {
+        if err := writeCardinalityReport(os.Stdout, *cardinalityFlag); err != nil {
+            log.Fatal(err)
+        }
+        return
+    }
+
+    // Mask trace and span IDs in console output when requested
+    var stdout io.Writer = os.Stdout
+    var aliases *idAliaser
+    if cfg.MaskIDs {
+        aliases = newIDAliaser()
+        stdout = aliases.Writer(os.Stdout)
+    }
+
+    // Get system information
+    hostname, ipAddress, macAddress := getSystemInfo(cfg.HostInterface)
+    if len(cfg.HostnameSources) > 0 {
+        hostname = resolveHostname(cfg.HostnameSources)
+    }
+
+    // Set up Resource with Attributes
+    detectedAttributes := append(
+        []attribute.KeyValue{attribute.String("service.name", defaultServiceName)},
+        hostAttributes(hostname, ipAddress, macAddress)...,
+    )
+    if cfg.HostnameTemplate != nil {
+        labels, err := cfg.HostnameTemplate.labels(hostname)
+        if err != nil {
+            log.Printf("Skipping hostname labels: %v", err)
+        }
+        detectedAttributes = append(detectedAttributes, labels...)
+    }
+    // OTEL_RESOURCE_ATTRIBUTES values may reference ${ENV:VAR_NAME}, so a
+    // generic setting can pick up per-deployment variables
+    resourceAttributes := mergeResourceAttributes(
+        detectedAttributes,
+        resolveEnvTemplates(resource.Environment().Attributes()),
+    )
+    resourceAttributes = limitResourceAttributes(resourceAttributes, cfg.ResourceValueLimit)
+
+    // Print the effective configuration and exit without starting tracing
+    if *printConfigFlag {
+        if err := printConfig(os.Stdout, cfg, resourceAttributes); err != nil {
+            log.Fatal(err)
+        }
+        return
+    }
+
+    // Preset detectors run first so explicitly set attributes override theirs
+    resourceOptions, err := presetDetectors(cfg.ResourcePreset)
+    if err != nil {
+        log.Fatal(err)
+    }
+    resourceOptions = append(resourceOptions,
+        resource.WithAttributes(resourceAttributes...),
+        resource.WithAttributes(sdkResourceAttributes()...),
+    )
+    res, err := detectResourceWithRetry(context.Background(), cfg.ResourceRetries, cfg.ResourceBackoff, func(ctx context.Context) (*resource.Resource, error) {
+        return resource.New(ctx, resourceOptions...)
+    })
+    res = detectedOrFallback(res, err)
+    res = dropResourceKeys(res, cfg.ResourceDropKeys)
+    if cfg.ValidateResource {
+        for _, warning := range validateResourceConventions(res) {
+            log.Printf("Warning: %s", warning)
+        }
+    }
+
+    // Set up the global meter provider for log record and pipeline metrics.
+    // Deferred first, it shuts down after the tracer provider so the final
+    // exports are still measured.
+    if cfg.LogRecordMetrics || cfg.PipelineMetrics {
+        meterProvider, err := newMeterProvider(stdout, res)
+        if err != nil {
+            log.Fatal(err)
+        }
+        defer func() {
+            if err := meterProvider.Shutdown(context.Background()); err != nil {
+                log.Fatal(err)
+            }
+        }()
+        otel.SetMeterProvider(meterProvider)
+    }
+
+    if cfg.CheckEndpoints {
+        for _, err := range checkExporterEndpoints(context.Background(), cfg) {
+            log.Printf("Warning: %v", err)
+        }
+    }
+
+    // Set up OpenTelemetry exporter
+    exporter, err := newExporter(cfg, stdout)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if cfg.ExporterWarmup {
+        if took, err := warmupExporter(context.Background(), exporter, res, cfg.WarmupTimeout); err != nil {
+            log.Printf("Warning: %v", err)
+        } else {
+            log.Printf("Exporter warmed up in %s", took)
+        }
+    }
+    if cfg.RedactIDs {
+        exporter = newRedactIDsExporter(exporter)
+    }
+    if cfg.ExportBatchSpans {
+        exporter = newBatchSpanExporter(exporter, res, cfg.TracesExporter)
+    }
+    if cfg.ExportSequence {
+        exporter = newSequenceExporter(exporter)
+    }
+    if cfg.DownsampleWindow > 0 {
+        exporter = newDownsampleExporter(exporter, cfg.DownsampleWindow, cfg.DownsampleKeys)
+    }
+    if cfg.StatsDAddress != "" {
+        exporter, err = newStatsDExporter(exporter, cfg.StatsDAddress, cfg.StatsDPrefix, cfg.StatsDNameKeys, cfg.StatsDSpanNames)
+        if err != nil {
+            log.Fatal(err)
+        }
+    }
+    if cfg.BreakerFailures > 0 {
+        breaker := newCircuitBreakerExporter(exporter, cfg.BreakerFailures, cfg.BreakerCooldown)
+        if cfg.PipelineMetrics {
+            if err := observeCircuitState(otel.Meter("export-pipeline"), breaker.State); err != nil {
+                log.Fatal(err)
+            }
+        }
+        exporter = breaker
+    }
+    if cfg.ExportConcurrency > 0 {
+        exporter = newConcurrencyLimitedExporter(exporter, cfg.ExportConcurrency)
+    }
+
+    // Count spans for the session summary emitted at shutdown
+    var session *sessionStats
+    if cfg.SessionSummary {
+        session = newSessionStats()
+        exporter = session.Exporter(exporter)
+    }
+
+    // Replay spans left queued by a crashed run, then log new ones until exported
+    var wal *spanWAL
+    if cfg.SpanWALPath != "" {
+        wal, err = openSpanWAL(cfg.SpanWALPath)
+        if err != nil {
+            log.Fatal(err)
+        }
+        replayed, err := wal.Replay(context.Background(), exporter)
+        if err != nil {
+            log.Printf("Could not fully replay span WAL: %v", err)
+        }
+        if replayed > 0 {
+            log.Printf("Replayed %d spans from %s", replayed, cfg.SpanWALPath)
+        }
+        exporter = wal.Exporter(exporter)
+    }
+    if cfg.PipelineMetrics {
+        exporter, err = newPipelineMetricsExporter(exporter, otel.Meter("export-pipeline"))
+        if err != nil {
+            log.Fatal(err)
+        }
+    }
+
+    // Backfill from a persisted span file instead of recording the example entry
+    if *replayFlag != "" {
+        err := ReplayFile(context.Background(), *replayFlag, exporter)
+        if shutdownErr := exporter.Shutdown(context.Background()); err == nil {
+            err = shutdownErr
+        }
+        if err != nil {
+            log.Fatal(err)
+        }
+        return
+    }
+
+    // Set up the sampler, whose ratio can later be changed with SetSamplingRatio
+    if err := SetSamplingRatio(cfg.SamplingRatio); err != nil {
+        log.Fatal(err)
+    }
+    var sampler trace.Sampler = activeSampler
+    sampledRatio := activeSampler.Ratio
+    if cfg.SamplingTargetRate > 0 {
+        adaptive := newAdaptiveRateSampler(cfg.SamplingTargetRate, cfg.SamplingInterval, activeSampler)
+        sampler, sampledRatio = adaptive, adaptive.Ratio
+    }
+    if cfg.SampleByRequestID {
+        sampler = newRequestIDSampler(activeSampler.Ratio, sampler)
+    }
+
+    // Set up the span processor strategy (batch, sync or microbatch), with
+    // optional flushes aligned to the wall clock
+    processor, err := newSpanProcessor(cfg, exporter)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if queue, ok := processor.(interface{ Len() int }); ok && cfg.PipelineMetrics {
+        if err := observeQueueLength(otel.Meter("export-pipeline"), queue.Len); err != nil {
+            log.Fatal(err)
+        }
+    }
+    if cfg.FlushAlignInterval > 0 {
+        processor = newAlignedFlushProcessor(processor, cfg.FlushAlignInterval)
+    }
+    if wal != nil {
+        processor = wal.Processor(processor)
+    }
+    processor, err = chainSpanProcessors(cfg, processor, cfg.ProcessorOrder)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Route SDK-internal errors through our logger
+    otel.SetErrorHandler(sdkErrorHandler(log.Default()))
+
+    // Set up Trace Provider. The only remote parents are those declared by
+    // entries, which carry no sampling decision, so the sampler decides for
+    // them rather than ParentBased dropping them as unsampled.
+    var rootSampler trace.Sampler = trace.ParentBased(sampler, trace.WithRemoteParentNotSampled(sampler))
+    if cfg.SamplingAdjusted {
+        rootSampler = newAdjustedCountSampler(rootSampler, sampledRatio)
+    }
+    if cfg.SamplingPriority {
+        rootSampler = newPrioritySampler(rootSampler)
+    }
+    if cfg.WarnDroppedErrors {
+        rootSampler = recordDroppedSampler{next: rootSampler}
+    }
+    providerOptions := []trace.TracerProviderOption{
+        trace.WithSampler(rootSampler),
+        trace.WithResource(res),
+    }
+    var idGenerator trace.IDGenerator
+    if cfg.TracesExporter == "xray" {
+        idGenerator = xrayIDGenerator{}
+    }
+    if cfg.TraceIDFromRequest {
+        idGenerator = newRequestIDGenerator(idGenerator)
+    }
+    if idGenerator != nil {
+        providerOptions = append(providerOptions, trace.WithIDGenerator(idGenerator))
+    }
+    if len(cfg.InheritedKeys) > 0 {
+        providerOptions = append(providerOptions, trace.WithSpanProcessor(newInheritAttributesProcessor(cfg.InheritedKeys)))
+    }
+    if cfg.TraceShape {
+        providerOptions = append(providerOptions, trace.WithSpanProcessor(newTraceShapeProcessor()))
+    }
+    if session != nil {
+        providerOptions = append(providerOptions, trace.WithSpanProcessor(session))
+    }
+    if cfg.WarnDroppedErrors {
+        providerOptions = append(providerOptions, trace.WithSpanProcessor(newDroppedErrorProcessor(droppedErrorWarnInterval)))
+    }
+    providerOptions = append(providerOptions, trace.WithSpanProcessor(processor))
+    tracerProvider := trace.NewTracerProvider(providerOptions...)
+    defer func() {
+        if session != nil {
+            session.emitSummary(context.Background(), tracerProvider, exporter, res)
+        }
+        if err := tracerProvider.Shutdown(context.Background()); err != nil {
+            log.Fatal(err)
+        }
+    }()
+
+    // Set the global trace provider
+    otel.SetTracerProvider(tracerProvider)
+
+    if *verboseFlag {
+        traceSystemInfo(context.Background(), otel.Tracer("system-info"), cfg.HostInterface)
+    }
+
+    // Example Log Entry
+    logEntry := exampleLogEntry(time.Now(), hostname, ipAddress, macAddress)
+    // DROP_RESOURCE_KEYS also applies to the entry's own copy of the resource,
+    // so dropped keys are never printed
+    logEntry = logEntry.withoutResourceKeys(cfg.ResourceDropKeys)
+
+    if err := computeDuration(&logEntry); err != nil {
+        log.Printf("Could not compute log entry duration: %v", err)
+    }
+    if err := logEntry.Validate(); err != nil {
+        log.Printf("Invalid log entry: %v", err)
+    }
+
+    // Count the ingested entry by severity, status and LOG_METRIC_LABELS
+    if cfg.LogRecordMetrics {
+        counter, err := newLogRecordCounter(otel.Meter("log-ingestion"), cfg.LogMetricLabels)
+        if err != nil {
+            log.Fatal(err)
+        }
+        counter.Record(context.Background(), logEntry)
+    }
+
+    // Skip entries matched by the drop rules before any span is created
+    filter := newEntryFilter(cfg.DropRules)
+    if !filter.Allow(logEntry) {
+        log.Printf("Log entry matched DROP_ENTRY_RULES, %d dropped", filter.Dropped())
+        return
+    }
+
+    // Attach geographic attributes from host.ip when a GeoIP database is set
+    if cfg.GeoIPDatabase != "" {
+        db, err := loadGeoIPDatabase(cfg.GeoIPDatabase)
+        if err != nil {
+            log.Fatal(err)
+        }
+        RegisterEnricher(geoIPEnricher(db))
+    }
+
+    // Use the tracer for the entry's instrumentation scope to record it as a span
+    tracer, err := entryScopeTracer(logEntry)
+    if err != nil {
+        log.Printf("Invalid instrumentation scope, using default tracer: %v", err)
+        tracer = otel.Tracer("example-tracer")
+    }
+    ctx := context.Background()
+    if requestID := logEntry.Attributes["request.id"]; requestID != "" {
+        ctx = contextWithRequestID(ctx, requestID)
+    }
+    if ctx, err = contextWithEntryParent(ctx, logEntry); err != nil {
+        log.Printf("Ignoring parent_span_id: %v", err)
+    }
+    ctx, span := startEntrySpan(ctx, tracer, logEntry, cfg)
+    defer endEntrySpan(span, logEntry, cfg)
+
+    // Record whether a trace will exist for this entry
+    markEntrySampled(&logEntry, span.SpanContext())
+
+    // Carry the span context along with the re-emitted entry, unless the
+    // exported IDs are redacted and the real ones must not leak here
+    if !cfg.RedactIDs {
+        injectContextIntoEntry(ctx, &logEntry)
+    }
 
     // Convert log entry to JSON and print it
-    logEntryJSON, err := json.MarshalIndent(logEntry, "", "  ")
+    if cfg.Timezone != nil {
+        logEntry = logEntry.InTimezone(cfg.Timezone)
+    }
+    var logEntryJSON []byte
+    if cfg.TraceMarshalling {
+        logEntryJSON, err = logEntry.marshalTraced(ctx, tracer, cfg.LogEntryIndent)
+    } else {
+        logEntryJSON, err = logEntry.Marshal(cfg.LogEntryIndent)
+    }
     if err != nil {
         log.Fatal(err)
     }
+    output := string(logEntryJSON)
+    if aliases != nil {
+        output = aliases.Mask(output)
+    }
     log.Println("Log Entry in JSON format:")
-    log.Println(string(logEntryJSON))
+    log.Println(output)
 
     // Your application logic here
     log.Println("OpenTelemetry is set up and running!")
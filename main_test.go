@@ -0,0 +1,79 @@
+package main
+
+import (
+    "context"
+    "net"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestMarkEntrySampled(t *testing.T) {
+    tests := []struct {
+        name    string
+        sampler trace.Sampler
+        want    string
+    }{
+        {"sampled", trace.AlwaysSample(), "true"},
+        {"unsampled", trace.NeverSample(), "false"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tracer := trace.NewTracerProvider(trace.WithSampler(tt.sampler)).Tracer("test")
+            _, span := tracer.Start(context.Background(), "entry")
+            defer span.End()
+
+            l := LogEntry{Body: "hello"}
+            markEntrySampled(&l, span.SpanContext())
+            if got := l.Attributes["log.sampled"]; got != tt.want {
+                t.Errorf("log.sampled = %q, want %q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSelectInterfacePreference(t *testing.T) {
+    addrs := map[string][]net.Addr{
+        "lo":   {&net.IPNet{IP: net.IPv4(127, 0, 0, 1), Mask: net.CIDRMask(8, 32)}},
+        "eth0": {&net.IPNet{IP: net.IPv4(10, 0, 0, 5), Mask: net.CIDRMask(24, 32)}},
+        "eth1": {&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)}, &net.IPNet{IP: net.IPv4(192, 168, 1, 9), Mask: net.CIDRMask(24, 32)}},
+        "tun0": {&net.IPNet{IP: net.IPv4(172, 16, 0, 2), Mask: net.CIDRMask(32, 32)}},
+        "eth2": nil,
+    }
+    saved := interfaceAddrs
+    interfaceAddrs = func(iface net.Interface) ([]net.Addr, error) { return addrs[iface.Name], nil }
+    defer func() { interfaceAddrs = saved }()
+
+    mac := func(s string) net.HardwareAddr {
+        hw, err := net.ParseMAC(s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return hw
+    }
+    interfaces := []net.Interface{
+        {Name: "lo"},
+        {Name: "tun0"},
+        {Name: "eth0", HardwareAddr: mac("02:00:00:00:00:01")},
+        {Name: "eth1", HardwareAddr: mac("02:00:00:00:00:02")},
+        {Name: "eth2", HardwareAddr: mac("02:00:00:00:00:03")},
+    }
+    tests := []struct {
+        preferred string
+        name, ip  string
+        mac       string
+    }{
+        {"", "eth0", "10.0.0.5", "02:00:00:00:00:01"},
+        {"eth1", "eth1", "192.168.1.9", "02:00:00:00:00:02"},
+        {"tun0", "tun0", "172.16.0.2", ""},
+        // No IPv4 address or no such interface: fall back to the default pick
+        {"eth2", "eth0", "10.0.0.5", "02:00:00:00:00:01"},
+        {"wlan0", "eth0", "10.0.0.5", "02:00:00:00:00:01"},
+    }
+    for _, tt := range tests {
+        name, ip, mac := selectInterface(interfaces, tt.preferred)
+        if name != tt.name || ip != tt.ip || mac != tt.mac {
+            t.Errorf("selectInterface(%q) = %s, %s, %s, want %s, %s, %s", tt.preferred, name, ip, mac, tt.name, tt.ip, tt.mac)
+        }
+    }
+}
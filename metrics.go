@@ -0,0 +1,116 @@
+package main
+
+import (
+    "context"
+    "io"
+    "log"
+    "regexp"
+    "sync"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+    "go.opentelemetry.io/otel/metric"
+    sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Meter provider writing metrics as JSON to w, collected on shutdown
+func newMeterProvider(w io.Writer, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+    exporter, err := stdoutmetric.New(stdoutmetric.WithWriter(w), stdoutmetric.WithPrettyPrint())
+    if err != nil {
+        return nil, err
+    }
+    return sdkmetric.NewMeterProvider(
+        sdkmetric.WithResource(res),
+        sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+    ), nil
+}
+
+// log.records.count counter of ingested entries by severity and status, plus
+// the MetricLabels of labelKeys
+type logRecordCounter struct {
+    count     metric.Int64Counter
+    labelKeys []string
+}
+
+func newLogRecordCounter(meter metric.Meter, labelKeys []string) (*logRecordCounter, error) {
+    count, err := meter.Int64Counter("log.records.count",
+        metric.WithDescription("Log entries ingested, by severity and status"),
+        metric.WithUnit("{record}"))
+    if err != nil {
+        return nil, err
+    }
+    return &logRecordCounter{count: count, labelKeys: labelKeys}, nil
+}
+
+func (c *logRecordCounter) Record(ctx context.Context, l LogEntry) {
+    attrs := append([]attribute.KeyValue{
+        attribute.String("log.severity", l.SeverityText),
+        attribute.String("log.status", l.Status),
+    }, l.MetricLabels(c.labelKeys)...)
+    c.count.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// Attribute keys whose values are unique per request or user and so would
+// blow up metric cardinality
+var highCardinalityKeys = map[string]bool{
+    "request.id":           true,
+    "user.id":              true,
+    "session.id":           true,
+    "trace_id":             true,
+    "span_id":              true,
+    "url.full":             true,
+    "http.url":             true,
+    "http.target":          true,
+    "client.address":       true,
+    "net.peer.ip":          true,
+    "exception.stacktrace": true,
+}
+
+// Integers this long are taken for IDs (order, account or user numbers)
+// rather than codes such as an HTTP status
+var numericIDPattern = regexp.MustCompile(`^\d{6,}$`)
+
+// Keys already warned about by MetricLabels, so each is reported once
+var warnedLabelKeys sync.Map
+
+// Requested attributes as metric labels, in the order of keys. Keys that are
+// missing are skipped; known high-cardinality keys and values that look like
+// IDs or addresses (UUID, IP, hex, long integers) are dropped with a warning.
+func (l LogEntry) MetricLabels(keys []string) []attribute.KeyValue {
+    labels := make([]attribute.KeyValue, 0, len(keys))
+    for _, k := range keys {
+        v, ok := l.Attributes[k]
+        if !ok {
+            continue
+        }
+        if reason := highCardinalityReason(k, v); reason != "" {
+            if _, warned := warnedLabelKeys.LoadOrStore(k, true); !warned {
+                log.Printf("Warning: not using attribute %q as a metric label: %s", k, reason)
+            }
+            continue
+        }
+        labels = append(labels, attribute.String(k, v))
+    }
+    return labels
+}
+
+// Why a key/value pair would make a high-cardinality label, empty if not
+func highCardinalityReason(key, value string) string {
+    if highCardinalityKeys[key] {
+        return "key is high-cardinality"
+    }
+    if numericIDPattern.MatchString(value) {
+        return "value looks like a numeric ID"
+    }
+    for _, p := range defaultBodyTokenPatterns {
+        if p.Name == "NUM" {
+            // Plain numbers are often codes (e.g. HTTP status) and fine as labels
+            continue
+        }
+        if loc := p.Pattern.FindStringIndex(value); loc != nil && loc[0] == 0 && loc[1] == len(value) {
+            return "value looks like a " + p.Name
+        }
+    }
+    return ""
+}
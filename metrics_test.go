@@ -0,0 +1,96 @@
+package main
+
+import (
+    "context"
+    "reflect"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/metric"
+    "go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetricLabelsSubset(t *testing.T) {
+    l := LogEntry{Attributes: map[string]string{
+        "http.method":      "GET",
+        "http.status_code": "200",
+        "order.number":     "48213377",
+        "client.id":        "3f2b8c1e-9d4a-4e6b-8f1a-2c3d4e5f6a7b",
+        "user.id":          "alice",
+        "region":           "eu-west",
+    }}
+    labels := l.MetricLabels([]string{"region", "http.method", "http.status_code", "order.number", "client.id", "user.id", "missing"})
+    want := []attribute.KeyValue{
+        attribute.String("region", "eu-west"),
+        attribute.String("http.method", "GET"),
+        attribute.String("http.status_code", "200"),
+    }
+    if len(labels) != len(want) {
+        t.Fatalf("labels = %v, want %v", labels, want)
+    }
+    for i := range want {
+        if labels[i] != want[i] {
+            t.Errorf("label %d = %v, want %v", i, labels[i], want[i])
+        }
+    }
+}
+
+func TestLogRecordCounterUsesMetricLabels(t *testing.T) {
+    reader := metric.NewManualReader()
+    provider := metric.NewMeterProvider(metric.WithReader(reader))
+    counter, err := newLogRecordCounter(provider.Meter("test"), []string{"region"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    counter.Record(context.Background(), LogEntry{SeverityText: "INFO", Status: "ok", Attributes: map[string]string{"region": "eu-west"}})
+
+    var rm metricdata.ResourceMetrics
+    if err := reader.Collect(context.Background(), &rm); err != nil {
+        t.Fatal(err)
+    }
+    sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+    if !ok || len(sum.DataPoints) != 1 {
+        t.Fatalf("unexpected data: %#v", rm.ScopeMetrics[0].Metrics[0].Data)
+    }
+    if v, ok := sum.DataPoints[0].Attributes.Value("region"); !ok || v.AsString() != "eu-west" {
+        t.Errorf("attributes = %v, want region=eu-west", sum.DataPoints[0].Attributes)
+    }
+}
+
+func TestLogRecordCountsBySeverity(t *testing.T) {
+    reader := metric.NewManualReader()
+    provider := metric.NewMeterProvider(metric.WithReader(reader))
+    counter, err := newLogRecordCounter(provider.Meter("test"), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx := context.Background()
+    input := []LogEntry{
+        {SeverityText: "INFO", Status: "ok"},
+        {SeverityText: "INFO", Status: "ok"},
+        {SeverityText: "INFO", Status: "ok"},
+        {SeverityText: "WARN", Status: "ok"},
+        {SeverityText: "ERROR", Status: "failed"},
+        {SeverityText: "ERROR", Status: "failed"},
+        {SeverityText: "ERROR", Status: "ok"},
+    }
+    for _, l := range input {
+        counter.Record(ctx, l)
+    }
+
+    metrics := collectMetrics(t, reader)
+    sum, ok := metrics["log.records.count"].(metricdata.Sum[int64])
+    if !ok || !sum.IsMonotonic {
+        t.Fatalf("log.records.count = %#v, want a counter", metrics["log.records.count"])
+    }
+    got := map[string]int64{}
+    for _, dp := range sum.DataPoints {
+        severity, _ := dp.Attributes.Value("log.severity")
+        status, _ := dp.Attributes.Value("log.status")
+        got[severity.AsString()+"/"+status.AsString()] = dp.Value
+    }
+    want := map[string]int64{"INFO/ok": 3, "WARN/ok": 1, "ERROR/failed": 2, "ERROR/ok": 1}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("counts = %v, want %v", got, want)
+    }
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Emit a child span for each operation of a list-valued operation attribute
+// (e.g. db.operation="SELECT;UPDATE"), in list order. When the entry has a
+// Duration the children split it evenly one after another from start, the
+// entry span's start; otherwise they are timed on the (offset) wall clock.
+func recordEntryOperations(ctx context.Context, tracer trace.Tracer, l LogEntry, keys []string, start time.Time) {
+    d := entryDuration(l)
+    timed := d > 0
+    for _, key := range keys {
+        ops := splitOperations(l.Attributes[key])
+        if len(ops) < 2 {
+            continue
+        }
+        step := d / time.Duration(len(ops))
+        for i, op := range ops {
+            opts := []trace.SpanStartOption{trace.WithAttributes(
+                attribute.String(key, op),
+                attribute.Int("operation.index", i),
+            )}
+            if !timed {
+                _, child := tracer.Start(ctx, op, append(opts, trace.WithTimestamp(spanNow()))...)
+                child.End(trace.WithTimestamp(spanNow()))
+                continue
+            }
+            opStart := start.Add(time.Duration(i) * step)
+            _, child := tracer.Start(ctx, op, append(opts, trace.WithTimestamp(opStart))...)
+            child.End(trace.WithTimestamp(opStart.Add(step)))
+        }
+    }
+}
+
+// Operations of a list value: a JSON array of strings, or items separated by
+// ";" or ","
+func splitOperations(value string) []string {
+    value = strings.TrimSpace(value)
+    if strings.HasPrefix(value, "[") {
+        var items []string
+        if err := json.Unmarshal([]byte(value), &items); err == nil {
+            return nonEmpty(items)
+        }
+    }
+    return nonEmpty(strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }))
+}
+
+func nonEmpty(items []string) []string {
+    out := items[:0]
+    for _, item := range items {
+        if item = strings.TrimSpace(item); item != "" {
+            out = append(out, item)
+        }
+    }
+    return out
+}
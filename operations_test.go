@@ -0,0 +1,64 @@
+package main
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSplitOperations(t *testing.T) {
+    for _, tc := range []struct{ value, want string }{
+        {"SELECT;UPDATE", "SELECT|UPDATE"},
+        {" SELECT , INSERT ,, ", "SELECT|INSERT"},
+        {`["SELECT","DELETE"]`, "SELECT|DELETE"},
+        {"SELECT", "SELECT"},
+        {"", ""},
+    } {
+        if got := strings.Join(splitOperations(tc.value), "|"); got != tc.want {
+            t.Errorf("splitOperations(%q) = %q, want %q", tc.value, got, tc.want)
+        }
+    }
+}
+
+func TestEntryOperationsSplitDuration(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+    l := LogEntry{
+        Duration:   "90ms",
+        Attributes: map[string]string{"db.operation": "SELECT;UPDATE;DELETE"},
+    }
+    _, span := startEntrySpan(context.Background(), tp.Tracer("test"), l, config{OperationKeys: []string{"db.operation"}})
+    endEntrySpan(span, l, config{})
+
+    var entry trace.ReadOnlySpan
+    var children []trace.ReadOnlySpan
+    for _, s := range recorder.Ended() {
+        if s.SpanContext().Equal(span.SpanContext()) {
+            entry = s
+        } else {
+            children = append(children, s)
+        }
+    }
+    if entry == nil || len(children) != 3 {
+        t.Fatalf("recorded entry %v and %d children, want 3", entry != nil, len(children))
+    }
+    for i, child := range children {
+        if !child.Parent().Equal(entry.SpanContext()) {
+            t.Errorf("child %d is not under the entry span", i)
+        }
+        AssertSpanAttribute(t, child, "operation.index", i)
+        if d := child.EndTime().Sub(child.StartTime()); d != 30*time.Millisecond {
+            t.Errorf("child %d lasts %s, want 30ms", i, d)
+        }
+        if want := entry.StartTime().Add(time.Duration(i) * 30 * time.Millisecond); !child.StartTime().Equal(want) {
+            t.Errorf("child %d starts at %s, want %s", i, child.StartTime(), want)
+        }
+    }
+    if children[1].Name() != "UPDATE" {
+        t.Errorf("second child %q, want UPDATE", children[1].Name())
+    }
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/metric"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter wrapper recording the health of the export pipeline:
+//
+//   - exporter.batch.size: histogram of the spans per export call
+//   - exporter.spans: counter of spans handed to the exporter, with outcome
+//     "success" or "failure", giving the export success rate
+//
+// The queue length of the batch and microbatch processors and the circuit
+// breaker state are reported separately by observeQueueLength and
+// observeCircuitState.
+type pipelineMetricsExporter struct {
+    trace.SpanExporter
+    batchSize metric.Int64Histogram
+    spans     metric.Int64Counter
+}
+
+func newPipelineMetricsExporter(exporter trace.SpanExporter, meter metric.Meter) (*pipelineMetricsExporter, error) {
+    batchSize, err := meter.Int64Histogram("exporter.batch.size",
+        metric.WithDescription("Spans per export call"),
+        metric.WithUnit("{span}"),
+        metric.WithExplicitBucketBoundaries(1, 8, 32, 128, 512, 2048))
+    if err != nil {
+        return nil, err
+    }
+    spans, err := meter.Int64Counter("exporter.spans",
+        metric.WithDescription("Spans handed to the exporter, by outcome"),
+        metric.WithUnit("{span}"))
+    if err != nil {
+        return nil, err
+    }
+    return &pipelineMetricsExporter{SpanExporter: exporter, batchSize: batchSize, spans: spans}, nil
+}
+
+func (e *pipelineMetricsExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    err := e.SpanExporter.ExportSpans(ctx, spans)
+    outcome := "success"
+    if err != nil {
+        outcome = "failure"
+    }
+    e.batchSize.Record(ctx, int64(len(spans)))
+    e.spans.Add(ctx, int64(len(spans)), metric.WithAttributes(attribute.String("outcome", outcome)))
+    return err
+}
+
+// Report processor.queue.length, the spans buffered and not yet exported, as
+// an observable gauge read from length at each collection
+func observeQueueLength(meter metric.Meter, length func() int) error {
+    _, err := meter.Int64ObservableGauge("processor.queue.length",
+        metric.WithDescription("Spans buffered by the span processor awaiting export"),
+        metric.WithUnit("{span}"),
+        metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+            o.Observe(int64(length()))
+            return nil
+        }))
+    return err
+}
+
+// Report exporter.circuit.state as an observable gauge: 0 closed, 1 open,
+// 2 half-open
+func observeCircuitState(meter metric.Meter, state func() circuitState) error {
+    _, err := meter.Int64ObservableGauge("exporter.circuit.state",
+        metric.WithDescription("Exporter circuit breaker state: 0 closed, 1 open, 2 half-open"),
+        metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+            o.Observe(int64(state()))
+            return nil
+        }))
+    return err
+}
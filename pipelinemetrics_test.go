@@ -0,0 +1,123 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/metric"
+    "go.opentelemetry.io/otel/sdk/metric/metricdata"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Metrics collected from reader, by instrument name
+func collectMetrics(t *testing.T, reader metric.Reader) map[string]metricdata.Aggregation {
+    t.Helper()
+    var rm metricdata.ResourceMetrics
+    if err := reader.Collect(context.Background(), &rm); err != nil {
+        t.Fatal(err)
+    }
+    metrics := map[string]metricdata.Aggregation{}
+    for _, sm := range rm.ScopeMetrics {
+        for _, m := range sm.Metrics {
+            metrics[m.Name] = m.Data
+        }
+    }
+    return metrics
+}
+
+func TestPipelineMetricsRecorded(t *testing.T) {
+    reader := metric.NewManualReader()
+    meter := metric.NewMeterProvider(metric.WithReader(reader)).Meter("test")
+    inner := &flakyExporter{}
+    e, err := newPipelineMetricsExporter(inner, meter)
+    if err != nil {
+        t.Fatal(err)
+    }
+    queued := 7
+    if err := observeQueueLength(meter, func() int { return queued }); err != nil {
+        t.Fatal(err)
+    }
+
+    ctx := context.Background()
+    if err := e.ExportSpans(ctx, tracetest.SpanStubs{{Name: "a"}, {Name: "b"}, {Name: "c"}}.Snapshots()); err != nil {
+        t.Fatal(err)
+    }
+    inner.fail = true
+    if err := e.ExportSpans(ctx, tracetest.SpanStubs{{Name: "d"}}.Snapshots()); err == nil {
+        t.Fatal("export error not returned")
+    }
+    metrics := collectMetrics(t, reader)
+
+    hist, ok := metrics["exporter.batch.size"].(metricdata.Histogram[int64])
+    if !ok || len(hist.DataPoints) != 1 {
+        t.Fatalf("exporter.batch.size = %#v", metrics["exporter.batch.size"])
+    }
+    if dp := hist.DataPoints[0]; dp.Count != 2 || dp.Sum != 4 {
+        t.Errorf("batch size count %d sum %d, want 2 and 4", dp.Count, dp.Sum)
+    }
+
+    sum, ok := metrics["exporter.spans"].(metricdata.Sum[int64])
+    if !ok {
+        t.Fatalf("exporter.spans = %#v", metrics["exporter.spans"])
+    }
+    byOutcome := map[string]int64{}
+    for _, dp := range sum.DataPoints {
+        outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+        byOutcome[outcome.AsString()] = dp.Value
+    }
+    if byOutcome["success"] != 3 || byOutcome["failure"] != 1 || len(byOutcome) != 2 {
+        t.Errorf("exporter.spans by outcome = %v, want 3 success and 1 failure", byOutcome)
+    }
+
+    gauge, ok := metrics["processor.queue.length"].(metricdata.Gauge[int64])
+    if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 7 {
+        t.Errorf("processor.queue.length = %#v, want 7", metrics["processor.queue.length"])
+    }
+}
+
+func TestQueueLengthOfDefaultBatchProcessor(t *testing.T) {
+    reader := metric.NewManualReader()
+    meter := metric.NewMeterProvider(metric.WithReader(reader)).Meter("test")
+    inner := tracetest.NewInMemoryExporter()
+    cfg := loadConfig()
+    cfg.PipelineMetrics = true
+    processor, err := newSpanProcessor(cfg, inner)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer processor.Shutdown(context.Background())
+    queue, ok := processor.(interface{ Len() int })
+    if !ok {
+        t.Fatalf("%q processor %T reports no queue length", cfg.SpanProcessor, processor)
+    }
+    if err := observeQueueLength(meter, queue.Len); err != nil {
+        t.Fatal(err)
+    }
+    queueLength := func() int64 {
+        t.Helper()
+        gauge, ok := collectMetrics(t, reader)["processor.queue.length"].(metricdata.Gauge[int64])
+        if !ok || len(gauge.DataPoints) != 1 {
+            t.Fatalf("processor.queue.length = %#v", gauge)
+        }
+        return gauge.DataPoints[0].Value
+    }
+
+    for _, name := range []string{"a", "b", "c"} {
+        processor.OnEnd(sampledSpan(name))
+    }
+    processor.OnEnd(tracetest.SpanStub{Name: "unsampled"}.Snapshot())
+    if n := queueLength(); n != 3 {
+        t.Errorf("queue length = %d before export, want the 3 sampled spans", n)
+    }
+
+    if err := processor.ForceFlush(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if n := len(inner.GetSpans()); n != 3 {
+        t.Errorf("exported %d spans, want 3", n)
+    }
+    if n := queueLength(); n != 0 {
+        t.Errorf("queue length = %d after export, want 0", n)
+    }
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sort"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Resource detectors by name, for use in presets
+var resourceDetectors = map[string]resource.Option{
+    "host":            resource.WithHost(),
+    "host-id":         resource.WithHostID(),
+    "os":              resource.WithOS(),
+    "process":         resource.WithProcess(),
+    "process-runtime": resource.WithProcessRuntimeName(),
+    "container":       resource.WithContainer(),
+    "k8s-env":         resource.WithDetectors(k8sEnvDetector{}),
+    "aws-ecs":         resource.WithAttributes(attribute.String("cloud.provider", "aws"), attribute.String("cloud.platform", "aws_ecs")),
+}
+
+// Detectors enabled by each RESOURCE_PRESET
+var resourcePresets = map[string][]string{
+    "kubernetes": {"container", "host", "os", "process-runtime", "k8s-env"},
+    "aws-ecs":    {"container", "host", "os", "process-runtime", "aws-ecs"},
+    "bare-metal": {"host", "host-id", "os", "process"},
+}
+
+// Detector options for a preset; an empty name selects none
+func presetDetectors(name string) ([]resource.Option, error) {
+    if name == "" {
+        return nil, nil
+    }
+    detectors, ok := resourcePresets[name]
+    if !ok {
+        names := make([]string, 0, len(resourcePresets))
+        for n := range resourcePresets {
+            names = append(names, n)
+        }
+        sort.Strings(names)
+        return nil, fmt.Errorf("unknown resource preset %q, want one of %v", name, names)
+    }
+    opts := make([]resource.Option, 0, len(detectors))
+    for _, d := range detectors {
+        opts = append(opts, resourceDetectors[d])
+    }
+    return opts, nil
+}
+
+// k8s.* attributes from the variables the Kubernetes downward API is
+// commonly mapped to: POD_NAME, POD_NAMESPACE, NODE_NAME and POD_UID
+type k8sEnvDetector struct{}
+
+func (k8sEnvDetector) Detect(context.Context) (*resource.Resource, error) {
+    var attrs []attribute.KeyValue
+    for _, v := range []struct{ env, key string }{
+        {"POD_NAME", "k8s.pod.name"},
+        {"POD_NAMESPACE", "k8s.namespace.name"},
+        {"NODE_NAME", "k8s.node.name"},
+        {"POD_UID", "k8s.pod.uid"},
+    } {
+        if value := os.Getenv(v.env); value != "" {
+            attrs = append(attrs, attribute.String(v.key, value))
+        }
+    }
+    return resource.NewSchemaless(attrs...), nil
+}
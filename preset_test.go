@@ -0,0 +1,76 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+func presetResource(t *testing.T, name string) *resource.Resource {
+    t.Helper()
+    opts, err := presetDetectors(name)
+    if err != nil {
+        t.Fatal(err)
+    }
+    res, err := resource.New(context.Background(), opts...)
+    if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+        t.Fatal(err)
+    }
+    return res
+}
+
+func TestPresetsEnableExpectedDetectors(t *testing.T) {
+    t.Setenv("POD_NAME", "web-1-abc")
+    t.Setenv("POD_NAMESPACE", "shop")
+    tests := []struct {
+        preset  string
+        present []string
+        absent  []string
+        values  map[string]string
+    }{
+        {"kubernetes", []string{"host.name", "os.type", "process.runtime.name", "k8s.pod.name"}, []string{"process.pid", "cloud.provider"},
+            map[string]string{"k8s.pod.name": "web-1-abc", "k8s.namespace.name": "shop"}},
+        {"aws-ecs", []string{"host.name", "os.type", "process.runtime.name"}, []string{"k8s.pod.name", "process.pid"},
+            map[string]string{"cloud.provider": "aws", "cloud.platform": "aws_ecs"}},
+        {"bare-metal", []string{"host.name", "os.type", "process.pid"}, []string{"k8s.pod.name", "cloud.provider"}, nil},
+    }
+    for _, tt := range tests {
+        t.Run(tt.preset, func(t *testing.T) {
+            set := presetResource(t, tt.preset).Set()
+            for _, key := range tt.present {
+                if !set.HasValue(attribute.Key(key)) {
+                    t.Errorf("missing %s", key)
+                }
+            }
+            for _, key := range tt.absent {
+                if set.HasValue(attribute.Key(key)) {
+                    t.Errorf("unexpected %s", key)
+                }
+            }
+            for key, want := range tt.values {
+                if v, _ := set.Value(attribute.Key(key)); v.AsString() != want {
+                    t.Errorf("%s = %q, want %q", key, v.AsString(), want)
+                }
+            }
+        })
+    }
+}
+
+func TestPresetDetectorNames(t *testing.T) {
+    for preset, detectors := range resourcePresets {
+        for _, d := range detectors {
+            if resourceDetectors[d] == nil {
+                t.Errorf("preset %s uses unknown detector %q", preset, d)
+            }
+        }
+    }
+    if opts, err := presetDetectors(""); err != nil || opts != nil {
+        t.Errorf("empty preset = %v, %v, want no detectors", opts, err)
+    }
+    if _, err := presetDetectors("mainframe"); err == nil {
+        t.Error("unknown preset accepted")
+    }
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "slices"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+    defaultMicroBatchInterval = 50 * time.Millisecond
+    defaultMicroBatchMaxSize  = 64
+)
+
+// Build the span processor for the SPAN_PROCESSOR strategy: "batch" (the
+// SDK batcher), "sync" (the SDK simple processor) or "microbatch"
+func newSpanProcessor(cfg config, exporter trace.SpanExporter) (trace.SpanProcessor, error) {
+    switch cfg.SpanProcessor {
+    case "batch":
+        if cfg.PipelineMetrics {
+            return newCountedBatchProcessor(exporter), nil
+        }
+        return trace.NewBatchSpanProcessor(exporter), nil
+    case "sync":
+        return trace.NewSimpleSpanProcessor(exporter), nil
+    case "microbatch":
+        return newMicroBatchProcessor(exporter, cfg.MicroBatchInterval, cfg.MicroBatchMaxSize), nil
+    }
+    return nil, fmt.Errorf("unknown span processor %q", cfg.SpanProcessor)
+}
+
+// Names of the wrapping processor stages in their default execution order:
+// slow-span and SLO tagging, trace duration, then inlining resource
+// attributes, then truncating oversized values and the attribute
+// allowlist/denylist, so these also apply to attributes added by the earlier
+// stages
+var defaultProcessorOrder = []string{"slow-span", "slo", "trace-duration", "inline-resource", "attribute-truncate", "attribute-filter"}
+
+// Wrap the export processor with the enabled stages, running them in order
+// (SPAN_PROCESSOR_ORDER). Stages left out of order run after the listed ones,
+// in their default order.
+func chainSpanProcessors(cfg config, export trace.SpanProcessor, order []string) (trace.SpanProcessor, error) {
+    seen := map[string]bool{}
+    var stages []string
+    for _, name := range append(append([]string(nil), order...), defaultProcessorOrder...) {
+        if !slices.Contains(defaultProcessorOrder, name) {
+            return nil, fmt.Errorf("unknown span processor stage %q", name)
+        }
+        if !seen[name] {
+            seen[name] = true
+            stages = append(stages, name)
+        }
+    }
+
+    // The first stage to run is the outermost wrapper
+    processor := export
+    for i := len(stages) - 1; i >= 0; i-- {
+        switch stages[i] {
+        case "slow-span":
+            if cfg.SlowSpanThreshold > 0 || len(cfg.SlowSpanPrefixes) > 0 {
+                processor = newSlowSpanProcessor(processor, cfg.SlowSpanThreshold, cfg.SlowSpanPrefixes)
+            }
+        case "slo":
+            if !cfg.SLORules.empty() {
+                processor = newSLOProcessor(processor, cfg.SLORules)
+            }
+        case "trace-duration":
+            if cfg.TraceDuration {
+                processor = newTraceDurationProcessor(processor)
+            }
+        case "inline-resource":
+            if cfg.InlineResource {
+                processor = newInlineResourceProcessor(processor)
+            }
+        case "attribute-truncate":
+            if len(cfg.TruncateRules) > 0 {
+                processor = newAttributeTruncateProcessor(processor, cfg.TruncateRules)
+            }
+        case "attribute-filter":
+            if len(cfg.AttributeAllowlist) > 0 {
+                processor = newAttributeFilterProcessor(processor, cfg.AttributeAllowlist, true)
+            }
+            if len(cfg.AttributeDenylist) > 0 {
+                processor = newAttributeFilterProcessor(processor, cfg.AttributeDenylist, false)
+            }
+        }
+    }
+    return processor, nil
+}
+
+// Span processor exporting on a short fixed interval, or as soon as maxSize
+// spans are buffered, whichever comes first
+type microBatchProcessor struct {
+    exporter trace.SpanExporter
+    maxSize  int
+
+    mu  sync.Mutex
+    buf []trace.ReadOnlySpan
+
+    exportMu sync.Mutex
+    stop     chan struct{}
+    done     chan struct{}
+    stopOnce sync.Once
+}
+
+func newMicroBatchProcessor(exporter trace.SpanExporter, interval time.Duration, maxSize int) *microBatchProcessor {
+    if interval <= 0 {
+        interval = defaultMicroBatchInterval
+    }
+    if maxSize <= 0 {
+        maxSize = defaultMicroBatchMaxSize
+    }
+    p := &microBatchProcessor{
+        exporter: exporter,
+        maxSize:  maxSize,
+        stop:     make(chan struct{}),
+        done:     make(chan struct{}),
+    }
+    go p.loop(interval)
+    return p
+}
+
+func (p *microBatchProcessor) loop(interval time.Duration) {
+    defer close(p.done)
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            _ = p.flush(context.Background())
+        case <-p.stop:
+            return
+        }
+    }
+}
+
+func (p *microBatchProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *microBatchProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if !s.SpanContext().IsSampled() {
+        return
+    }
+    p.mu.Lock()
+    p.buf = append(p.buf, s)
+    full := len(p.buf) >= p.maxSize
+    p.mu.Unlock()
+
+    // Hard cap reached: export now rather than waiting for the tick
+    if full {
+        _ = p.flush(context.Background())
+    }
+}
+
+// Spans buffered for the next flush
+func (p *microBatchProcessor) Len() int {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return len(p.buf)
+}
+
+// SDK batch span processor keeping count of the sampled spans it has queued
+// and not yet exported, which the SDK does not expose. Spans the SDK drops
+// from a full queue are never exported and stay counted.
+type countedBatchProcessor struct {
+    trace.SpanProcessor
+    pending *atomic.Int64
+}
+
+func newCountedBatchProcessor(exporter trace.SpanExporter) *countedBatchProcessor {
+    pending := new(atomic.Int64)
+    return &countedBatchProcessor{
+        SpanProcessor: trace.NewBatchSpanProcessor(countingExporter{SpanExporter: exporter, pending: pending}),
+        pending:       pending,
+    }
+}
+
+// Count the span in the queue; the batcher only queues sampled spans
+func (p *countedBatchProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if s.SpanContext().IsSampled() {
+        p.pending.Add(1)
+    }
+    p.SpanProcessor.OnEnd(s)
+}
+
+// Spans queued and not yet exported
+func (p *countedBatchProcessor) Len() int {
+    return int(p.pending.Load())
+}
+
+// Exporter counting each batch out of the pending spans once its export
+// call returns, whether or not it succeeded
+type countingExporter struct {
+    trace.SpanExporter
+    pending *atomic.Int64
+}
+
+func (e countingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    defer e.pending.Add(-int64(len(spans)))
+    return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+func (p *microBatchProcessor) flush(ctx context.Context) error {
+    p.exportMu.Lock()
+    defer p.exportMu.Unlock()
+
+    p.mu.Lock()
+    batch := p.buf
+    p.buf = nil
+    p.mu.Unlock()
+
+    if len(batch) == 0 {
+        return nil
+    }
+    return p.exporter.ExportSpans(ctx, batch)
+}
+
+func (p *microBatchProcessor) ForceFlush(ctx context.Context) error {
+    return p.flush(ctx)
+}
+
+func (p *microBatchProcessor) Shutdown(ctx context.Context) error {
+    var err error
+    p.stopOnce.Do(func() {
+        close(p.stop)
+        <-p.done
+        err = p.flush(ctx)
+        if shutdownErr := p.exporter.Shutdown(ctx); err == nil {
+            err = shutdownErr
+        }
+    })
+    return err
+}
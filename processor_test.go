@@ -0,0 +1,129 @@
+package main
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func sampledSpan(name string) trace.ReadOnlySpan {
+    sc := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+        TraceID:    oteltrace.TraceID{1},
+        SpanID:     oteltrace.SpanID{1},
+        TraceFlags: oteltrace.FlagsSampled,
+    })
+    return tracetest.SpanStub{Name: name, SpanContext: sc}.Snapshot()
+}
+
+func TestMicroBatchFlushesOnInterval(t *testing.T) {
+    inner := &retainingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+    p := newMicroBatchProcessor(inner, 10*time.Millisecond, 100)
+    defer p.Shutdown(context.Background())
+
+    p.OnEnd(sampledSpan("a"))
+    p.OnEnd(tracetest.SpanStub{Name: "unsampled"}.Snapshot())
+    if n := p.Len(); n != 1 {
+        t.Fatalf("buffered %d spans, want only the sampled one", n)
+    }
+    deadline := time.Now().Add(5 * time.Second)
+    for len(inner.GetSpans()) == 0 && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+    if spans := inner.GetSpans(); len(spans) != 1 || spans[0].Name != "a" {
+        t.Fatalf("exported %v after the interval, want span a", spans)
+    }
+    if n := p.Len(); n != 0 {
+        t.Errorf("%d spans still buffered after the flush", n)
+    }
+}
+
+func TestMicroBatchFlushesAtMaxSizeAndOnShutdown(t *testing.T) {
+    inner := &retainingExporter{InMemoryExporter: tracetest.NewInMemoryExporter()}
+    p := newMicroBatchProcessor(inner, time.Hour, 2)
+
+    p.OnEnd(sampledSpan("a"))
+    p.OnEnd(sampledSpan("b"))
+    if n := len(inner.GetSpans()); n != 2 {
+        t.Fatalf("exported %d spans at the size cap, want 2", n)
+    }
+    p.OnEnd(sampledSpan("c"))
+    if err := p.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if n := len(inner.GetSpans()); n != 3 || inner.shutdowns != 1 {
+        t.Errorf("after shutdown exported %d spans and shut down %d times, want 3 and 1", n, inner.shutdowns)
+    }
+    if err := p.Shutdown(context.Background()); err != nil || inner.shutdowns != 1 {
+        t.Errorf("second shutdown = %v with %d exporter shutdowns", err, inner.shutdowns)
+    }
+}
+
+func TestNewSpanProcessorStrategies(t *testing.T) {
+    exporter := tracetest.NewInMemoryExporter()
+    for _, strategy := range []string{"batch", "sync", "microbatch"} {
+        p, err := newSpanProcessor(config{SpanProcessor: strategy}, exporter)
+        if err != nil {
+            t.Errorf("%s: %v", strategy, err)
+            continue
+        }
+        if _, ok := p.(*microBatchProcessor); ok != (strategy == "microbatch") {
+            t.Errorf("%s: got %T", strategy, p)
+        }
+        p.Shutdown(context.Background())
+    }
+    if _, err := newSpanProcessor(config{SpanProcessor: "eventually"}, exporter); err == nil {
+        t.Error("unknown strategy accepted")
+    }
+}
+
+func TestProcessorOrderChangesOutcome(t *testing.T) {
+    cfg := config{SlowSpanThreshold: time.Millisecond, AttributeAllowlist: []string{"http.method"}}
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    span := tracetest.SpanStub{
+        Name:        "GET /slow",
+        SpanContext: sampledSpan("").SpanContext(),
+        StartTime:   start,
+        EndTime:     start.Add(time.Second),
+        Attributes:  []attribute.KeyValue{attribute.String("http.method", "GET"), attribute.String("user.id", "u-1")},
+    }.Snapshot()
+
+    tagged := func(order []string) bool {
+        t.Helper()
+        recorder := tracetest.NewSpanRecorder()
+        p, err := chainSpanProcessors(cfg, recorder, order)
+        if err != nil {
+            t.Fatal(err)
+        }
+        p.OnEnd(span)
+        ended := recorder.Ended()
+        if len(ended) != 1 {
+            t.Fatalf("order %v: recorded %d spans", order, len(ended))
+        }
+        slow := false
+        for _, kv := range ended[0].Attributes() {
+            switch kv.Key {
+            case "slow":
+                slow = kv.Value.AsBool()
+            case "user.id":
+                t.Errorf("order %v: user.id not filtered", order)
+            }
+        }
+        return slow
+    }
+
+    // By default filtering runs last and drops the slow tag with user.id
+    if tagged(nil) {
+        t.Error("default order kept the slow tag through the allowlist")
+    }
+    if !tagged([]string{"attribute-filter", "slow-span"}) {
+        t.Error("filtering first did not leave the slow tag in place")
+    }
+    if _, err := chainSpanProcessors(cfg, tracetest.NewSpanRecorder(), []string{"compress"}); err == nil {
+        t.Error("unknown stage accepted")
+    }
+}
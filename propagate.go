@@ -0,0 +1,51 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Write the current span context into the entry's attributes as W3C
+// traceparent (and tracestate when set), so consumers of the re-emitted entry
+// can continue the trace. Nothing is written when ctx has no valid span.
+func injectContextIntoEntry(ctx context.Context, l *LogEntry) {
+    if l.Attributes == nil {
+        l.Attributes = map[string]string{}
+    }
+    propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(l.Attributes))
+}
+
+// Context whose remote parent is the span named by the entry's ParentSpanID,
+// so spans of entries that declare their parent rebuild the original tree.
+// The trace is the entry's TraceId when it is a valid trace ID, otherwise the
+// one requestIDGenerator derives from the request ID in ctx. Entries carry no
+// sampling decision, so the parent is not marked sampled and the root sampler
+// decides. ctx is returned unchanged when no parent is declared or it cannot
+// be linked.
+func contextWithEntryParent(ctx context.Context, l LogEntry) (context.Context, error) {
+    if l.ParentSpanID == "" {
+        return ctx, nil
+    }
+    spanID, err := trace.SpanIDFromHex(l.ParentSpanID)
+    if err != nil {
+        return ctx, fmt.Errorf("parent_span_id %q is not a 16-digit hex span ID", l.ParentSpanID)
+    }
+    traceID, err := trace.TraceIDFromHex(l.TraceID)
+    if err != nil {
+        requestID, _ := ctx.Value(requestIDKey{}).(string)
+        if requestID == "" {
+            return ctx, errors.New("entry has neither a valid TraceId nor a request.id to place the parent in a trace")
+        }
+        traceID = requestTraceID(requestID)
+    }
+    parent := trace.NewSpanContext(trace.SpanContextConfig{
+        TraceID: traceID,
+        SpanID:  spanID,
+        Remote:  true,
+    })
+    return trace.ContextWithRemoteSpanContext(ctx, parent), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestEntryParentLeavesSamplingToRootSampler(t *testing.T) {
+    entry := LogEntry{TraceID: "0af7651916cd43dd8448eb211c80319c", ParentSpanID: "b7ad6b7169203331"}
+    ctx, err := contextWithEntryParent(context.Background(), entry)
+    if err != nil {
+        t.Fatal(err)
+    }
+    parent := oteltrace.SpanContextFromContext(ctx)
+    if parent.TraceID().String() != entry.TraceID || parent.SpanID().String() != entry.ParentSpanID {
+        t.Fatalf("parent %s/%s, want %s/%s", parent.TraceID(), parent.SpanID(), entry.TraceID, entry.ParentSpanID)
+    }
+    if parent.IsSampled() {
+        t.Error("declared parent marked sampled")
+    }
+
+    for _, tc := range []struct {
+        sampler trace.Sampler
+        sampled bool
+    }{
+        {trace.NeverSample(), false},
+        {trace.AlwaysSample(), true},
+    } {
+        tp := trace.NewTracerProvider(trace.WithSampler(trace.ParentBased(tc.sampler, trace.WithRemoteParentNotSampled(tc.sampler))))
+        _, span := tp.Tracer("test").Start(ctx, "entry")
+        if got := span.SpanContext().IsSampled(); got != tc.sampled {
+            t.Errorf("%s: sampled = %v, want %v", tc.sampler.Description(), got, tc.sampled)
+        }
+        if span.SpanContext().TraceID() != parent.TraceID() {
+            t.Errorf("%s: span not in the declared parent's trace", tc.sampler.Description())
+        }
+    }
+}
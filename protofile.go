@@ -0,0 +1,397 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/instrumentation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+    commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+    resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+    tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const defaultProtoFilePath = "spans.pb"
+
+// Upper bound on a single framed message, so a corrupt length prefix cannot
+// make the reader allocate an arbitrary amount of memory
+const maxProtoMessageSize = 64 << 20
+
+// Exporter appending each export call to a file as one OTLP TracesData
+// message, in the configured encoding. The file can be replayed with
+// readSpans using the same encoding.
+type protoFileExporter struct {
+    serializer spanSerializer
+
+    mu   sync.Mutex
+    file *os.File
+}
+
+func newProtoFileExporter(path string, encoding exportEncoding) (*protoFileExporter, error) {
+    if path == "" {
+        path = defaultProtoFilePath
+    }
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+    if err != nil {
+        return nil, fmt.Errorf("protofile exporter: %w", err)
+    }
+    return &protoFileExporter{serializer: encoding.serializer(), file: f}, nil
+}
+
+func (e *protoFileExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if len(spans) == 0 {
+        return nil
+    }
+    frame, err := e.serializer.Marshal(toProtoTracesData(spans))
+    if err != nil {
+        return fmt.Errorf("protofile marshal: %w", err)
+    }
+
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.file == nil {
+        return nil
+    }
+    if _, err := e.file.Write(frame); err != nil {
+        return fmt.Errorf("protofile export: %w", err)
+    }
+    return nil
+}
+
+func (e *protoFileExporter) Shutdown(context.Context) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.file == nil {
+        return nil
+    }
+    err := e.file.Close()
+    e.file = nil
+    return err
+}
+
+// Length-prefixed TracesData message for a batch of spans
+func marshalProtoFrame(spans []trace.ReadOnlySpan) ([]byte, error) {
+    frame, err := protobufSerializer{}.Marshal(toProtoTracesData(spans))
+    if err != nil {
+        return nil, fmt.Errorf("protofile marshal: %w", err)
+    }
+    return frame, nil
+}
+
+// Group spans by resource and instrumentation scope, keeping the order in
+// which each group is first seen
+func toProtoTracesData(spans []trace.ReadOnlySpan) *tracepb.TracesData {
+    data := &tracepb.TracesData{}
+    resourceSpans := map[*resource.Resource]*tracepb.ResourceSpans{}
+    scopeSpans := map[*resource.Resource]map[instrumentation.Scope]*tracepb.ScopeSpans{}
+
+    for _, s := range spans {
+        res := s.Resource()
+        rs, ok := resourceSpans[res]
+        if !ok {
+            rs = &tracepb.ResourceSpans{
+                Resource:  &resourcepb.Resource{Attributes: toProtoAttributes(res.Attributes())},
+                SchemaUrl: res.SchemaURL(),
+            }
+            resourceSpans[res] = rs
+            scopeSpans[res] = map[instrumentation.Scope]*tracepb.ScopeSpans{}
+            data.ResourceSpans = append(data.ResourceSpans, rs)
+        }
+
+        scope := s.InstrumentationScope()
+        ss, ok := scopeSpans[res][scope]
+        if !ok {
+            ss = &tracepb.ScopeSpans{
+                Scope: &commonpb.InstrumentationScope{
+                    Name:       scope.Name,
+                    Version:    scope.Version,
+                    Attributes: toProtoAttributes(scope.Attributes.ToSlice()),
+                },
+                SchemaUrl: scope.SchemaURL,
+            }
+            scopeSpans[res][scope] = ss
+            rs.ScopeSpans = append(rs.ScopeSpans, ss)
+        }
+        ss.Spans = append(ss.Spans, toProtoSpan(s))
+    }
+    return data
+}
+
+func toProtoSpan(s trace.ReadOnlySpan) *tracepb.Span {
+    sc := s.SpanContext()
+    traceID, spanID := sc.TraceID(), sc.SpanID()
+    span := &tracepb.Span{
+        TraceId:                traceID[:],
+        SpanId:                 spanID[:],
+        TraceState:             sc.TraceState().String(),
+        Flags:                  uint32(sc.TraceFlags()),
+        Name:                   s.Name(),
+        Kind:                   tracepb.Span_SpanKind(s.SpanKind()),
+        StartTimeUnixNano:      uint64(s.StartTime().UnixNano()),
+        EndTimeUnixNano:        uint64(s.EndTime().UnixNano()),
+        Attributes:             toProtoAttributes(s.Attributes()),
+        DroppedAttributesCount: uint32(s.DroppedAttributes()),
+        DroppedEventsCount:     uint32(s.DroppedEvents()),
+        DroppedLinksCount:      uint32(s.DroppedLinks()),
+        Status:                 toProtoStatus(s.Status()),
+    }
+    if parent := s.Parent(); parent.HasSpanID() {
+        parentID := parent.SpanID()
+        span.ParentSpanId = parentID[:]
+    }
+    for _, ev := range s.Events() {
+        span.Events = append(span.Events, &tracepb.Span_Event{
+            TimeUnixNano:           uint64(ev.Time.UnixNano()),
+            Name:                   ev.Name,
+            Attributes:             toProtoAttributes(ev.Attributes),
+            DroppedAttributesCount: uint32(ev.DroppedAttributeCount),
+        })
+    }
+    for _, link := range s.Links() {
+        linkTraceID, linkSpanID := link.SpanContext.TraceID(), link.SpanContext.SpanID()
+        span.Links = append(span.Links, &tracepb.Span_Link{
+            TraceId:                linkTraceID[:],
+            SpanId:                 linkSpanID[:],
+            TraceState:             link.SpanContext.TraceState().String(),
+            Flags:                  uint32(link.SpanContext.TraceFlags()),
+            Attributes:             toProtoAttributes(link.Attributes),
+            DroppedAttributesCount: uint32(link.DroppedAttributeCount),
+        })
+    }
+    return span
+}
+
+// OTLP swaps the numeric values of Ok and Error relative to the codes package
+func toProtoStatus(status trace.Status) *tracepb.Status {
+    code := tracepb.Status_STATUS_CODE_UNSET
+    switch status.Code {
+    case codes.Error:
+        code = tracepb.Status_STATUS_CODE_ERROR
+    case codes.Ok:
+        code = tracepb.Status_STATUS_CODE_OK
+    }
+    return &tracepb.Status{Code: code, Message: status.Description}
+}
+
+func toProtoAttributes(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+    if len(attrs) == 0 {
+        return nil
+    }
+    out := make([]*commonpb.KeyValue, 0, len(attrs))
+    for _, kv := range attrs {
+        out = append(out, &commonpb.KeyValue{Key: string(kv.Key), Value: toProtoValue(kv.Value)})
+    }
+    return out
+}
+
+func toProtoValue(v attribute.Value) *commonpb.AnyValue {
+    switch v.Type() {
+    case attribute.BOOL:
+        return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+    case attribute.INT64:
+        return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+    case attribute.FLOAT64:
+        return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+    case attribute.BOOLSLICE:
+        var values []*commonpb.AnyValue
+        for _, b := range v.AsBoolSlice() {
+            values = append(values, toProtoValue(attribute.BoolValue(b)))
+        }
+        return protoArray(values)
+    case attribute.INT64SLICE:
+        var values []*commonpb.AnyValue
+        for _, n := range v.AsInt64Slice() {
+            values = append(values, toProtoValue(attribute.Int64Value(n)))
+        }
+        return protoArray(values)
+    case attribute.FLOAT64SLICE:
+        var values []*commonpb.AnyValue
+        for _, f := range v.AsFloat64Slice() {
+            values = append(values, toProtoValue(attribute.Float64Value(f)))
+        }
+        return protoArray(values)
+    case attribute.STRINGSLICE:
+        var values []*commonpb.AnyValue
+        for _, s := range v.AsStringSlice() {
+            values = append(values, toProtoValue(attribute.StringValue(s)))
+        }
+        return protoArray(values)
+    }
+    return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+}
+
+func protoArray(values []*commonpb.AnyValue) *commonpb.AnyValue {
+    return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+}
+
+// Read back every span of a protobuf-encoded file written by the protofile
+// exporter, in the order they were exported
+func readProtoSpans(r io.Reader) ([]tracetest.SpanStub, error) {
+    return readSpans(r, protobufSerializer{})
+}
+
+// Like readProtoSpans, for a file in any encoding
+func readSpans(r io.Reader, serializer spanSerializer) ([]tracetest.SpanStub, error) {
+    br := bufio.NewReader(r)
+    var stubs []tracetest.SpanStub
+    for {
+        data, err := serializer.Unmarshal(br)
+        if errors.Is(err, io.EOF) {
+            return stubs, nil
+        }
+        if err != nil {
+            return stubs, fmt.Errorf("protofile read: %w", err)
+        }
+        stubs = append(stubs, fromProtoTracesData(data)...)
+    }
+}
+
+func fromProtoTracesData(data *tracepb.TracesData) []tracetest.SpanStub {
+    var stubs []tracetest.SpanStub
+    for _, rs := range data.ResourceSpans {
+        res := resource.NewWithAttributes(rs.SchemaUrl, fromProtoAttributes(rs.GetResource().GetAttributes())...)
+        for _, ss := range rs.ScopeSpans {
+            scope := instrumentation.Scope{
+                Name:       ss.GetScope().GetName(),
+                Version:    ss.GetScope().GetVersion(),
+                SchemaURL:  ss.SchemaUrl,
+                Attributes: attribute.NewSet(fromProtoAttributes(ss.GetScope().GetAttributes())...),
+            }
+            for _, span := range ss.Spans {
+                stub := fromProtoSpan(span)
+                stub.Resource = res
+                stub.InstrumentationLibrary = scope
+                stubs = append(stubs, stub)
+            }
+        }
+    }
+    return stubs
+}
+
+func fromProtoSpan(span *tracepb.Span) tracetest.SpanStub {
+    sc := protoSpanContext(span.TraceId, span.SpanId, span.TraceState, span.Flags)
+    stub := tracetest.SpanStub{
+        Name:              span.Name,
+        SpanContext:       sc,
+        SpanKind:          oteltrace.SpanKind(span.Kind),
+        StartTime:         time.Unix(0, int64(span.StartTimeUnixNano)),
+        EndTime:           time.Unix(0, int64(span.EndTimeUnixNano)),
+        Attributes:        fromProtoAttributes(span.Attributes),
+        Status:            fromProtoStatus(span.GetStatus()),
+        DroppedAttributes: int(span.DroppedAttributesCount),
+        DroppedEvents:     int(span.DroppedEventsCount),
+        DroppedLinks:      int(span.DroppedLinksCount),
+    }
+    if len(span.ParentSpanId) > 0 {
+        traceID := sc.TraceID()
+        stub.Parent = protoSpanContext(traceID[:], span.ParentSpanId, "", 0)
+    }
+    for _, ev := range span.Events {
+        stub.Events = append(stub.Events, trace.Event{
+            Name:                  ev.Name,
+            Attributes:            fromProtoAttributes(ev.Attributes),
+            DroppedAttributeCount: int(ev.DroppedAttributesCount),
+            Time:                  time.Unix(0, int64(ev.TimeUnixNano)),
+        })
+    }
+    for _, link := range span.Links {
+        stub.Links = append(stub.Links, trace.Link{
+            SpanContext:           protoSpanContext(link.TraceId, link.SpanId, link.TraceState, link.Flags),
+            Attributes:            fromProtoAttributes(link.Attributes),
+            DroppedAttributeCount: int(link.DroppedAttributesCount),
+        })
+    }
+    return stub
+}
+
+// Span context from raw OTLP fields; malformed IDs or trace state are left
+// zero rather than failing the whole replay
+func protoSpanContext(traceID, spanID []byte, traceState string, flags uint32) oteltrace.SpanContext {
+    cfg := oteltrace.SpanContextConfig{TraceFlags: oteltrace.TraceFlags(flags)}
+    if len(traceID) == len(cfg.TraceID) {
+        copy(cfg.TraceID[:], traceID)
+    }
+    if len(spanID) == len(cfg.SpanID) {
+        copy(cfg.SpanID[:], spanID)
+    }
+    if ts, err := oteltrace.ParseTraceState(traceState); err == nil {
+        cfg.TraceState = ts
+    }
+    return oteltrace.NewSpanContext(cfg)
+}
+
+func fromProtoStatus(status *tracepb.Status) trace.Status {
+    switch status.GetCode() {
+    case tracepb.Status_STATUS_CODE_ERROR:
+        return trace.Status{Code: codes.Error, Description: status.GetMessage()}
+    case tracepb.Status_STATUS_CODE_OK:
+        return trace.Status{Code: codes.Ok}
+    }
+    return trace.Status{Code: codes.Unset}
+}
+
+func fromProtoAttributes(kvs []*commonpb.KeyValue) []attribute.KeyValue {
+    if len(kvs) == 0 {
+        return nil
+    }
+    out := make([]attribute.KeyValue, 0, len(kvs))
+    for _, kv := range kvs {
+        out = append(out, attribute.KeyValue{Key: attribute.Key(kv.Key), Value: fromProtoValue(kv.Value)})
+    }
+    return out
+}
+
+// Arrays are assumed homogeneous, as produced by toProtoValue; the element
+// type is taken from the first value
+func fromProtoValue(v *commonpb.AnyValue) attribute.Value {
+    switch v.GetValue().(type) {
+    case *commonpb.AnyValue_BoolValue:
+        return attribute.BoolValue(v.GetBoolValue())
+    case *commonpb.AnyValue_IntValue:
+        return attribute.Int64Value(v.GetIntValue())
+    case *commonpb.AnyValue_DoubleValue:
+        return attribute.Float64Value(v.GetDoubleValue())
+    case *commonpb.AnyValue_ArrayValue:
+        values := v.GetArrayValue().GetValues()
+        if len(values) == 0 {
+            return attribute.StringSliceValue(nil)
+        }
+        switch values[0].GetValue().(type) {
+        case *commonpb.AnyValue_BoolValue:
+            out := make([]bool, len(values))
+            for i, e := range values {
+                out[i] = e.GetBoolValue()
+            }
+            return attribute.BoolSliceValue(out)
+        case *commonpb.AnyValue_IntValue:
+            out := make([]int64, len(values))
+            for i, e := range values {
+                out[i] = e.GetIntValue()
+            }
+            return attribute.Int64SliceValue(out)
+        case *commonpb.AnyValue_DoubleValue:
+            out := make([]float64, len(values))
+            for i, e := range values {
+                out[i] = e.GetDoubleValue()
+            }
+            return attribute.Float64SliceValue(out)
+        }
+        out := make([]string, len(values))
+        for i, e := range values {
+            out[i] = e.GetStringValue()
+        }
+        return attribute.StringSliceValue(out)
+    }
+    return attribute.StringValue(v.GetStringValue())
+}
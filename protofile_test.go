@@ -0,0 +1,16 @@
+package main
+
+import (
+    "testing"
+
+    tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestFromProtoSpanShortTraceID(t *testing.T) {
+    for _, traceID := range [][]byte{nil, {1, 2, 3}} {
+        stub := fromProtoSpan(&tracepb.Span{Name: "s", TraceId: traceID, SpanId: []byte{1}, ParentSpanId: []byte{2}})
+        if stub.SpanContext.TraceID().IsValid() {
+            t.Errorf("trace ID %x: got valid trace ID %s, want it left zero", traceID, stub.SpanContext.TraceID())
+        }
+    }
+}
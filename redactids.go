@@ -0,0 +1,80 @@
+package main
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Exporter wrapper replacing every trace and span ID with a random one before
+// export, so traces can be shared without their real IDs. Within a batch the
+// same original ID always maps to the same replacement, keeping parent-child
+// relationships and links intact; the mapping is discarded after each batch
+// so nothing accumulates and batches cannot be correlated.
+type redactIDsExporter struct {
+    trace.SpanExporter
+    ids randomIDGenerator
+}
+
+func newRedactIDsExporter(exporter trace.SpanExporter) *redactIDsExporter {
+    return &redactIDsExporter{SpanExporter: exporter}
+}
+
+// Span with its own, parent and link span contexts replaced
+type redactedSpan struct {
+    trace.ReadOnlySpan
+    sc     oteltrace.SpanContext
+    parent oteltrace.SpanContext
+    links  []trace.Link
+}
+
+func (s redactedSpan) SpanContext() oteltrace.SpanContext { return s.sc }
+func (s redactedSpan) Parent() oteltrace.SpanContext      { return s.parent }
+func (s redactedSpan) Links() []trace.Link                { return s.links }
+
+func (e *redactIDsExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    m := idMapping{
+        ids:    e.ids,
+        traces: make(map[oteltrace.TraceID]oteltrace.TraceID),
+        spans:  make(map[oteltrace.SpanID]oteltrace.SpanID),
+    }
+    redacted := make([]trace.ReadOnlySpan, len(spans))
+    for i, s := range spans {
+        r := redactedSpan{ReadOnlySpan: s, sc: m.remap(s.SpanContext()), parent: m.remap(s.Parent())}
+        for _, link := range s.Links() {
+            link.SpanContext = m.remap(link.SpanContext)
+            r.links = append(r.links, link)
+        }
+        redacted[i] = r
+    }
+    return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+// Replacement IDs assigned so far in one batch
+type idMapping struct {
+    ids    randomIDGenerator
+    traces map[oteltrace.TraceID]oteltrace.TraceID
+    spans  map[oteltrace.SpanID]oteltrace.SpanID
+}
+
+// Span context with its IDs replaced; invalid (absent) IDs are kept
+func (m idMapping) remap(sc oteltrace.SpanContext) oteltrace.SpanContext {
+    if tid := sc.TraceID(); tid.IsValid() {
+        mapped, ok := m.traces[tid]
+        if !ok {
+            mapped, _ = m.ids.NewIDs(context.Background())
+            m.traces[tid] = mapped
+        }
+        sc = sc.WithTraceID(mapped)
+    }
+    if sid := sc.SpanID(); sid.IsValid() {
+        mapped, ok := m.spans[sid]
+        if !ok {
+            mapped = m.ids.NewSpanID(context.Background(), sc.TraceID())
+            m.spans[sid] = mapped
+        }
+        sc = sc.WithSpanID(mapped)
+    }
+    return sc
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRedactIDsPreservesRelationships(t *testing.T) {
+    recorder := tracetest.NewInMemoryExporter()
+    tp := trace.NewTracerProvider(trace.WithSyncer(recorder))
+    ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+    _, child := tp.Tracer("test").Start(ctx, "child")
+    child.End()
+    parent.End()
+    originals := recorder.GetSpans().Snapshots()
+
+    out := tracetest.NewInMemoryExporter()
+    if err := newRedactIDsExporter(out).ExportSpans(context.Background(), originals); err != nil {
+        t.Fatal(err)
+    }
+    redacted := out.GetSpans()
+    if len(redacted) != 2 {
+        t.Fatalf("got %d spans, want 2", len(redacted))
+    }
+    gotChild, gotParent := redacted[0], redacted[1]
+    if gotChild.Parent.SpanID() != gotParent.SpanContext.SpanID() {
+        t.Errorf("child parent %s, want redacted parent span %s", gotChild.Parent.SpanID(), gotParent.SpanContext.SpanID())
+    }
+    if gotChild.SpanContext.TraceID() != gotParent.SpanContext.TraceID() {
+        t.Error("spans of one trace got different redacted trace IDs")
+    }
+
+    var output strings.Builder
+    for _, s := range redacted {
+        output.WriteString(s.SpanContext.TraceID().String() + s.SpanContext.SpanID().String() + s.Parent.SpanID().String())
+    }
+    for _, s := range originals {
+        for _, id := range []string{s.SpanContext().TraceID().String(), s.SpanContext().SpanID().String()} {
+            if strings.Contains(output.String(), id) {
+                t.Errorf("original ID %s appears in redacted output", id)
+            }
+        }
+    }
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "os"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Spans handed to the exporter per call when replaying a file
+const replayBatchSize = 512
+
+// Re-export every span of a file written by the protofile exporter, e.g. to
+// backfill a collector after an outage
+func ReplayFile(ctx context.Context, path string, exporter trace.SpanExporter) error {
+    spans, encoding, err := readSpanFile(path)
+    if err != nil {
+        return fmt.Errorf("replay: %w", err)
+    }
+    for start := 0; start < len(spans); start += replayBatchSize {
+        batch := spans[start:min(start+replayBatchSize, len(spans))]
+        if err := exporter.ExportSpans(ctx, batch); err != nil {
+            return fmt.Errorf("replay %s: %d of %d spans exported: %w", path, start, len(spans), err)
+        }
+    }
+    log.Printf("Replayed %d spans from %s as %s", len(spans), path, encoding)
+    return nil
+}
+
+// Read the spans of a file written by the protofile exporter. The encoding
+// is detected from the content: JSON messages start with `{"`, which cannot
+// begin a length-prefixed protobuf message (its second byte is a field tag).
+func readSpanFile(path string) ([]trace.ReadOnlySpan, exportEncoding, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, "", err
+    }
+    defer f.Close()
+
+    r := bufio.NewReader(f)
+    encoding := encodingProtobuf
+    if prefix, _ := r.Peek(2); string(prefix) == `{"` {
+        encoding = encodingJSON
+    }
+    stubs, err := readSpans(r, encoding.serializer())
+    if err != nil {
+        return nil, encoding, fmt.Errorf("%s: %w", path, err)
+    }
+    return tracetest.SpanStubs(stubs).Snapshots(), encoding, nil
+}
+
+// Write DiffTraces of the spans in two protofile exporter files to w, one
+// difference per line, and report whether there were any
+func diffSpanFiles(w io.Writer, pathA, pathB string) (bool, error) {
+    a, _, err := readSpanFile(pathA)
+    if err != nil {
+        return false, err
+    }
+    b, _, err := readSpanFile(pathB)
+    if err != nil {
+        return false, err
+    }
+    diffs := DiffTraces(a, b)
+    for _, d := range diffs {
+        fmt.Fprintln(w, d)
+    }
+    return len(diffs) > 0, nil
+}
+
+// Write a report of the spans in a protofile exporter file to w: "stats"
+// for TraceStatsReport, "dot" for one traceToDOT digraph per trace, or
+// "deps" for the dependencyEdges as JSON
+func writeSpanReport(w io.Writer, report, path string) error {
+    spans, _, err := readSpanFile(path)
+    if err != nil {
+        return err
+    }
+    switch report {
+    case "stats":
+        data, err := TraceStatsReport(spans)
+        if err != nil {
+            return err
+        }
+        _, err = fmt.Fprintf(w, "%s\n", data)
+        return err
+    case "dot":
+        order, byTrace := spansByTrace(spans)
+        for _, id := range order {
+            if _, err := io.WriteString(w, traceToDOT(byTrace[id])); err != nil {
+                return err
+            }
+        }
+        return nil
+    case "deps":
+        data, err := json.MarshalIndent(dependencyEdges(spans), "", "  ")
+        if err != nil {
+            return err
+        }
+        _, err = fmt.Fprintf(w, "%s\n", data)
+        return err
+    }
+    return fmt.Errorf("unknown report %q", report)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+    "math/rand/v2"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// Context carrying the request ID that requestIDGenerator derives trace IDs
+// from
+func contextWithRequestID(ctx context.Context, requestID string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// ID generator deriving the trace ID of a new root span from a hash of the
+// request ID in its context, so the same request always gets the same trace
+// ID. Without a request ID, and for all span IDs, the fallback is used.
+type requestIDGenerator struct {
+    fallback trace.IDGenerator
+}
+
+func newRequestIDGenerator(fallback trace.IDGenerator) requestIDGenerator {
+    if fallback == nil {
+        fallback = randomIDGenerator{}
+    }
+    return requestIDGenerator{fallback: fallback}
+}
+
+func (g requestIDGenerator) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+    requestID, _ := ctx.Value(requestIDKey{}).(string)
+    if requestID == "" {
+        return g.fallback.NewIDs(ctx)
+    }
+    tid := requestTraceID(requestID)
+    return tid, g.fallback.NewSpanID(ctx, tid)
+}
+
+func (g requestIDGenerator) NewSpanID(ctx context.Context, traceID oteltrace.TraceID) oteltrace.SpanID {
+    return g.fallback.NewSpanID(ctx, traceID)
+}
+
+// First 16 bytes of the SHA-256 of the request ID
+func requestTraceID(requestID string) oteltrace.TraceID {
+    sum := sha256.Sum256([]byte(requestID))
+    var tid oteltrace.TraceID
+    copy(tid[:], sum[:])
+    if !tid.IsValid() {
+        tid[len(tid)-1] = 1
+    }
+    return tid
+}
+
+// Random IDs, as generated by the SDK by default
+type randomIDGenerator struct{}
+
+func (randomIDGenerator) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+    var tid oteltrace.TraceID
+    for !tid.IsValid() {
+        binary.BigEndian.PutUint64(tid[:8], rand.Uint64())
+        binary.BigEndian.PutUint64(tid[8:], rand.Uint64())
+    }
+    return tid, randomIDGenerator{}.NewSpanID(ctx, tid)
+}
+
+func (randomIDGenerator) NewSpanID(context.Context, oteltrace.TraceID) oteltrace.SpanID {
+    var sid oteltrace.SpanID
+    for !sid.IsValid() {
+        binary.BigEndian.PutUint64(sid[:], rand.Uint64())
+    }
+    return sid
+}
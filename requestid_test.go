@@ -0,0 +1,42 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func startWithRequestID(tracer oteltrace.Tracer, requestID string) oteltrace.SpanContext {
+    ctx := context.Background()
+    if requestID != "" {
+        ctx = contextWithRequestID(ctx, requestID)
+    }
+    _, span := tracer.Start(ctx, "request")
+    span.End()
+    return span.SpanContext()
+}
+
+func TestSameRequestIDYieldsSameTraceID(t *testing.T) {
+    tracer := trace.NewTracerProvider(trace.WithIDGenerator(newRequestIDGenerator(nil))).Tracer("test")
+
+    first := startWithRequestID(tracer, "req-123")
+    second := startWithRequestID(tracer, "req-123")
+    other := startWithRequestID(tracer, "req-456")
+    if first.TraceID() != second.TraceID() || first.TraceID() != requestTraceID("req-123") {
+        t.Errorf("trace IDs %s and %s differ for the same request ID", first.TraceID(), second.TraceID())
+    }
+    if first.SpanID() == second.SpanID() {
+        t.Error("span IDs repeat for the same request ID")
+    }
+    if other.TraceID() == first.TraceID() {
+        t.Error("different request IDs share a trace ID")
+    }
+
+    // Without a request ID trace IDs are random
+    a, b := startWithRequestID(tracer, ""), startWithRequestID(tracer, "")
+    if !a.TraceID().IsValid() || a.TraceID() == b.TraceID() {
+        t.Errorf("fallback trace IDs %s and %s", a.TraceID(), b.TraceID())
+    }
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "regexp"
+    "runtime/debug"
+    "sort"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+const sdkModulePath = "go.opentelemetry.io/otel/sdk"
+
+const defaultServiceName = "web-backend"
+
+// Minimal resource used when building the detected resource fails, so the
+// tracer provider can always be initialized
+var fallbackResource = resource.NewSchemaless(
+    attribute.String("service.name", defaultServiceName),
+)
+
+// Resource to use once detection is done. A partial resource, missing only
+// what the failed detectors would have added, is kept; any other failure
+// falls back to fallbackResource.
+func detectedOrFallback(res *resource.Resource, err error) *resource.Resource {
+    switch {
+    case err == nil:
+        return res
+    case errors.Is(err, resource.ErrPartialResource) && res != nil:
+        log.Printf("Resource detection incomplete, keeping the detected attributes: %v", err)
+        return res
+    }
+    log.Printf("Resource detection failed, using fallback resource: %v", err)
+    return fallbackResource
+}
+
+// Default maximum length in bytes of a resource attribute value
+const defaultResourceValueLimit = 2048
+
+// Default retries of a failed resource detection, and the delay before the
+// first retry (doubled for each later one)
+const (
+    defaultResourceDetectionRetries = 2
+    defaultResourceDetectionBackoff = 100 * time.Millisecond
+)
+
+// Run detect, retrying up to retries times with exponential backoff while it
+// fails, e.g. on a cloud metadata endpoint that is still starting. The last
+// error is returned once retries are exhausted or ctx is done.
+func detectResourceWithRetry(ctx context.Context, retries int, backoff time.Duration, detect func(context.Context) (*resource.Resource, error)) (*resource.Resource, error) {
+    res, err := detect(ctx)
+    for attempt := 1; err != nil && attempt <= retries; attempt++ {
+        log.Printf("Resource detection failed (attempt %d of %d), retrying in %s: %v", attempt, retries+1, backoff, err)
+        select {
+        case <-time.After(backoff):
+        case <-ctx.Done():
+            return res, err
+        }
+        backoff *= 2
+        res, err = detect(ctx)
+    }
+    return res, err
+}
+
+// host.* attributes for the detected values, skipping any that are empty
+func hostAttributes(hostname, ipAddress, macAddress string) []attribute.KeyValue {
+    var attrs []attribute.KeyValue
+    if hostname != "" {
+        attrs = append(attrs, attribute.String("host.name", hostname))
+    }
+    if ipAddress != "" {
+        attrs = append(attrs, attribute.String("host.ip", ipAddress))
+    }
+    if macAddress != "" {
+        attrs = append(attrs, attribute.String("host.mac", macAddress))
+    }
+    return attrs
+}
+
+// Hostname naming scheme with {name} placeholders, e.g.
+// "{role}-{}.{region}.{env}" turns web-01.us-east.prod.example.com into
+// host.role=web, host.region=us-east and host.env=prod; unnamed placeholders
+// are matched but not recorded, and any domain after the template is
+// ignored. A placeholder never spans a dot.
+type hostnameTemplate struct {
+    template string
+    re       *regexp.Regexp
+    names    []string
+}
+
+func parseHostnameTemplate(template string) (*hostnameTemplate, error) {
+    var pattern strings.Builder
+    var names []string
+    pattern.WriteString("^")
+    for rest := template; rest != ""; {
+        open := strings.IndexByte(rest, '{')
+        if open < 0 {
+            pattern.WriteString(regexp.QuoteMeta(rest))
+            break
+        }
+        end := strings.IndexByte(rest[open:], '}')
+        if end < 0 {
+            return nil, fmt.Errorf("unclosed placeholder in hostname template %q", template)
+        }
+        name := strings.TrimSpace(rest[open+1 : open+end])
+        if strings.ContainsRune(name, '{') {
+            return nil, fmt.Errorf("nested placeholder in hostname template %q", template)
+        }
+        pattern.WriteString(regexp.QuoteMeta(rest[:open]))
+        pattern.WriteString("([^.]+?)")
+        names = append(names, name)
+        rest = rest[open+end+1:]
+    }
+    pattern.WriteString(`(?:\.|$)`)
+
+    re, err := regexp.Compile(pattern.String())
+    if err != nil {
+        return nil, fmt.Errorf("invalid hostname template %q: %w", template, err)
+    }
+    return &hostnameTemplate{template: template, re: re, names: names}, nil
+}
+
+// host.* attributes from the labels of hostname
+func (t *hostnameTemplate) labels(hostname string) ([]attribute.KeyValue, error) {
+    m := t.re.FindStringSubmatch(hostname)
+    if m == nil {
+        return nil, fmt.Errorf("hostname %q does not match template %q", hostname, t.template)
+    }
+    var attrs []attribute.KeyValue
+    for i, name := range t.names {
+        if name != "" {
+            attrs = append(attrs, attribute.String("host."+name, m[i+1]))
+        }
+    }
+    return attrs, nil
+}
+
+// Standard telemetry.sdk.* resource attributes, using the SDK version
+// recorded in the build info when available
+func sdkResourceAttributes() []attribute.KeyValue {
+    return []attribute.KeyValue{
+        attribute.String("telemetry.sdk.name", "opentelemetry"),
+        attribute.String("telemetry.sdk.language", "go"),
+        attribute.String("telemetry.sdk.version", sdkVersion()),
+    }
+}
+
+func sdkVersion() string {
+    if info, ok := debug.ReadBuildInfo(); ok {
+        for _, dep := range info.Deps {
+            if dep.Path != sdkModulePath {
+                continue
+            }
+            if dep.Replace != nil && dep.Replace.Version != "" {
+                return dep.Replace.Version
+            }
+            if dep.Version != "" && dep.Version != "(devel)" {
+                return dep.Version
+            }
+        }
+    }
+    return sdk.Version()
+}
+
+// Merge resource attributes from several sources into one deduplicated list
+// sorted by key. Sources are given in ascending precedence, so a key set by a
+// later source overrides earlier ones; callers pass them as
+// detected, config, env, flags so that flags > env > config > detected.
+func mergeResourceAttributes(sources ...[]attribute.KeyValue) []attribute.KeyValue {
+    merged := make(map[attribute.Key]attribute.KeyValue)
+    for _, source := range sources {
+        for _, kv := range source {
+            merged[kv.Key] = kv
+        }
+    }
+
+    out := make([]attribute.KeyValue, 0, len(merged))
+    for _, kv := range merged {
+        out = append(out, kv)
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+    return out
+}
+
+// Cut string resource attribute values longer than limit bytes, marked like
+// other truncated values, so backends don't reject the resource, logging
+// each truncation. A limit of zero or less leaves values untouched.
+func limitResourceAttributes(attrs []attribute.KeyValue, limit int) []attribute.KeyValue {
+    if limit <= 0 {
+        return attrs
+    }
+    out := make([]attribute.KeyValue, len(attrs))
+    for i, kv := range attrs {
+        if kv.Value.Type() == attribute.STRING && len(kv.Value.AsString()) > limit {
+            log.Printf("Truncating resource attribute %s from %d to %d bytes", kv.Key, len(kv.Value.AsString()), limit)
+            kv = kv.Key.String(truncateEndString(kv.Value.AsString(), limit))
+        }
+        out[i] = kv
+    }
+    return out
+}
+
+// Copy of res without the attributes whose key is in keys, keeping its
+// schema URL
+func dropResourceKeys(res *resource.Resource, keys []string) *resource.Resource {
+    if len(keys) == 0 || res == nil {
+        return res
+    }
+    drop := make(map[attribute.Key]bool, len(keys))
+    for _, k := range keys {
+        drop[attribute.Key(k)] = true
+    }
+    kept, _ := res.Set().Filter(func(kv attribute.KeyValue) bool { return !drop[kv.Key] })
+    return resource.NewWithAttributes(res.SchemaURL(), kept.ToSlice()...)
+}
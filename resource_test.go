@@ -0,0 +1,206 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestHostnameTemplateLabels(t *testing.T) {
+    template, err := parseHostnameTemplate("{role}-{}.{region}.{env}")
+    if err != nil {
+        t.Fatal(err)
+    }
+    labels, err := template.labels("web-01.us-east.prod.example.com")
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []attribute.KeyValue{
+        attribute.String("host.role", "web"),
+        attribute.String("host.region", "us-east"),
+        attribute.String("host.env", "prod"),
+    }
+    if len(labels) != len(want) {
+        t.Fatalf("labels = %v, want %v", labels, want)
+    }
+    for i := range want {
+        if labels[i] != want[i] {
+            t.Errorf("label %d = %v, want %v", i, labels[i], want[i])
+        }
+    }
+    if _, err := template.labels("localhost"); err == nil {
+        t.Error("non-matching hostname accepted")
+    }
+}
+
+func TestParseHostnameTemplateRejectsUnclosedPlaceholder(t *testing.T) {
+    if _, err := parseHostnameTemplate("{role-{}.{region}"); err == nil {
+        t.Error("no error")
+    }
+    if _, err := parseHostnameTemplate("{role"); err == nil {
+        t.Error("no error")
+    }
+}
+
+// Detector finding only some of its attributes
+type partialDetector struct{}
+
+func (partialDetector) Detect(context.Context) (*resource.Resource, error) {
+    return resource.NewSchemaless(attribute.String("cloud.region", "us-east-1")),
+        fmt.Errorf("%w: instance ID unavailable", resource.ErrPartialResource)
+}
+
+func TestDetectedOrFallbackKeepsPartialResource(t *testing.T) {
+    res, err := resource.New(context.Background(),
+        resource.WithDetectors(partialDetector{}),
+        resource.WithAttributes(attribute.String("service.name", "api")))
+    if !errors.Is(err, resource.ErrPartialResource) {
+        t.Fatalf("err = %v, want a partial resource", err)
+    }
+    res = detectedOrFallback(res, err)
+    if v, ok := res.Set().Value("cloud.region"); !ok || v.AsString() != "us-east-1" {
+        t.Errorf("partial resource dropped: %v", res)
+    }
+
+    if got := detectedOrFallback(nil, errors.New("detector crashed")); got != fallbackResource {
+        t.Errorf("failed detection kept %v", got)
+    }
+}
+
+func TestDetectResourceWithRetry(t *testing.T) {
+    calls := 0
+    res, err := detectResourceWithRetry(context.Background(), 2, time.Millisecond, func(context.Context) (*resource.Resource, error) {
+        calls++
+        if calls == 1 {
+            return nil, errors.New("metadata endpoint not ready")
+        }
+        return fallbackResource, nil
+    })
+    if err != nil || res != fallbackResource {
+        t.Fatalf("res, err = %v, %v", res, err)
+    }
+    if calls != 2 {
+        t.Errorf("detect called %d times, want 2", calls)
+    }
+}
+
+func TestSDKResourceAttributes(t *testing.T) {
+    set := attribute.NewSet(sdkResourceAttributes()...)
+    for key, want := range map[string]string{"telemetry.sdk.name": "opentelemetry", "telemetry.sdk.language": "go"} {
+        if v, _ := set.Value(attribute.Key(key)); v.AsString() != want {
+            t.Errorf("%s = %q, want %q", key, v.AsString(), want)
+        }
+    }
+    v, ok := set.Value("telemetry.sdk.version")
+    if !ok || strings.TrimPrefix(v.AsString(), "v") != sdk.Version() {
+        t.Errorf("telemetry.sdk.version = %q, want the SDK version %s", v.AsString(), sdk.Version())
+    }
+}
+
+func TestMergeResourceAttributesPrecedence(t *testing.T) {
+    detected := []attribute.KeyValue{attribute.String("host.name", "detected-host"), attribute.String("service.name", "detected"), attribute.String("os.type", "linux")}
+    fromConfig := []attribute.KeyValue{attribute.String("service.name", "config"), attribute.String("region", "eu")}
+    fromEnv := []attribute.KeyValue{attribute.String("service.name", "env"), attribute.String("host.name", "env-host")}
+    fromFlags := []attribute.KeyValue{attribute.String("service.name", "flags")}
+
+    got := mergeResourceAttributes(detected, fromConfig, fromEnv, fromFlags)
+    want := []attribute.KeyValue{
+        attribute.String("host.name", "env-host"),
+        attribute.String("os.type", "linux"),
+        attribute.String("region", "eu"),
+        attribute.String("service.name", "flags"),
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("mergeResourceAttributes = %v, want %v", got, want)
+    }
+}
+
+func TestLimitResourceAttributesTruncatesOversizedValues(t *testing.T) {
+    var logged bytes.Buffer
+    saved := log.Writer()
+    log.SetOutput(&logged)
+    defer log.SetOutput(saved)
+
+    attrs := []attribute.KeyValue{
+        attribute.String("service.name", "web"),
+        attribute.String("process.command_line", strings.Repeat("x", 300)),
+        attribute.String("host.name", "hhhhhé-wörld-example"),
+        attribute.Int("process.pid", 123456789),
+    }
+    got := limitResourceAttributes(attrs, 20)
+    want := []attribute.KeyValue{
+        attribute.String("service.name", "web"),
+        attribute.String("process.command_line", "xxxxxx"+truncatedMarker),
+        // Cut on a rune boundary, marker included
+        attribute.String("host.name", "hhhhh"+truncatedMarker),
+        attribute.Int("process.pid", 123456789),
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("limitResourceAttributes = %v, want %v", got, want)
+    }
+    if !strings.Contains(logged.String(), "Truncating resource attribute process.command_line from 300 to 20 bytes") {
+        t.Errorf("truncation not logged: %q", logged.String())
+    }
+    if got := limitResourceAttributes(attrs, 0); !reflect.DeepEqual(got, attrs) {
+        t.Error("a zero limit changed the attributes")
+    }
+}
+
+func TestDetectResourceWithRetryGivesUp(t *testing.T) {
+    var logged bytes.Buffer
+    saved := log.Writer()
+    log.SetOutput(&logged)
+    defer log.SetOutput(saved)
+
+    calls := 0
+    failing := func(context.Context) (*resource.Resource, error) {
+        calls++
+        return nil, fmt.Errorf("attempt %d failed", calls)
+    }
+    _, err := detectResourceWithRetry(context.Background(), 2, time.Millisecond, failing)
+    if err == nil || err.Error() != "attempt 3 failed" || calls != 3 {
+        t.Errorf("after %d calls err = %v, want the third attempt's error", calls, err)
+    }
+    // The backoff doubles between attempts
+    if !strings.Contains(logged.String(), "retrying in 1ms") || !strings.Contains(logged.String(), "retrying in 2ms") {
+        t.Errorf("retry log = %q", logged.String())
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    calls = 0
+    if _, err := detectResourceWithRetry(ctx, 5, time.Hour, failing); err == nil || calls != 1 {
+        t.Errorf("cancelled detection made %d calls, err %v; want 1 and an error", calls, err)
+    }
+}
+
+func TestDropResourceKeys(t *testing.T) {
+    res := resource.NewWithAttributes("https://opentelemetry.io/schemas/1.26.0",
+        attribute.String("service.name", "web"),
+        attribute.String("host.mac", "02:42:ac:11:00:02"),
+        attribute.String("process.command_line", "/app --token=secret"))
+    got := dropResourceKeys(res, []string{"host.mac", "process.command_line", "not.present"})
+
+    if got.Set().HasValue("host.mac") || got.Set().HasValue("process.command_line") {
+        t.Errorf("dropped keys still present: %v", got.Attributes())
+    }
+    if v, _ := got.Set().Value("service.name"); v.AsString() != "web" || got.Len() != 1 {
+        t.Errorf("kept attributes = %v, want only service.name", got.Attributes())
+    }
+    if got.SchemaURL() != res.SchemaURL() {
+        t.Errorf("schema URL = %q, want %q", got.SchemaURL(), res.SchemaURL())
+    }
+    if dropResourceKeys(res, nil) != res {
+        t.Error("no keys to drop should return the resource unchanged")
+    }
+}
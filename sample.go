@@ -0,0 +1,128 @@
+package main
+
+import (
+    "fmt"
+    "math/rand"
+    "time"
+)
+
+type sampleConfig struct {
+    seed      int64
+    errorRate float64
+    start     time.Time
+}
+
+// Option for GenerateSampleEntries
+type SampleOption func(*sampleConfig)
+
+// Seed the random generator; the same seed always yields the same entries
+func WithSeed(seed int64) SampleOption {
+    return func(c *sampleConfig) { c.seed = seed }
+}
+
+// Fraction of generated entries (0 to 1) that represent failed requests
+func WithErrorRate(rate float64) SampleOption {
+    return func(c *sampleConfig) { c.errorRate = rate }
+}
+
+// Timestamp of the first generated entry
+func WithStartTime(t time.Time) SampleOption {
+    return func(c *sampleConfig) { c.start = t }
+}
+
+var (
+    sampleMethods      = []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
+    samplePaths        = []string{"/", "/login", "/api/orders", "/api/users", "/api/cart", "/healthz"}
+    sampleOperations   = []string{"SELECT", "INSERT", "UPDATE", "DELETE"}
+    sampleServices     = []string{"web-backend", "checkout", "auth"}
+    sampleSuccessCodes = []string{"200", "200", "201", "204"}
+    sampleFailures     = []struct {
+        status, exceptionType, message string
+    }{
+        {"500", "DatabaseError", "Database connection failed"},
+        {"504", "TimeoutError", "Upstream request timed out"},
+        {"400", "ValidationError", "Invalid request payload"},
+        {"503", "NetworkError", "Connection refused by upstream"},
+    }
+)
+
+// Generate n realistic randomized log entries for load testing and demos
+func GenerateSampleEntries(n int, opts ...SampleOption) []LogEntry {
+    cfg := sampleConfig{
+        seed:      1,
+        errorRate: 0.1,
+        start:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+    }
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    rng := rand.New(rand.NewSource(cfg.seed))
+
+    entries := make([]LogEntry, 0, n)
+    ts := cfg.start
+    for i := 0; i < n; i++ {
+        ts = ts.Add(time.Duration(rng.Intn(1000)) * time.Millisecond)
+        duration := time.Duration(1+rng.Intn(500)) * time.Millisecond
+        method := sampleMethods[rng.Intn(len(sampleMethods))]
+        path := samplePaths[rng.Intn(len(samplePaths))]
+        hostIndex := rng.Intn(8)
+
+        entry := LogEntry{
+            Timestamp:         formatTimestamp(ts),
+            ObservedTimestamp: formatTimestamp(ts.Add(duration)),
+            TraceID:           fmt.Sprintf("%016x%016x", rng.Uint64(), rng.Uint64()),
+            SpanID:            fmt.Sprintf("%016x", rng.Uint64()),
+            Resource: map[string]string{
+                "service.name": sampleServices[rng.Intn(len(sampleServices))],
+                "host.name":    fmt.Sprintf("web-%02d", hostIndex),
+            },
+            InstrumentationScope: map[string]string{
+                "Name":    "GoLogger",
+                "Version": "1.0.0",
+            },
+            Attributes: map[string]string{
+                "http.method": method,
+                "http.url":    "http://example.com" + path,
+            },
+            EventData:  map[string]string{},
+            Exception:  map[string]string{},
+            Duration:   duration.String(),
+            Hostname:   fmt.Sprintf("web-%02d", hostIndex),
+            IPAddress:  fmt.Sprintf("10.0.0.%d", 10+hostIndex),
+            MacAddress: fmt.Sprintf("02:00:00:00:00:%02x", hostIndex),
+        }
+        if path != "/healthz" && rng.Intn(2) == 0 {
+            entry.Attributes["db.operation"] = sampleOperations[rng.Intn(len(sampleOperations))]
+        }
+
+        if rng.Float64() < cfg.errorRate {
+            failure := sampleFailures[rng.Intn(len(sampleFailures))]
+            entry.SeverityText = "ERROR"
+            entry.SeverityNumber = "17"
+            entry.LogLevel = "error"
+            entry.Status = "failed"
+            entry.Body = "An error occurred while processing the request."
+            entry.Attributes["http.status_code"] = failure.status
+            entry.EventData["event.name"] = "request_error"
+            entry.EventData["event.type"] = "error"
+            entry.Exception["exception.type"] = failure.exceptionType
+            entry.Exception["exception.message"] = failure.message
+        } else {
+            switch level := rng.Intn(10); {
+            case level == 0:
+                entry.SeverityText, entry.SeverityNumber, entry.LogLevel = "DEBUG", "5", "debug"
+            case level < 3:
+                entry.SeverityText, entry.SeverityNumber, entry.LogLevel = "WARN", "13", "warn"
+            default:
+                entry.SeverityText, entry.SeverityNumber, entry.LogLevel = "INFO", "9", "info"
+            }
+            entry.Status = "success"
+            entry.Body = "Request processed successfully."
+            entry.Attributes["http.status_code"] = sampleSuccessCodes[rng.Intn(len(sampleSuccessCodes))]
+            entry.EventData["event.name"] = "request_complete"
+            entry.EventData["event.type"] = "info"
+        }
+        entries = append(entries, entry)
+    }
+    return entries
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestGenerateSampleEntriesValidate(t *testing.T) {
+    entries := GenerateSampleEntries(200, WithSeed(7), WithErrorRate(0.25))
+    if len(entries) != 200 {
+        t.Fatalf("generated %d entries", len(entries))
+    }
+    failed := 0
+    for i, l := range entries {
+        if err := l.Validate(); err != nil {
+            t.Errorf("entry %d: %v", i, err)
+        }
+        if l.Status == "failed" {
+            failed++
+        }
+    }
+    if failed < 30 || failed > 70 {
+        t.Errorf("%d of 200 entries failed, want about 50 at an error rate of 0.25", failed)
+    }
+}
+
+func TestGenerateSampleEntriesSeeded(t *testing.T) {
+    if !reflect.DeepEqual(GenerateSampleEntries(20, WithSeed(3)), GenerateSampleEntries(20, WithSeed(3))) {
+        t.Error("the same seed generated different entries")
+    }
+    if reflect.DeepEqual(GenerateSampleEntries(20, WithSeed(3)), GenerateSampleEntries(20, WithSeed(4))) {
+        t.Error("different seeds generated the same entries")
+    }
+    for _, l := range GenerateSampleEntries(50, WithErrorRate(0)) {
+        if l.Status == "failed" {
+            t.Fatal("failed entry at an error rate of 0")
+        }
+    }
+}
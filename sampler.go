@@ -0,0 +1,273 @@
+package main
+
+import (
+    "encoding/binary"
+    "fmt"
+    "hash/fnv"
+    "math"
+    "strconv"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Trace ID ratio sampler whose ratio can be changed while the provider runs
+type dynamicRatioSampler struct {
+    bits atomic.Uint64
+}
+
+// Sampler installed on the tracer provider; SetSamplingRatio updates it
+var activeSampler = newDynamicRatioSampler(1)
+
+func newDynamicRatioSampler(ratio float64) *dynamicRatioSampler {
+    s := &dynamicRatioSampler{}
+    s.bits.Store(math.Float64bits(clampRatio(ratio)))
+    return s
+}
+
+// Change the sampling ratio for spans started from now on
+func SetSamplingRatio(ratio float64) error {
+    return activeSampler.SetRatio(ratio)
+}
+
+func (s *dynamicRatioSampler) SetRatio(ratio float64) error {
+    if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+        return fmt.Errorf("sampling ratio %v out of range [0, 1]", ratio)
+    }
+    s.bits.Store(math.Float64bits(ratio))
+    return nil
+}
+
+func (s *dynamicRatioSampler) Ratio() float64 {
+    return math.Float64frombits(s.bits.Load())
+}
+
+// Same decision as trace.TraceIDRatioBased, against the current ratio
+func (s *dynamicRatioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    psc := oteltrace.SpanContextFromContext(p.ParentContext)
+    if traceIDSampled(p.TraceID, s.Ratio()) {
+        return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+    }
+    return trace.SamplingResult{Decision: trace.Drop, Tracestate: psc.TraceState()}
+}
+
+// Report whether the low bits of the trace ID fall below the ratio
+func traceIDSampled(traceID oteltrace.TraceID, ratio float64) bool {
+    return binary.BigEndian.Uint64(traceID[8:16])>>1 < uint64(ratio*(1<<63))
+}
+
+func (s *dynamicRatioSampler) Description() string {
+    return fmt.Sprintf("DynamicRatioBased{%g}", s.Ratio())
+}
+
+func clampRatio(ratio float64) float64 {
+    if math.IsNaN(ratio) || ratio < 0 {
+        return 0
+    }
+    if ratio > 1 {
+        return 1
+    }
+    return ratio
+}
+
+// Sampler deciding on a hash of the request.id attribute instead of the
+// trace ID, so a request is sampled the same way in every service and trace
+// it appears in. Spans without a request.id use the fallback sampler.
+type requestIDSampler struct {
+    ratio    func() float64
+    fallback trace.Sampler
+}
+
+func newRequestIDSampler(ratio func() float64, fallback trace.Sampler) *requestIDSampler {
+    return &requestIDSampler{ratio: ratio, fallback: fallback}
+}
+
+func (s *requestIDSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    var requestID string
+    for _, kv := range p.Attributes {
+        if kv.Key == "request.id" {
+            requestID = kv.Value.Emit()
+            break
+        }
+    }
+    if requestID == "" {
+        return s.fallback.ShouldSample(p)
+    }
+
+    psc := oteltrace.SpanContextFromContext(p.ParentContext)
+    if requestIDSampled(requestID, s.ratio()) {
+        return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+    }
+    return trace.SamplingResult{Decision: trace.Drop, Tracestate: psc.TraceState()}
+}
+
+func (s *requestIDSampler) Description() string {
+    return fmt.Sprintf("RequestIDHash{%g}/%s", s.ratio(), s.fallback.Description())
+}
+
+// Report whether the FNV-1a hash of the request ID falls below the ratio
+func requestIDSampled(requestID string, ratio float64) bool {
+    h := fnv.New64a()
+    h.Write([]byte(requestID))
+    return h.Sum64()>>1 < uint64(ratio*(1<<63))
+}
+
+const defaultSamplingAdjustInterval = time.Second
+
+// Sampler targeting a fixed number of sampled traces per second. Each
+// interval an adaptive factor is set to target/expected throughput and
+// multiplied with the configured ratio, so the effective ratio never exceeds
+// the configured one, which is left untouched. A token bucket refilled at the
+// target rate (holding at most one second's worth) caps bursts arriving
+// before the factor catches up.
+type adaptiveRateSampler struct {
+    target   float64
+    interval time.Duration
+    ratio    *dynamicRatioSampler
+
+    mu          sync.Mutex
+    windowStart time.Time
+    seen        int
+    admitted    int
+    passed      int
+    factor      float64
+    passRate    float64
+    tokens      float64
+    lastRefill  time.Time
+}
+
+func newAdaptiveRateSampler(target float64, interval time.Duration, ratio *dynamicRatioSampler) *adaptiveRateSampler {
+    if interval <= 0 {
+        interval = defaultSamplingAdjustInterval
+    }
+    now := time.Now()
+    return &adaptiveRateSampler{
+        target:      target,
+        interval:    interval,
+        ratio:       ratio,
+        windowStart: now,
+        factor:      1,
+        passRate:    1,
+        tokens:      target,
+        lastRefill:  now,
+    }
+}
+
+func (s *adaptiveRateSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    now := time.Now()
+    psc := oteltrace.SpanContextFromContext(p.ParentContext)
+    drop := trace.SamplingResult{Decision: trace.Drop, Tracestate: psc.TraceState()}
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.tokens = math.Min(s.target, s.tokens+now.Sub(s.lastRefill).Seconds()*s.target)
+    s.lastRefill = now
+    s.seen++
+    if elapsed := now.Sub(s.windowStart); elapsed >= s.interval {
+        // Sampled throughput the configured ratio alone would give
+        expected := float64(s.seen) / elapsed.Seconds() * s.ratio.Ratio()
+        s.factor = 1
+        if expected > 0 {
+            s.factor = clampRatio(s.target / expected)
+        }
+        s.passRate = 1
+        if s.admitted > 0 {
+            s.passRate = float64(s.passed) / float64(s.admitted)
+        }
+        s.windowStart = now
+        s.seen, s.admitted, s.passed = 0, 0, 0
+    }
+
+    if !traceIDSampled(p.TraceID, s.ratio.Ratio()*s.factor) {
+        return drop
+    }
+    s.admitted++
+    if s.tokens < 1 {
+        return drop
+    }
+    s.tokens--
+    s.passed++
+    return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+}
+
+// Probability a span is kept: the configured ratio times the adaptive factor
+// and the share of spans the token bucket let through in the last interval
+func (s *adaptiveRateSampler) Ratio() float64 {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.ratio.Ratio() * s.factor * s.passRate
+}
+
+func (s *adaptiveRateSampler) Description() string {
+    return fmt.Sprintf("AdaptiveRate{%g/s}/%s", s.target, s.ratio.Description())
+}
+
+// Sampler keeping spans started with a positive sampling.priority attribute
+// (the OpenTracing debug flag) whatever the wrapped sampler decides, so they
+// are exported even under a never-sample configuration. It wraps the
+// parent-based sampler, letting a flagged child of a dropped parent through.
+type prioritySampler struct {
+    next trace.Sampler
+}
+
+func newPrioritySampler(next trace.Sampler) *prioritySampler {
+    return &prioritySampler{next: next}
+}
+
+func (s *prioritySampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    for _, kv := range p.Attributes {
+        if kv.Key != "sampling.priority" {
+            continue
+        }
+        if priority, err := strconv.ParseFloat(kv.Value.Emit(), 64); err == nil && priority > 0 {
+            psc := oteltrace.SpanContextFromContext(p.ParentContext)
+            return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+        }
+        break
+    }
+    return s.next.ShouldSample(p)
+}
+
+func (s *prioritySampler) Description() string {
+    return fmt.Sprintf("SamplingPriority/%s", s.next.Description())
+}
+
+// Sampler attaching sampling.adjusted_count, the number of spans each
+// sampled one stands for (1/ratio at the current sampling ratio), so
+// backends can extrapolate true volumes from sampled data.
+type adjustedCountSampler struct {
+    next  trace.Sampler
+    ratio func() float64
+}
+
+func newAdjustedCountSampler(next trace.Sampler, ratio func() float64) *adjustedCountSampler {
+    return &adjustedCountSampler{next: next, ratio: ratio}
+}
+
+func (s *adjustedCountSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+    result := s.next.ShouldSample(p)
+    if result.Decision == trace.RecordAndSample {
+        if count := adjustedCount(s.ratio()); count > 0 {
+            result.Attributes = append(result.Attributes, attribute.Float64("sampling.adjusted_count", count))
+        }
+    }
+    return result
+}
+
+func (s *adjustedCountSampler) Description() string {
+    return fmt.Sprintf("AdjustedCount/%s", s.next.Description())
+}
+
+// Spans represented by one sampled span at ratio; zero when nothing is
+// sampled
+func adjustedCount(ratio float64) float64 {
+    if ratio <= 0 {
+        return 0
+    }
+    return 1 / ratio
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+    "context"
+    "crypto/rand"
+    "fmt"
+    "math"
+    "reflect"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func randomSamplingParameters() trace.SamplingParameters {
+    var tid oteltrace.TraceID
+    _, _ = rand.Read(tid[:])
+    return trace.SamplingParameters{TraceID: tid, Name: "span"}
+}
+
+func TestAdaptiveRateSamplerKeepsConfiguredRatio(t *testing.T) {
+    configured := newDynamicRatioSampler(0.5)
+    s := newAdaptiveRateSampler(10, 10*time.Millisecond, configured)
+    for i := 0; i < 2000; i++ {
+        s.ShouldSample(randomSamplingParameters())
+    }
+    time.Sleep(15 * time.Millisecond)
+    s.ShouldSample(randomSamplingParameters())
+
+    if got := configured.Ratio(); got != 0.5 {
+        t.Errorf("configured ratio changed to %g, want 0.5", got)
+    }
+    if got := s.Ratio(); got <= 0 || got >= 0.5 {
+        t.Errorf("effective ratio %g, want within (0, 0.5) under load", got)
+    }
+    if got := adjustedCount(s.Ratio()); got <= 2 {
+        t.Errorf("adjusted count %g does not account for adaptive and token bucket drops", got)
+    }
+}
+
+func TestAdaptiveRateSamplerNeverExceedsConfiguredRatio(t *testing.T) {
+    configured := newDynamicRatioSampler(0)
+    s := newAdaptiveRateSampler(1000, time.Millisecond, configured)
+    time.Sleep(2 * time.Millisecond)
+    for i := 0; i < 100; i++ {
+        if s.ShouldSample(randomSamplingParameters()).Decision == trace.RecordAndSample {
+            t.Fatal("span sampled with a configured ratio of 0")
+        }
+    }
+}
+
+func TestSetSamplingRatioAppliesToLaterSpans(t *testing.T) {
+    saved := activeSampler.Ratio()
+    defer activeSampler.SetRatio(saved)
+    tracer := trace.NewTracerProvider(trace.WithSampler(activeSampler)).Tracer("test")
+    ctx := context.Background()
+
+    if err := SetSamplingRatio(0); err != nil {
+        t.Fatal(err)
+    }
+    _, dropped := tracer.Start(ctx, "before")
+    if err := SetSamplingRatio(1); err != nil {
+        t.Fatal(err)
+    }
+    _, kept := tracer.Start(ctx, "after")
+    if dropped.SpanContext().IsSampled() || !kept.SpanContext().IsSampled() {
+        t.Errorf("sampled before %v, after %v; want false then true", dropped.SpanContext().IsSampled(), kept.SpanContext().IsSampled())
+    }
+    dropped.End()
+    kept.End()
+
+    for _, ratio := range []float64{-0.1, 1.5, math.NaN()} {
+        if err := SetSamplingRatio(ratio); err == nil {
+            t.Errorf("SetSamplingRatio(%v) succeeded, want error", ratio)
+        }
+    }
+    if got := activeSampler.Ratio(); got != 1 {
+        t.Errorf("ratio after rejected updates = %v, want 1", got)
+    }
+}
+
+func TestRequestIDSamplerConsistentPerRequest(t *testing.T) {
+    s := newRequestIDSampler(func() float64 { return 0.5 }, trace.NeverSample())
+    decide := func(requestID string) trace.SamplingDecision {
+        p := randomSamplingParameters()
+        if requestID != "" {
+            p.Attributes = []attribute.KeyValue{attribute.String("request.id", requestID)}
+        }
+        return s.ShouldSample(p).Decision
+    }
+
+    sampled := 0
+    for i := 0; i < 200; i++ {
+        requestID := fmt.Sprintf("req-%d", i)
+        first := decide(requestID)
+        for j := 0; j < 5; j++ {
+            if got := decide(requestID); got != first {
+                t.Fatalf("%s: decision %v then %v across trace IDs", requestID, first, got)
+            }
+        }
+        if first == trace.RecordAndSample {
+            sampled++
+        }
+    }
+    if sampled < 60 || sampled > 140 {
+        t.Errorf("sampled %d of 200 request IDs at ratio 0.5", sampled)
+    }
+    if got := decide(""); got != trace.Drop {
+        t.Errorf("span without request.id: %v, want the fallback's Drop", got)
+    }
+}
+
+func TestPrioritySpanExportedUnderNeverSample(t *testing.T) {
+    exporter := tracetest.NewInMemoryExporter()
+    tp := trace.NewTracerProvider(
+        trace.WithSampler(newPrioritySampler(trace.ParentBased(trace.NeverSample()))),
+        trace.WithSyncer(exporter))
+    tracer := tp.Tracer("test")
+    ctx := context.Background()
+
+    start := func(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+        ctx, span := tracer.Start(ctx, name, oteltrace.WithAttributes(attrs...))
+        span.End()
+        return ctx
+    }
+    parent := start(ctx, "plain")
+    start(ctx, "priority", attribute.Int("sampling.priority", 1))
+    start(ctx, "zero priority", attribute.Int("sampling.priority", 0))
+    start(ctx, "bad priority", attribute.String("sampling.priority", "high"))
+    start(parent, "flagged child", attribute.String("sampling.priority", "1"))
+
+    var names []string
+    for _, s := range exporter.GetSpans() {
+        names = append(names, s.Name)
+    }
+    if want := []string{"priority", "flagged child"}; !reflect.DeepEqual(names, want) {
+        t.Errorf("exported %q, want %q", names, want)
+    }
+}
+
+func TestAdjustedCountAtRatio(t *testing.T) {
+    ratio := newDynamicRatioSampler(0.1)
+    exporter := tracetest.NewInMemoryExporter()
+    tp := trace.NewTracerProvider(
+        trace.WithSampler(newAdjustedCountSampler(trace.AlwaysSample(), ratio.Ratio)),
+        trace.WithSyncer(exporter))
+    _, span := tp.Tracer("test").Start(context.Background(), "op")
+    span.End()
+
+    spans := exporter.GetSpans().Snapshots()
+    if len(spans) != 1 {
+        t.Fatalf("exported %d spans, want 1", len(spans))
+    }
+    AssertSpanAttribute(t, spans[0], "sampling.adjusted_count", 10.0)
+
+    for r, want := range map[float64]float64{1: 1, 0.25: 4, 0: 0} {
+        if got := adjustedCount(r); got != want {
+            t.Errorf("adjustedCount(%v) = %v, want %v", r, got, want)
+        }
+    }
+
+    // Dropped spans carry nothing
+    dropped := newAdjustedCountSampler(trace.NeverSample(), ratio.Ratio).ShouldSample(randomSamplingParameters())
+    if len(dropped.Attributes) != 0 {
+        t.Errorf("dropped span attributes = %v", dropped.Attributes)
+    }
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+    "errors"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Keys of a LogEntry's InstrumentationScope map naming the scope itself;
+// every other key is a scope attribute
+const (
+    scopeNameKey      = "Name"
+    scopeVersionKey   = "Version"
+    scopeSchemaURLKey = "SchemaURL"
+)
+
+// Tracer for the entry's instrumentation scope, carrying its version, schema
+// URL and attributes, which are exported with the scope of its spans. The
+// scope name must be set.
+func entryScopeTracer(l LogEntry) (trace.Tracer, error) {
+    name := l.InstrumentationScope[scopeNameKey]
+    if name == "" {
+        return nil, errors.New("instrumentation scope has no Name")
+    }
+
+    var opts []trace.TracerOption
+    if version := l.InstrumentationScope[scopeVersionKey]; version != "" {
+        opts = append(opts, trace.WithInstrumentationVersion(version))
+    }
+    if schemaURL := l.InstrumentationScope[scopeSchemaURLKey]; schemaURL != "" {
+        opts = append(opts, trace.WithSchemaURL(schemaURL))
+    }
+    var attrs []attribute.KeyValue
+    for _, k := range sortedKeys(l.InstrumentationScope) {
+        switch k {
+        case scopeNameKey, scopeVersionKey, scopeSchemaURLKey:
+            continue
+        }
+        attrs = append(attrs, attribute.String(k, l.InstrumentationScope[k]))
+    }
+    if len(attrs) > 0 {
+        opts = append(opts, trace.WithInstrumentationAttributes(attrs...))
+    }
+    return otel.Tracer(name, opts...), nil
+}
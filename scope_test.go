@@ -0,0 +1,62 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEntryScopeAttributesExported(t *testing.T) {
+    recorder := tracetest.NewInMemoryExporter()
+    tp := trace.NewTracerProvider(trace.WithSyncer(recorder))
+    prev := otel.GetTracerProvider()
+    otel.SetTracerProvider(tp)
+    defer otel.SetTracerProvider(prev)
+
+    tracer, err := entryScopeTracer(LogEntry{InstrumentationScope: map[string]string{
+        "Name":      "GoLogger",
+        "Version":   "1.0.0",
+        "SchemaURL": "https://opentelemetry.io/schemas/1.24.0",
+        "team":      "payments",
+    }})
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, span := tracer.Start(context.Background(), "entry")
+    span.End()
+
+    spans := recorder.GetSpans().Snapshots()
+    if len(spans) != 1 {
+        t.Fatalf("got %d spans, want 1", len(spans))
+    }
+    scope := spans[0].InstrumentationScope()
+    if scope.Name != "GoLogger" || scope.Version != "1.0.0" || scope.SchemaURL != "https://opentelemetry.io/schemas/1.24.0" {
+        t.Errorf("scope %+v", scope)
+    }
+    if v, ok := scope.Attributes.Value("team"); !ok || v.AsString() != "payments" {
+        t.Errorf("scope attribute team = %q, %v; want payments", v.Emit(), ok)
+    }
+
+    // The attributes survive the protofile round trip
+    frame, err := marshalProtoFrame(spans)
+    if err != nil {
+        t.Fatal(err)
+    }
+    stubs, err := readProtoSpans(bytes.NewReader(frame))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := stubs[0].InstrumentationLibrary.Attributes; !got.Equals(&scope.Attributes) {
+        t.Errorf("read back scope attributes %v, want %v", got.ToSlice(), scope.Attributes.ToSlice())
+    }
+}
+
+func TestEntryScopeTracerRequiresName(t *testing.T) {
+    if _, err := entryScopeTracer(LogEntry{InstrumentationScope: map[string]string{"Version": "1"}}); err == nil {
+        t.Error("no error for a scope without Name")
+    }
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+    "fmt"
+
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Resource attribute keys defined by the OpenTelemetry semantic conventions
+var knownResourceKeys = map[string]bool{
+    "service.name":                true,
+    "service.namespace":           true,
+    "service.instance.id":         true,
+    "service.version":             true,
+    "telemetry.sdk.name":          true,
+    "telemetry.sdk.language":      true,
+    "telemetry.sdk.version":       true,
+    "telemetry.distro.name":       true,
+    "telemetry.distro.version":    true,
+    "deployment.environment.name": true,
+    "host.id":                     true,
+    "host.name":                   true,
+    "host.type":                   true,
+    "host.arch":                   true,
+    "host.image.name":             true,
+    "host.image.id":               true,
+    "host.image.version":          true,
+    "host.ip":                     true,
+    "host.mac":                    true,
+    "os.type":                     true,
+    "os.description":              true,
+    "os.name":                     true,
+    "os.version":                  true,
+    "os.build_id":                 true,
+    "process.pid":                 true,
+    "process.parent_pid":          true,
+    "process.executable.name":     true,
+    "process.executable.path":     true,
+    "process.command":             true,
+    "process.command_line":        true,
+    "process.command_args":        true,
+    "process.owner":               true,
+    "process.runtime.name":        true,
+    "process.runtime.version":     true,
+    "process.runtime.description": true,
+    "container.id":                true,
+    "container.name":              true,
+    "container.runtime":           true,
+    "container.image.name":        true,
+    "container.image.id":          true,
+    "container.image.tags":        true,
+    "k8s.cluster.name":            true,
+    "k8s.cluster.uid":             true,
+    "k8s.node.name":               true,
+    "k8s.node.uid":                true,
+    "k8s.namespace.name":          true,
+    "k8s.pod.name":                true,
+    "k8s.pod.uid":                 true,
+    "k8s.container.name":          true,
+    "k8s.deployment.name":         true,
+    "k8s.replicaset.name":         true,
+    "k8s.statefulset.name":        true,
+    "k8s.daemonset.name":          true,
+    "k8s.job.name":                true,
+    "k8s.cronjob.name":            true,
+    "cloud.provider":              true,
+    "cloud.account.id":            true,
+    "cloud.region":                true,
+    "cloud.availability_zone":     true,
+    "cloud.platform":              true,
+    "cloud.resource_id":           true,
+    "faas.name":                   true,
+    "faas.version":                true,
+    "faas.instance":               true,
+    "faas.max_memory":             true,
+}
+
+// Deprecated resource attribute keys and their replacements
+var deprecatedResourceKeys = map[string]string{
+    "deployment.environment": "deployment.environment.name",
+    "telemetry.auto.version": "telemetry.distro.version",
+    "container.image.tag":    "container.image.tags",
+    "faas.id":                "cloud.resource_id",
+}
+
+// Warnings for resource attributes that are deprecated or not defined by the
+// semantic conventions, in key order. They are advisory only.
+func validateResourceConventions(res *resource.Resource) []string {
+    if res == nil {
+        return nil
+    }
+    var warnings []string
+    for _, kv := range res.Attributes() {
+        key := string(kv.Key)
+        if replacement, ok := deprecatedResourceKeys[key]; ok {
+            warnings = append(warnings, fmt.Sprintf("resource attribute %q is deprecated, use %q", key, replacement))
+        } else if !knownResourceKeys[key] {
+            warnings = append(warnings, fmt.Sprintf("resource attribute %q is not a semantic convention", key))
+        }
+    }
+    return warnings
+}
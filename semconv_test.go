@@ -0,0 +1,33 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestValidateResourceConventions(t *testing.T) {
+    res := resource.NewSchemaless(
+        attribute.String("service.name", "otelprac2"),
+        attribute.String("host.name", "web-1"),
+        attribute.String("deployment.environment", "prod"),
+        attribute.String("team", "payments"),
+        attribute.String("k8s.pod.name", "web-1-abc"))
+    want := []string{
+        `resource attribute "deployment.environment" is deprecated, use "deployment.environment.name"`,
+        `resource attribute "team" is not a semantic convention`,
+    }
+    if got := validateResourceConventions(res); !reflect.DeepEqual(got, want) {
+        t.Errorf("validateResourceConventions = %q, want %q", got, want)
+    }
+
+    standard := resource.NewSchemaless(attribute.String("service.name", "otelprac2"))
+    if got := validateResourceConventions(standard); len(got) != 0 {
+        t.Errorf("warnings for a standard resource: %q", got)
+    }
+    if got := validateResourceConventions(nil); got != nil {
+        t.Errorf("warnings for a nil resource: %q", got)
+    }
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+    "context"
+    "sync/atomic"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter wrapper numbering every exported span with export.sequence,
+// starting at 1 and increasing across export calls, so consumers can spot
+// gaps or reordering in the stream. Calls are numbered in the order they
+// reach the wrapper.
+type sequenceExporter struct {
+    trace.SpanExporter
+    next atomic.Int64
+}
+
+func newSequenceExporter(exporter trace.SpanExporter) *sequenceExporter {
+    return &sequenceExporter{SpanExporter: exporter}
+}
+
+func (e *sequenceExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if len(spans) == 0 {
+        return nil
+    }
+    // Reserve the batch's range in one step so concurrent calls don't
+    // interleave numbers
+    first := e.next.Add(int64(len(spans))) - int64(len(spans)) + 1
+    numbered := make([]trace.ReadOnlySpan, len(spans))
+    for i, s := range spans {
+        numbered[i] = withAttributes(s, attribute.Int64("export.sequence", first+int64(i)))
+    }
+    return e.SpanExporter.ExportSpans(ctx, numbered)
+}
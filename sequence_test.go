@@ -0,0 +1,64 @@
+package main
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSequenceNumbersUniqueAndIncreasing(t *testing.T) {
+    inner := tracetest.NewInMemoryExporter()
+    e := newSequenceExporter(inner)
+    ctx := context.Background()
+    batch := tracetest.SpanStubs{{Name: "a"}, {Name: "b"}, {Name: "c"}}.Snapshots()
+
+    if err := e.ExportSpans(ctx, batch); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(ctx, nil); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(ctx, batch[:2]); err != nil {
+        t.Fatal(err)
+    }
+    var want int64
+    for _, s := range inner.GetSpans().Snapshots() {
+        want++
+        AssertSpanAttribute(t, s, "export.sequence", want)
+    }
+    if want != 5 {
+        t.Fatalf("exported %d spans, want 5", want)
+    }
+
+    // Concurrent batches get disjoint, gap-free ranges
+    inner.Reset()
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            e.ExportSpans(ctx, batch)
+        }()
+    }
+    wg.Wait()
+    var seqs []int64
+    for _, s := range inner.GetSpans().Snapshots() {
+        for _, kv := range s.Attributes() {
+            if kv.Key == "export.sequence" {
+                seqs = append(seqs, kv.Value.AsInt64())
+            }
+        }
+    }
+    sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+    if len(seqs) != 24 {
+        t.Fatalf("got %d sequence numbers, want 24", len(seqs))
+    }
+    for i, seq := range seqs {
+        if seq != int64(6+i) {
+            t.Fatalf("sequence numbers %v are not unique and gap-free from 6", seqs)
+        }
+    }
+}
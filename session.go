@@ -0,0 +1,81 @@
+package main
+
+import (
+    "context"
+    "sync/atomic"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const sessionSummarySpanName = "session-summary"
+
+// Counters for the run, fed by a span processor (spans started) and an
+// exporter wrapper (spans exported, or dropped by a failed export)
+type sessionStats struct {
+    start    time.Time
+    created  atomic.Int64
+    exported atomic.Int64
+    dropped  atomic.Int64
+}
+
+func newSessionStats() *sessionStats {
+    return &sessionStats{start: time.Now()}
+}
+
+// Span processor counting every sampled span. Spans only recorded, e.g. for
+// WARN_DROPPED_ERRORS, are never exported and so not counted.
+func (s *sessionStats) OnStart(_ context.Context, span trace.ReadWriteSpan) {
+    if span.SpanContext().IsSampled() {
+        s.created.Add(1)
+    }
+}
+
+func (s *sessionStats) OnEnd(trace.ReadOnlySpan)         {}
+func (s *sessionStats) Shutdown(context.Context) error   { return nil }
+func (s *sessionStats) ForceFlush(context.Context) error { return nil }
+
+// Wrap an exporter so its results are counted
+func (s *sessionStats) Exporter(exporter trace.SpanExporter) trace.SpanExporter {
+    return &sessionCountingExporter{SpanExporter: exporter, stats: s}
+}
+
+type sessionCountingExporter struct {
+    trace.SpanExporter
+    stats *sessionStats
+}
+
+func (e *sessionCountingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    err := e.SpanExporter.ExportSpans(ctx, spans)
+    if err != nil {
+        e.stats.dropped.Add(int64(len(spans)))
+    } else {
+        e.stats.exported.Add(int64(len(spans)))
+    }
+    return err
+}
+
+// Flush pending spans so the counters are settled, then record them on a
+// final session-summary span. The span comes from a provider of its own that
+// always samples and exports straight to exporter, so the configured sampler
+// cannot drop it.
+func (s *sessionStats) emitSummary(ctx context.Context, provider *trace.TracerProvider, exporter trace.SpanExporter, res *resource.Resource) {
+    _ = provider.ForceFlush(ctx)
+    summary := trace.NewTracerProvider(
+        trace.WithSampler(trace.AlwaysSample()),
+        trace.WithResource(res),
+        trace.WithSyncer(keepOpenExporter{exporter}),
+    )
+    _, span := summary.Tracer("session").Start(ctx, sessionSummarySpanName,
+        oteltrace.WithAttributes(
+            attribute.Int64("session.spans_created", s.created.Load()),
+            attribute.Int64("session.spans_exported", s.exported.Load()),
+            attribute.Int64("session.spans_dropped", s.dropped.Load()),
+            attribute.Float64("session.uptime_s", time.Since(s.start).Seconds()),
+        ))
+    span.End()
+    _ = summary.Shutdown(ctx)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSessionSummaryExportedWhenSampledOut(t *testing.T) {
+    recorder := tracetest.NewInMemoryExporter()
+    stats := newSessionStats()
+    exporter := stats.Exporter(recorder)
+    tp := trace.NewTracerProvider(
+        trace.WithSampler(trace.AlwaysSample()),
+        trace.WithSpanProcessor(stats),
+        trace.WithSyncer(exporter),
+    )
+    _, span := tp.Tracer("test").Start(context.Background(), "work")
+    span.End()
+
+    neverSampled := trace.NewTracerProvider(trace.WithSampler(trace.NeverSample()), trace.WithSyncer(exporter))
+    stats.emitSummary(context.Background(), neverSampled, exporter, resource.Empty())
+
+    var summary trace.ReadOnlySpan
+    for _, s := range recorder.GetSpans().Snapshots() {
+        if s.Name() == sessionSummarySpanName {
+            summary = s
+        }
+    }
+    if summary == nil {
+        t.Fatal("session summary not exported")
+    }
+    AssertSpanAttribute(t, summary, "session.spans_created", int64(1))
+    AssertSpanAttribute(t, summary, "session.spans_exported", int64(1))
+    AssertSpanAttribute(t, summary, "session.spans_dropped", int64(0))
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+    "strings"
+
+    "go.opentelemetry.io/otel/log"
+)
+
+// Common severity spellings beyond the OTel short names
+var severityAliases = map[string]log.Severity{
+    "INFORMATION": log.SeverityInfo,
+    "NOTICE":      log.SeverityInfo2,
+    "WARNING":     log.SeverityWarn,
+    "ERR":         log.SeverityError,
+    "CRITICAL":    log.SeverityFatal,
+    "CRIT":        log.SeverityFatal,
+    "PANIC":       log.SeverityFatal,
+}
+
+// Map severity text such as "ERROR", "warn" or "INFO2" to the OTel logs
+// severity, case-insensitively. Unknown text maps to Info.
+func severityTextToOTel(text string) log.Severity {
+    text = strings.ToUpper(strings.TrimSpace(text))
+    for s := log.SeverityTrace1; s <= log.SeverityFatal4; s++ {
+        if s.String() == text {
+            return s
+        }
+    }
+    if s, ok := severityAliases[text]; ok {
+        return s
+    }
+    return log.SeverityInfo
+}
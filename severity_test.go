@@ -0,0 +1,40 @@
+package main
+
+import (
+    "testing"
+
+    "go.opentelemetry.io/otel/log"
+)
+
+func TestSeverityTextToOTel(t *testing.T) {
+    tests := []struct {
+        text string
+        want log.Severity
+    }{
+        {"TRACE", log.SeverityTrace},
+        {"trace3", log.SeverityTrace3},
+        {"DEBUG", log.SeverityDebug},
+        {"Info", log.SeverityInfo},
+        {"INFO2", log.SeverityInfo2},
+        {"warn", log.SeverityWarn},
+        {"ERROR", log.SeverityError},
+        {"error4", log.SeverityError4},
+        {"FATAL", log.SeverityFatal},
+        {" ERROR ", log.SeverityError},
+        {"information", log.SeverityInfo},
+        {"NOTICE", log.SeverityInfo2},
+        {"Warning", log.SeverityWarn},
+        {"err", log.SeverityError},
+        {"CRITICAL", log.SeverityFatal},
+        {"crit", log.SeverityFatal},
+        {"panic", log.SeverityFatal},
+        {"", log.SeverityInfo},
+        {"verbose", log.SeverityInfo},
+        {"ERROR5", log.SeverityInfo},
+    }
+    for _, tt := range tests {
+        if got := severityTextToOTel(tt.text); got != tt.want {
+            t.Errorf("severityTextToOTel(%q) = %v, want %v", tt.text, got, tt.want)
+        }
+    }
+}
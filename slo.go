@@ -0,0 +1,88 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Latency SLOs: exact span names, then name prefixes as for slow-span
+// thresholds
+type sloRules struct {
+    exact    map[string]time.Duration
+    prefixes []prefixThreshold
+}
+
+func (r sloRules) empty() bool {
+    return len(r.exact) == 0 && len(r.prefixes) == 0
+}
+
+// Span processor tagging spans that have a latency SLO with slo.violated and
+// slo.threshold_ms before handing them to the next processor. An exact name
+// rule takes precedence over prefix rules, and the longest matching prefix
+// wins among those.
+type sloProcessor struct {
+    next  trace.SpanProcessor
+    rules sloRules
+}
+
+func newSLOProcessor(next trace.SpanProcessor, rules sloRules) *sloProcessor {
+    return &sloProcessor{next: next, rules: rules}
+}
+
+func (p *sloProcessor) threshold(name string) (time.Duration, bool) {
+    if threshold, ok := p.rules.exact[name]; ok {
+        return threshold, true
+    }
+    return longestPrefixThreshold(p.rules.prefixes, name)
+}
+
+func (p *sloProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *sloProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if threshold, ok := p.threshold(s.Name()); ok {
+        s = withAttributes(s,
+            attribute.Bool("slo.violated", s.EndTime().Sub(s.StartTime()) > threshold),
+            attribute.Float64("slo.threshold_ms", float64(threshold.Microseconds())/1000),
+        )
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *sloProcessor) Shutdown(ctx context.Context) error {
+    return p.next.Shutdown(ctx)
+}
+
+func (p *sloProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
+
+// Parse "name=duration" rules separated by commas; a name ending in "*" is a
+// prefix, e.g. "GET /users=200ms,db.*=50ms"
+func parseSLORules(value string) (sloRules, error) {
+    pairs, err := parsePrefixThresholds(value)
+    if err != nil {
+        return sloRules{}, err
+    }
+    var rules sloRules
+    for _, pt := range pairs {
+        if pt.prefix == "" {
+            return sloRules{}, fmt.Errorf("invalid SLO of %s: no span name", pt.threshold)
+        }
+        if prefix, ok := strings.CutSuffix(pt.prefix, "*"); ok {
+            rules.prefixes = append(rules.prefixes, prefixThreshold{prefix: prefix, threshold: pt.threshold})
+            continue
+        }
+        if rules.exact == nil {
+            rules.exact = make(map[string]time.Duration)
+        }
+        rules.exact[pt.prefix] = pt.threshold
+    }
+    return rules, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSLORulePrecedence(t *testing.T) {
+    rules, err := parseSLORules("db.*=50ms, db.query*=20ms, db.query.users=5ms")
+    if err != nil {
+        t.Fatal(err)
+    }
+    next := &collectingProcessor{}
+    p := newSLOProcessor(next, rules)
+    start := time.Unix(1700000000, 0)
+    for _, name := range []string{"db.query.users", "db.query.orders", "db.insert", "http.get"} {
+        p.OnEnd(tracetest.SpanStub{Name: name, StartTime: start, EndTime: start.Add(10 * time.Millisecond)}.Snapshot())
+    }
+
+    AssertSpanAttribute(t, next.spans[0], "slo.threshold_ms", 5.0)
+    AssertSpanAttribute(t, next.spans[0], "slo.violated", true)
+    AssertSpanAttribute(t, next.spans[1], "slo.threshold_ms", 20.0)
+    AssertSpanAttribute(t, next.spans[1], "slo.violated", false)
+    AssertSpanAttribute(t, next.spans[2], "slo.threshold_ms", 50.0)
+    if n := len(next.spans[3].Attributes()); n != 0 {
+        t.Errorf("span without an SLO has %d attributes", n)
+    }
+}
+
+func TestParseSLORulesRejectsMissingName(t *testing.T) {
+    for _, value := range []string{"=10ms", "db.*"} {
+        if _, err := parseSLORules(value); err == nil {
+            t.Errorf("%q: no error", value)
+        }
+    }
+}
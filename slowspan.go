@@ -0,0 +1,90 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Latency threshold for spans whose name starts with prefix
+type prefixThreshold struct {
+    prefix    string
+    threshold time.Duration
+}
+
+// Span processor tagging spans slower than their threshold with slow=true
+// before handing them to the next processor. The longest matching name
+// prefix decides the threshold, falling back to the default; a zero
+// threshold never tags.
+type slowSpanProcessor struct {
+    next             trace.SpanProcessor
+    defaultThreshold time.Duration
+    prefixes         []prefixThreshold
+}
+
+func newSlowSpanProcessor(next trace.SpanProcessor, defaultThreshold time.Duration, prefixes []prefixThreshold) *slowSpanProcessor {
+    return &slowSpanProcessor{next: next, defaultThreshold: defaultThreshold, prefixes: prefixes}
+}
+
+func (p *slowSpanProcessor) threshold(name string) time.Duration {
+    if threshold, ok := longestPrefixThreshold(p.prefixes, name); ok {
+        return threshold
+    }
+    return p.defaultThreshold
+}
+
+// Threshold of the longest prefix matching name, if any
+func longestPrefixThreshold(prefixes []prefixThreshold, name string) (time.Duration, bool) {
+    var threshold time.Duration
+    matched := -1
+    for _, pt := range prefixes {
+        if strings.HasPrefix(name, pt.prefix) && len(pt.prefix) > matched {
+            threshold, matched = pt.threshold, len(pt.prefix)
+        }
+    }
+    return threshold, matched >= 0
+}
+
+func (p *slowSpanProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *slowSpanProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if threshold := p.threshold(s.Name()); threshold > 0 && s.EndTime().Sub(s.StartTime()) > threshold {
+        s = withAttributes(s, attribute.Bool("slow", true))
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *slowSpanProcessor) Shutdown(ctx context.Context) error {
+    return p.next.Shutdown(ctx)
+}
+
+func (p *slowSpanProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
+
+// Parse "prefix=duration" pairs separated by commas, e.g. "db.=100ms,http.=1s"
+func parsePrefixThresholds(value string) ([]prefixThreshold, error) {
+    var out []prefixThreshold
+    for _, pair := range strings.Split(value, ",") {
+        pair = strings.TrimSpace(pair)
+        if pair == "" {
+            continue
+        }
+        prefix, raw, ok := strings.Cut(pair, "=")
+        if !ok {
+            return nil, fmt.Errorf("invalid threshold %q: want prefix=duration", pair)
+        }
+        d, err := time.ParseDuration(strings.TrimSpace(raw))
+        if err != nil {
+            return nil, fmt.Errorf("invalid threshold %q: %v", pair, err)
+        }
+        out = append(out, prefixThreshold{prefix: strings.TrimSpace(prefix), threshold: d})
+    }
+    return out, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSlowSpanTagsOnlySpansOverThreshold(t *testing.T) {
+    prefixes, err := parsePrefixThresholds("db.=100ms, db.cache.=5ms")
+    if err != nil {
+        t.Fatal(err)
+    }
+    next := &collectingProcessor{}
+    p := newSlowSpanProcessor(next, time.Second, prefixes)
+    start := time.Unix(1700000000, 0)
+    span := func(name string, d time.Duration) trace.ReadOnlySpan {
+        return tracetest.SpanStub{
+            Name:       name,
+            StartTime:  start,
+            EndTime:    start.Add(d),
+            Attributes: []attribute.KeyValue{attribute.String("own", "kept")},
+        }.Snapshot()
+    }
+    for _, s := range []trace.ReadOnlySpan{
+        span("db.query", 150*time.Millisecond),
+        span("db.query", 50*time.Millisecond),
+        span("db.cache.get", 10*time.Millisecond),
+        span("http.get", 500*time.Millisecond),
+        span("http.get", 2*time.Second),
+    } {
+        p.OnEnd(s)
+    }
+
+    for i, slow := range []bool{true, false, true, false, true} {
+        s := next.spans[i]
+        if slow {
+            AssertSpanAttribute(t, s, "slow", true)
+            AssertSpanAttribute(t, s, "own", "kept")
+            continue
+        }
+        if n := len(s.Attributes()); n != 1 {
+            t.Errorf("span %d (%s) tagged: %v", i, s.Name(), s.Attributes())
+        }
+    }
+}
+
+func TestParsePrefixThresholdsRejectsMalformedPairs(t *testing.T) {
+    for _, value := range []string{"db.", "db.=fast"} {
+        if _, err := parsePrefixThresholds(value); err == nil {
+            t.Errorf("%q: no error", value)
+        }
+    }
+}
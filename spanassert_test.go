@@ -0,0 +1,99 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Fail t unless span has the attribute key with value want, a string, int,
+// float or bool compared against the attribute of the matching type
+func AssertSpanAttribute(t testing.TB, span trace.ReadOnlySpan, key string, want any) {
+    t.Helper()
+    var got attribute.Value
+    found := false
+    for _, kv := range span.Attributes() {
+        if string(kv.Key) == key {
+            got, found = kv.Value, true
+        }
+    }
+    if !found {
+        t.Errorf("span %q has no attribute %q (want %v)", span.Name(), key, want)
+        return
+    }
+
+    var wantValue attribute.Value
+    switch w := want.(type) {
+    case string:
+        wantValue = attribute.StringValue(w)
+    case int:
+        wantValue = attribute.IntValue(w)
+    case int64:
+        wantValue = attribute.Int64Value(w)
+    case bool:
+        wantValue = attribute.BoolValue(w)
+    case float64:
+        wantValue = attribute.Float64Value(w)
+    default:
+        t.Errorf("AssertSpanAttribute: unsupported want type %T for %q", want, key)
+        return
+    }
+    if got != wantValue {
+        t.Errorf("span %q attribute %q = %s(%s), want %s(%s)",
+            span.Name(), key, got.Type(), got.Emit(), wantValue.Type(), wantValue.Emit())
+    }
+}
+
+// testing.TB recording failures instead of failing the test
+type recordingTB struct {
+    testing.TB
+    errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+    r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSpanAttribute(t *testing.T) {
+    span := tracetest.SpanStub{
+        Name: "op",
+        Attributes: []attribute.KeyValue{
+            attribute.String("s", "v"),
+            attribute.Int("n", 3),
+            attribute.Bool("b", true),
+            attribute.Float64("f", 1.5),
+        },
+    }.Snapshot()
+
+    for _, tc := range []struct {
+        key     string
+        want    any
+        failure string
+    }{
+        {"s", "v", ""},
+        {"n", 3, ""},
+        {"n", int64(3), ""},
+        {"b", true, ""},
+        {"f", 1.5, ""},
+        {"missing", "v", `has no attribute "missing"`},
+        {"s", "other", `attribute "s" = STRING(v), want STRING(other)`},
+        {"n", "3", `attribute "n" = INT64(3), want STRING(3)`},
+        {"b", false, `attribute "b" = BOOL(true), want BOOL(false)`},
+        {"s", float32(1.5), "unsupported want type float32"},
+    } {
+        r := &recordingTB{TB: t}
+        AssertSpanAttribute(r, span, tc.key, tc.want)
+        switch {
+        case tc.failure == "" && len(r.errors) > 0:
+            t.Errorf("%s=%v: unexpected failure %q", tc.key, tc.want, r.errors)
+        case tc.failure != "" && (len(r.errors) != 1 || !strings.Contains(r.errors[0], tc.failure)):
+            t.Errorf("%s=%v: failures %q, want one containing %q", tc.key, tc.want, r.errors, tc.failure)
+        }
+    }
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+    "fmt"
+    "strings"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Rule assigning Kind to entries that have attribute Key, optionally only
+// when it equals Value
+type spanKindRule struct {
+    Key   string
+    Value string
+    Kind  trace.SpanKind
+}
+
+func (r spanKindRule) matches(l LogEntry) bool {
+    v, ok := l.Attributes[r.Key]
+    return ok && (r.Value == "" || v == r.Value)
+}
+
+// HTTP entries are handled requests, database entries are calls out
+var defaultSpanKindRules = []spanKindRule{
+    {Key: "http.method", Kind: trace.SpanKindServer},
+    {Key: "db.system", Kind: trace.SpanKindClient},
+    {Key: "db.operation", Kind: trace.SpanKindClient},
+}
+
+// Span kind of the first matching rule, checking the configured rules before
+// the default HTTP/DB ones; internal when nothing matches
+func spanKindForEntry(l LogEntry, rules []spanKindRule) trace.SpanKind {
+    for _, set := range [][]spanKindRule{rules, defaultSpanKindRules} {
+        for _, rule := range set {
+            if rule.matches(l) {
+                return rule.Kind
+            }
+        }
+    }
+    return trace.SpanKindInternal
+}
+
+// Parse comma-separated rules of the form key=kind or key:value=kind, e.g.
+// "messaging.system=producer,rpc.system:grpc=client"
+func parseSpanKindRules(value string) ([]spanKindRule, error) {
+    var rules []spanKindRule
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        match, kindName, ok := strings.Cut(item, "=")
+        if !ok {
+            return nil, fmt.Errorf("invalid span kind rule %q: want key=kind", item)
+        }
+        kind, err := parseSpanKind(strings.TrimSpace(kindName))
+        if err != nil {
+            return nil, fmt.Errorf("invalid span kind rule %q: %v", item, err)
+        }
+        key, v, _ := strings.Cut(match, ":")
+        rules = append(rules, spanKindRule{Key: strings.TrimSpace(key), Value: strings.TrimSpace(v), Kind: kind})
+    }
+    return rules, nil
+}
+
+func parseSpanKind(name string) (trace.SpanKind, error) {
+    switch strings.ToLower(name) {
+    case "internal":
+        return trace.SpanKindInternal, nil
+    case "server":
+        return trace.SpanKindServer, nil
+    case "client":
+        return trace.SpanKindClient, nil
+    case "producer":
+        return trace.SpanKindProducer, nil
+    case "consumer":
+        return trace.SpanKindConsumer, nil
+    }
+    return trace.SpanKindUnspecified, fmt.Errorf("unknown span kind %q", name)
+}
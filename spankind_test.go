@@ -0,0 +1,42 @@
+package main
+
+import (
+    "testing"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanKindRules(t *testing.T) {
+    rules, err := parseSpanKindRules("messaging.system=producer, rpc.system:grpc=client, rpc.system=server")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        name  string
+        attrs map[string]string
+        want  trace.SpanKind
+    }{
+        {"messaging", map[string]string{"messaging.system": "kafka"}, trace.SpanKindProducer},
+        {"grpc client", map[string]string{"rpc.system": "grpc"}, trace.SpanKindClient},
+        {"other rpc", map[string]string{"rpc.system": "thrift"}, trace.SpanKindServer},
+        {"rule before default", map[string]string{"messaging.system": "kafka", "http.method": "POST"}, trace.SpanKindProducer},
+        {"default http", map[string]string{"http.method": "GET"}, trace.SpanKindServer},
+        {"default db", map[string]string{"db.system": "postgresql"}, trace.SpanKindClient},
+        {"no match", map[string]string{"user.id": "u-1"}, trace.SpanKindInternal},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := spanKindForEntry(LogEntry{Attributes: tt.attrs}, rules); got != tt.want {
+                t.Errorf("spanKindForEntry(%v) = %v, want %v", tt.attrs, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParseSpanKindRulesRejectsInvalid(t *testing.T) {
+    for _, value := range []string{"messaging.system", "rpc.system=sideways"} {
+        if _, err := parseSpanKindRules(value); err == nil {
+            t.Errorf("parseSpanKindRules(%q) succeeded, want error", value)
+        }
+    }
+}
@@ -0,0 +1,30 @@
+package main
+
+// How a span name is derived from a LogEntry
+type spanNameStrategy string
+
+const (
+    // Use event.name, falling back to the Body
+    spanNameFromEvent spanNameStrategy = "event"
+    // Use exception.type so error traces group by exception kind, falling
+    // back to the event strategy when the entry carries no exception
+    spanNameFromException spanNameStrategy = "exception"
+)
+
+const defaultSpanName = "log-entry"
+
+// Pick the span name for a LogEntry according to the strategy
+func spanNameForEntry(l LogEntry, strategy spanNameStrategy) string {
+    if strategy == spanNameFromException {
+        if name := l.Exception["exception.type"]; name != "" {
+            return name
+        }
+    }
+    if name := l.EventData["event.name"]; name != "" {
+        return name
+    }
+    if l.Body != "" {
+        return l.Body
+    }
+    return defaultSpanName
+}
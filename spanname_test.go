@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestSpanNameForEntry(t *testing.T) {
+    withEvery := LogEntry{
+        Body:      "request failed",
+        EventData: map[string]string{"event.name": "request_error"},
+        Exception: map[string]string{"exception.type": "DatabaseError"},
+    }
+    withoutException := LogEntry{Body: "request failed", EventData: map[string]string{"event.name": "request_error"}}
+    for _, tc := range []struct {
+        l        LogEntry
+        strategy spanNameStrategy
+        want     string
+    }{
+        {withEvery, spanNameFromException, "DatabaseError"},
+        {withEvery, spanNameFromEvent, "request_error"},
+        {withoutException, spanNameFromException, "request_error"},
+        {LogEntry{Body: "request failed"}, spanNameFromException, "request failed"},
+        {LogEntry{}, spanNameFromException, defaultSpanName},
+    } {
+        if got := spanNameForEntry(tc.l, tc.strategy); got != tc.want {
+            t.Errorf("%s strategy on %+v: %q, want %q", tc.strategy, tc.l, got, tc.want)
+        }
+    }
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Ended spans are read-only, so processors that change attributes on OnEnd
+// pass this view down the chain instead of mutating the span.
+type attributeView struct {
+    trace.ReadOnlySpan
+    attrs []attribute.KeyValue
+}
+
+func (s attributeView) Attributes() []attribute.KeyValue {
+    return s.attrs
+}
+
+// View of s carrying extra attributes in addition to its own
+func withAttributes(s trace.ReadOnlySpan, attrs ...attribute.KeyValue) trace.ReadOnlySpan {
+    if len(attrs) == 0 {
+        return s
+    }
+    own := s.Attributes()
+    all := make([]attribute.KeyValue, 0, len(own)+len(attrs))
+    return attributeView{ReadOnlySpan: s, attrs: append(append(all, own...), attrs...)}
+}
+
+// View of s whose attributes are replaced by attrs
+func replaceAttributes(s trace.ReadOnlySpan, attrs []attribute.KeyValue) trace.ReadOnlySpan {
+    return attributeView{ReadOnlySpan: s, attrs: attrs}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "net"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+    defaultStatsDAddress = "127.0.0.1:8125"
+    defaultStatsDPrefix  = "spans"
+)
+
+// Largest datagram sent to StatsD, small enough to avoid IP fragmentation
+const maxStatsDPacketSize = 1432
+
+// Runs of characters StatsD does not accept in metric names
+var statsDUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Attributes holding a low-cardinality operation (a route template rather
+// than a URL) to name span metrics by, checked in order
+var defaultStatsDNameKeys = []string{"http.route", "db.operation", "rpc.method"}
+
+// Operation of spans with none of the name keys and a name not allowlisted
+const statsDOtherOperation = "other"
+
+// Exporter wrapper mirroring exported spans as StatsD metrics: a
+// <prefix>.<operation>.count counter and a <prefix>.<operation>.duration
+// timer per span, tagged (DogStatsD style) with the span kind and status.
+// Span names come from log bodies and are unbounded, so the operation is the
+// value of the first name key attribute the span has, its name if in the
+// allowlist, or "other". Lines of an export call are packed into as few UDP
+// datagrams as fit. StatsD failures are reported through otel.Handle and
+// never fail the export.
+type statsDExporter struct {
+    trace.SpanExporter
+    prefix    string
+    nameKeys  []string
+    spanNames map[string]bool
+
+    mu   sync.Mutex
+    conn net.Conn
+}
+
+func newStatsDExporter(exporter trace.SpanExporter, address, prefix string, nameKeys, spanNames []string) (*statsDExporter, error) {
+    if address == "" {
+        address = defaultStatsDAddress
+    }
+    if len(nameKeys) == 0 {
+        nameKeys = defaultStatsDNameKeys
+    }
+    conn, err := net.Dial("udp", address)
+    if err != nil {
+        return nil, fmt.Errorf("statsd: %w", err)
+    }
+    allowed := make(map[string]bool, len(spanNames))
+    for _, name := range spanNames {
+        allowed[name] = true
+    }
+    return &statsDExporter{SpanExporter: exporter, prefix: prefix, nameKeys: nameKeys, spanNames: allowed, conn: conn}, nil
+}
+
+func (e *statsDExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    err := e.SpanExporter.ExportSpans(ctx, spans)
+    if sendErr := e.send(e.lines(spans)); sendErr != nil {
+        otel.Handle(sendErr)
+    }
+    return err
+}
+
+func (e *statsDExporter) send(lines []string) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.conn == nil {
+        return nil
+    }
+    for _, packet := range packStatsDLines(lines, maxStatsDPacketSize) {
+        if _, err := e.conn.Write(packet); err != nil {
+            return fmt.Errorf("statsd send: %w", err)
+        }
+    }
+    return nil
+}
+
+func (e *statsDExporter) Shutdown(ctx context.Context) error {
+    e.mu.Lock()
+    if e.conn != nil {
+        e.conn.Close()
+        e.conn = nil
+    }
+    e.mu.Unlock()
+    return e.SpanExporter.Shutdown(ctx)
+}
+
+// Count and timing lines for each span, durations in milliseconds
+func (e *statsDExporter) lines(spans []trace.ReadOnlySpan) []string {
+    lines := make([]string, 0, 2*len(spans))
+    for _, s := range spans {
+        name := statsDMetricName(e.prefix, e.operation(s))
+        tags := "|#span.kind:" + s.SpanKind().String() + ",status:" + strings.ToLower(s.Status().Code.String())
+        ms := float64(s.EndTime().Sub(s.StartTime()).Microseconds()) / 1000
+        lines = append(lines,
+            name+".count:1|c"+tags,
+            name+".duration:"+strconv.FormatFloat(ms, 'f', -1, 64)+"|ms"+tags,
+        )
+    }
+    return lines
+}
+
+// Bounded operation a span's metrics are named by
+func (e *statsDExporter) operation(s trace.ReadOnlySpan) string {
+    for _, key := range e.nameKeys {
+        for _, kv := range s.Attributes() {
+            if string(kv.Key) == key && kv.Value.Emit() != "" {
+                return kv.Value.Emit()
+            }
+        }
+    }
+    if e.spanNames[s.Name()] {
+        return s.Name()
+    }
+    return statsDOtherOperation
+}
+
+// Metric name for an operation, with unsafe characters replaced by "_"
+func statsDMetricName(prefix, operation string) string {
+    name := strings.Trim(statsDUnsafeChars.ReplaceAllString(operation, "_"), "._")
+    if name == "" {
+        name = "unnamed"
+    }
+    if prefix == "" {
+        return name
+    }
+    return prefix + "." + name
+}
+
+// Newline-separated packets of at most size bytes; a single longer line is
+// sent on its own
+func packStatsDLines(lines []string, size int) [][]byte {
+    var packets [][]byte
+    var buf bytes.Buffer
+    for _, line := range lines {
+        if buf.Len() > 0 && buf.Len()+1+len(line) > size {
+            packets = append(packets, bytes.Clone(buf.Bytes()))
+            buf.Reset()
+        }
+        if buf.Len() > 0 {
+            buf.WriteByte('\n')
+        }
+        buf.WriteString(line)
+    }
+    if buf.Len() > 0 {
+        packets = append(packets, buf.Bytes())
+    }
+    return packets
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+    "context"
+    "net"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestStatsDExporterSendsSpanMetrics(t *testing.T) {
+    listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer listener.Close()
+
+    inner := tracetest.NewInMemoryExporter()
+    e, err := newStatsDExporter(inner, listener.LocalAddr().String(), "app", nil, []string{"checkout"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer e.Shutdown(context.Background())
+
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    spans := tracetest.SpanStubs{
+        {Name: "GET /users/42", SpanKind: oteltrace.SpanKindServer, StartTime: start, EndTime: start.Add(1500 * time.Microsecond),
+            Attributes: []attribute.KeyValue{attribute.String("http.route", "/users/{id}")}},
+        {Name: "db query for user 42", StartTime: start, EndTime: start.Add(20 * time.Millisecond),
+            Attributes: []attribute.KeyValue{attribute.String("db.operation", "SELECT")}, Status: trace.Status{Code: codes.Error}},
+        {Name: "checkout", StartTime: start, EndTime: start.Add(time.Millisecond)},
+        {Name: "user 42 logged in", StartTime: start, EndTime: start},
+    }.Snapshots()
+    if err := e.ExportSpans(context.Background(), spans); err != nil {
+        t.Fatal(err)
+    }
+    if n := len(inner.GetSpans()); n != 4 {
+        t.Errorf("wrapped exporter got %d spans, want 4", n)
+    }
+
+    listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+    buf := make([]byte, maxStatsDPacketSize)
+    n, _, err := listener.ReadFrom(buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Metrics are named by route, operation or allowlisted name, never by
+    // the unbounded span name
+    want := []string{
+        "app.users_id.count:1|c|#span.kind:server,status:unset",
+        "app.users_id.duration:1.5|ms|#span.kind:server,status:unset",
+        "app.SELECT.count:1|c|#span.kind:unspecified,status:error",
+        "app.SELECT.duration:20|ms|#span.kind:unspecified,status:error",
+        "app.checkout.count:1|c|#span.kind:unspecified,status:unset",
+        "app.checkout.duration:1|ms|#span.kind:unspecified,status:unset",
+        "app.other.count:1|c|#span.kind:unspecified,status:unset",
+        "app.other.duration:0|ms|#span.kind:unspecified,status:unset",
+    }
+    if got := strings.Split(string(buf[:n]), "\n"); !reflect.DeepEqual(got, want) {
+        t.Errorf("packet lines = %q, want %q in one packet", got, want)
+    }
+}
+
+func TestPackStatsDLines(t *testing.T) {
+    lines := []string{"a.count:1|c", "b.count:1|c", "c.count:1|c", strings.Repeat("x", 40)}
+    packets := packStatsDLines(lines, 24)
+    want := []string{"a.count:1|c\nb.count:1|c", "c.count:1|c", strings.Repeat("x", 40)}
+    if len(packets) != len(want) {
+        t.Fatalf("got %d packets, want %d: %q", len(packets), len(want), packets)
+    }
+    for i, p := range packets {
+        if string(p) != want[i] {
+            t.Errorf("packet %d = %q, want %q", i, p, want[i])
+        }
+    }
+}
+
+func TestStatsDMetricName(t *testing.T) {
+    tests := []struct{ prefix, span, want string }{
+        {"spans", "GET /users", "spans.GET_users"},
+        {"", "checkout.step-1", "checkout.step-1"},
+        {"spans", "/", "spans.unnamed"},
+        {"spans", "héllo wörld", "spans.h_llo_w_rld"},
+    }
+    for _, tt := range tests {
+        if got := statsDMetricName(tt.prefix, tt.span); got != tt.want {
+            t.Errorf("statsDMetricName(%q, %q) = %q, want %q", tt.prefix, tt.span, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+    "regexp"
+    "strconv"
+    "strings"
+
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+const defaultStatusMessage = "log entry reported an error"
+
+var statusPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Report whether the entry describes a failure
+func isErrorEntry(l LogEntry) bool {
+    if strings.EqualFold(l.Status, "failed") {
+        return true
+    }
+    n, err := strconv.Atoi(l.SeverityNumber)
+    return err == nil && n >= 17
+}
+
+// Set an error status on the span for failed entries
+func setEntryStatus(span trace.Span, l LogEntry, template string) {
+    if isErrorEntry(l) {
+        span.SetStatus(codes.Error, renderStatusMessage(template, l))
+    }
+}
+
+// Build a status message from a template such as
+// "HTTP {http.status_code}: {exception.message}". Placeholders are looked up
+// in Attributes, then Exception, then EventData, and render empty when
+// missing. An empty template uses the exception message or a generic message.
+func renderStatusMessage(template string, l LogEntry) string {
+    if template == "" {
+        if msg := l.Exception["exception.message"]; msg != "" {
+            return msg
+        }
+        return defaultStatusMessage
+    }
+    return statusPlaceholder.ReplaceAllStringFunc(template, func(p string) string {
+        key := p[1 : len(p)-1]
+        for _, m := range []map[string]string{l.Attributes, l.Exception, l.EventData} {
+            if v, ok := m[key]; ok {
+                return v
+            }
+        }
+        return ""
+    })
+}
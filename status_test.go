@@ -0,0 +1,51 @@
+package main
+
+import (
+    "testing"
+
+    "go.opentelemetry.io/otel/codes"
+)
+
+func TestRenderStatusMessage(t *testing.T) {
+    l := LogEntry{
+        Attributes: map[string]string{"http.status_code": "503"},
+        Exception:  map[string]string{"exception.message": "upstream timed out"},
+        EventData:  map[string]string{"retry": "3"},
+    }
+    tests := []struct {
+        name     string
+        template string
+        entry    LogEntry
+        want     string
+    }{
+        {"attributes and exception", "HTTP {http.status_code}: {exception.message}", l, "HTTP 503: upstream timed out"},
+        {"event data", "after {retry} retries", l, "after 3 retries"},
+        {"missing placeholder", "code={missing}", l, "code="},
+        {"default uses exception", "", l, "upstream timed out"},
+        {"default generic", "", LogEntry{}, defaultStatusMessage},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := renderStatusMessage(tt.template, tt.entry); got != tt.want {
+                t.Errorf("renderStatusMessage(%q) = %q, want %q", tt.template, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestEntrySpanStatusUsesTemplate(t *testing.T) {
+    failed := LogEntry{
+        Body:           "request failed",
+        SeverityNumber: "17",
+        Attributes:     map[string]string{"http.status_code": "500"},
+    }
+    span := recordEntrySpan(t, failed, config{StatusTemplate: "HTTP {http.status_code}"})
+    if span.Status().Code != codes.Error || span.Status().Description != "HTTP 500" {
+        t.Errorf("status = %+v, want Error with \"HTTP 500\"", span.Status())
+    }
+
+    ok := recordEntrySpan(t, LogEntry{Body: "fine", SeverityNumber: "9"}, config{StatusTemplate: "HTTP {http.status_code}"})
+    if ok.Status().Code != codes.Unset {
+        t.Errorf("status = %+v for a non-error entry, want Unset", ok.Status())
+    }
+}
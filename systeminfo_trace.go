@@ -0,0 +1,45 @@
+package main
+
+import (
+    "context"
+    "net"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Trace the interface enumeration done by getSystemInfo, with a child span
+// per interface examined. Resource detection has to run before the tracer
+// provider exists, so this is a separate pass made once tracing is set up.
+func traceSystemInfo(ctx context.Context, tracer trace.Tracer, preferredInterface string) {
+    ctx, span := tracer.Start(ctx, "getSystemInfo")
+    defer span.End()
+
+    interfaces, err := net.Interfaces()
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+        return
+    }
+
+    selected, _, _ := selectInterface(interfaces, preferredInterface)
+    span.SetAttributes(
+        attribute.Int("interface.count", len(interfaces)),
+        attribute.String("interface.selected_name", selected),
+    )
+
+    for _, iface := range interfaces {
+        _, child := tracer.Start(ctx, "inspect-interface")
+        addrs, err := iface.Addrs()
+        if err != nil {
+            child.RecordError(err)
+        }
+        child.SetAttributes(
+            attribute.String("interface.name", iface.Name),
+            attribute.Int("interface.address_count", len(addrs)),
+            attribute.Bool("interface.selected", iface.Name == selected),
+        )
+        child.End()
+    }
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+    "context"
+    "net"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceSystemInfoSpanPerInterface(t *testing.T) {
+    interfaces, err := net.Interfaces()
+    if err != nil {
+        t.Skipf("cannot list interfaces: %v", err)
+    }
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+    traceSystemInfo(context.Background(), tp.Tracer("test"), "")
+
+    var parent trace.ReadOnlySpan
+    children := 0
+    for _, s := range recorder.Ended() {
+        switch s.Name() {
+        case "getSystemInfo":
+            parent = s
+        case "inspect-interface":
+            children++
+        }
+    }
+    if parent == nil {
+        t.Fatal("no getSystemInfo span")
+    }
+    AssertSpanAttribute(t, parent, "interface.count", len(interfaces))
+    if children != len(interfaces) {
+        t.Errorf("%d interface spans for %d interfaces", children, len(interfaces))
+    }
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Magnitude thresholds used to guess the precision of a numeric Unix timestamp.
+// Anything below 1e12 is treated as seconds, below 1e15 as milliseconds,
+// below 1e18 as microseconds, and everything else as nanoseconds.
+const (
+    unixMillisThreshold = 1e12
+    unixMicrosThreshold = 1e15
+    unixNanosThreshold  = 1e18
+)
+
+// Parse a timestamp that is either RFC3339 or a numeric Unix timestamp
+func parseTimestamp(s string) (time.Time, error) {
+    s = strings.TrimSpace(s)
+    if s == "" {
+        return time.Time{}, fmt.Errorf("empty timestamp")
+    }
+
+    n, err := strconv.ParseInt(s, 10, 64)
+    if err != nil {
+        t, err := time.Parse(time.RFC3339Nano, s)
+        if err != nil {
+            return time.Time{}, fmt.Errorf("invalid timestamp %q: not RFC3339 or Unix time", s)
+        }
+        return t, nil
+    }
+
+    abs := n
+    if abs < 0 {
+        abs = -abs
+    }
+    switch {
+    case abs < unixMillisThreshold:
+        return time.Unix(n, 0).UTC(), nil
+    case abs < unixMicrosThreshold:
+        return time.UnixMilli(n).UTC(), nil
+    case abs < unixNanosThreshold:
+        return time.UnixMicro(n).UTC(), nil
+    default:
+        return time.Unix(0, n).UTC(), nil
+    }
+}
+
+// Sub-second precision of generated LogEntry timestamps
+type timePrecision string
+
+const (
+    timestampNano   timePrecision = "nano"
+    timestampMilli  timePrecision = "milli"
+    timestampSecond timePrecision = "second"
+)
+
+// Layout used by formatTimestamp, set from TIMESTAMP_PRECISION at startup
+var timestampLayout = time.RFC3339Nano
+
+func (p timePrecision) layout() string {
+    switch p {
+    case timestampMilli:
+        return "2006-01-02T15:04:05.000Z07:00"
+    case timestampSecond:
+        return time.RFC3339
+    }
+    return time.RFC3339Nano
+}
+
+// Format a LogEntry timestamp at the configured precision
+func formatTimestamp(t time.Time) string {
+    return t.Format(timestampLayout)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+    want := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+    for _, value := range []string{
+        "2024-03-01T12:30:45Z",
+        "2024-03-01T14:30:45+02:00",
+        "1709296245",
+        "1709296245000",
+        "1709296245000000",
+        "1709296245000000000",
+        " 1709296245 ",
+    } {
+        got, err := parseTimestamp(value)
+        if err != nil {
+            t.Errorf("parseTimestamp(%q): %v", value, err)
+            continue
+        }
+        if !got.Equal(want) {
+            t.Errorf("parseTimestamp(%q) = %s, want %s", value, got, want)
+        }
+    }
+
+    got, err := parseTimestamp("2024-03-01T12:30:45.123456789Z")
+    if err != nil || got.Nanosecond() != 123456789 {
+        t.Errorf("fractional RFC3339: %s, %v", got, err)
+    }
+    for _, value := range []string{"", "yesterday", "2024-03-01"} {
+        if _, err := parseTimestamp(value); err == nil {
+            t.Errorf("parseTimestamp(%q): no error", value)
+        }
+    }
+}
+
+func TestTimestampPrecisionRetained(t *testing.T) {
+    saved := timestampLayout
+    defer func() { timestampLayout = saved }()
+
+    ts := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+    tests := []struct {
+        precision timePrecision
+        want      string
+        roundTrip time.Time
+    }{
+        {timestampNano, "2024-03-01T12:30:45.123456789Z", ts},
+        {timestampMilli, "2024-03-01T12:30:45.123Z", ts.Truncate(time.Millisecond)},
+        {timestampSecond, "2024-03-01T12:30:45Z", ts.Truncate(time.Second)},
+    }
+    for _, tt := range tests {
+        timestampLayout = tt.precision.layout()
+        got := formatTimestamp(ts)
+        if got != tt.want {
+            t.Errorf("%s: formatTimestamp = %q, want %q", tt.precision, got, tt.want)
+        }
+        parsed, err := parseTimestamp(got)
+        if err != nil || !parsed.Equal(tt.roundTrip) {
+            t.Errorf("%s: parsed back as %s, %v, want %s", tt.precision, parsed, err, tt.roundTrip)
+        }
+    }
+
+    // Milliseconds keep their trailing zeros so every entry has the same width
+    timestampLayout = timestampMilli.layout()
+    if got := formatTimestamp(time.Date(2024, 3, 1, 12, 30, 45, 100000000, time.UTC)); got != "2024-03-01T12:30:45.100Z" {
+        t.Errorf("milli timestamp = %q", got)
+    }
+}
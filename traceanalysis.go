@@ -0,0 +1,364 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Heuristic for buffered traces: complete when a root span (one without a
+// parent) is present and every parent span ID referenced is among the spans.
+// An empty set or any orphaned span makes the trace incomplete.
+func isTraceComplete(spans []trace.ReadOnlySpan) bool {
+    ids := make(map[oteltrace.SpanID]struct{}, len(spans))
+    for _, s := range spans {
+        ids[s.SpanContext().SpanID()] = struct{}{}
+    }
+
+    hasRoot := false
+    for _, s := range spans {
+        parent := s.Parent()
+        if !parent.SpanID().IsValid() {
+            hasRoot = true
+            continue
+        }
+        if _, ok := ids[parent.SpanID()]; !ok {
+            return false
+        }
+    }
+    return hasRoot
+}
+
+// Time from the earliest start to the latest end across the spans of a
+// trace. Zero start or end times are ignored; the result is zero when no span
+// has both.
+func traceDuration(spans []trace.ReadOnlySpan) time.Duration {
+    var first, last time.Time
+    for _, s := range spans {
+        if start := s.StartTime(); !start.IsZero() && (first.IsZero() || start.Before(first)) {
+            first = start
+        }
+        if end := s.EndTime(); !end.IsZero() && end.After(last) {
+            last = end
+        }
+    }
+    if first.IsZero() || last.IsZero() || last.Before(first) {
+        return 0
+    }
+    return last.Sub(first)
+}
+
+// Graphviz DOT digraph of the span tree, one node per span labelled with its
+// name and duration and an edge from each parent to its children. Spans
+// whose parent is not among the spans are left as disconnected nodes.
+func traceToDOT(spans []trace.ReadOnlySpan) string {
+    ids := make(map[oteltrace.SpanID]struct{}, len(spans))
+    for _, s := range spans {
+        ids[s.SpanContext().SpanID()] = struct{}{}
+    }
+
+    var b strings.Builder
+    b.WriteString("digraph trace {\n")
+    b.WriteString("    node [shape=box];\n")
+    for _, s := range spans {
+        label := dotEscape(s.Name()) + `\n` + s.EndTime().Sub(s.StartTime()).String()
+        fmt.Fprintf(&b, "    \"%s\" [label=\"%s\"];\n", s.SpanContext().SpanID(), label)
+    }
+    for _, s := range spans {
+        parent := s.Parent().SpanID()
+        if _, ok := ids[parent]; ok && parent.IsValid() {
+            fmt.Fprintf(&b, "    \"%s\" -> \"%s\";\n", parent, s.SpanContext().SpanID())
+        }
+    }
+    b.WriteString("}\n")
+    return b.String()
+}
+
+// Escape a string for use inside a double-quoted DOT ID
+func dotEscape(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Number of slowest spans listed per trace in TraceStatsReport
+const reportSlowestSpans = 5
+
+type traceStats struct {
+    TraceID      string         `json:"trace_id"`
+    SpanCount    int            `json:"span_count"`
+    ErrorCount   int            `json:"error_count"`
+    DurationMS   float64        `json:"duration_ms"`
+    SlowestSpans []spanDuration `json:"slowest_spans"`
+}
+
+type spanDuration struct {
+    Name       string  `json:"name"`
+    SpanID     string  `json:"span_id"`
+    DurationMS float64 `json:"duration_ms"`
+}
+
+// JSON report of per-trace statistics: span and error counts, traceDuration
+// and the slowest spans, e.g. to gate CI on a replayed log. Traces are listed
+// in the order they first appear among the spans.
+func TraceStatsReport(spans []trace.ReadOnlySpan) ([]byte, error) {
+    order, byTrace := spansByTrace(spans)
+
+    report := struct {
+        Traces []traceStats `json:"traces"`
+    }{Traces: make([]traceStats, 0, len(order))}
+    for _, id := range order {
+        traceSpans := byTrace[id]
+        stats := traceStats{
+            TraceID:    id.String(),
+            SpanCount:  len(traceSpans),
+            DurationMS: durationMS(traceDuration(traceSpans)),
+        }
+        slowest := make([]spanDuration, 0, len(traceSpans))
+        for _, s := range traceSpans {
+            if s.Status().Code == codes.Error {
+                stats.ErrorCount++
+            }
+            slowest = append(slowest, spanDuration{
+                Name:       s.Name(),
+                SpanID:     s.SpanContext().SpanID().String(),
+                DurationMS: durationMS(s.EndTime().Sub(s.StartTime())),
+            })
+        }
+        sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].DurationMS > slowest[j].DurationMS })
+        stats.SlowestSpans = slowest[:min(len(slowest), reportSlowestSpans)]
+        report.Traces = append(report.Traces, stats)
+    }
+    return json.MarshalIndent(report, "", "  ")
+}
+
+// Spans grouped by trace, and the trace IDs in the order they first appear
+func spansByTrace(spans []trace.ReadOnlySpan) ([]oteltrace.TraceID, map[oteltrace.TraceID][]trace.ReadOnlySpan) {
+    var order []oteltrace.TraceID
+    byTrace := map[oteltrace.TraceID][]trace.ReadOnlySpan{}
+    for _, s := range spans {
+        id := s.SpanContext().TraceID()
+        if _, ok := byTrace[id]; !ok {
+            order = append(order, id)
+        }
+        byTrace[id] = append(byTrace[id], s)
+    }
+    return order, byTrace
+}
+
+func durationMS(d time.Duration) float64 {
+    return float64(d.Microseconds()) / 1000
+}
+
+// Caller to callee service dependency, with the number of parent-child span
+// pairs it was inferred from
+type Edge struct {
+    Caller string `json:"caller"`
+    Callee string `json:"callee"`
+    Calls  int    `json:"calls"`
+}
+
+// Service dependency edges for a service map: one per pair of services where
+// a span of the callee has a parent span of the caller. service.name is taken
+// from the span's attributes, then its resource; pairs within one service,
+// and spans whose parent or service is unknown, produce no edge. Edges are
+// sorted by caller, then callee.
+func dependencyEdges(spans []trace.ReadOnlySpan) []Edge {
+    // Span IDs are only unique within a trace
+    type spanRef struct {
+        traceID oteltrace.TraceID
+        spanID  oteltrace.SpanID
+    }
+    services := make(map[spanRef]string, len(spans))
+    for _, s := range spans {
+        services[spanRef{s.SpanContext().TraceID(), s.SpanContext().SpanID()}] = spanServiceName(s)
+    }
+
+    type pair struct{ caller, callee string }
+    calls := map[pair]int{}
+    for _, s := range spans {
+        caller, ok := services[spanRef{s.SpanContext().TraceID(), s.Parent().SpanID()}]
+        callee := services[spanRef{s.SpanContext().TraceID(), s.SpanContext().SpanID()}]
+        if !ok || caller == "" || callee == "" || caller == callee {
+            continue
+        }
+        calls[pair{caller, callee}]++
+    }
+
+    edges := make([]Edge, 0, len(calls))
+    for p, n := range calls {
+        edges = append(edges, Edge{Caller: p.caller, Callee: p.callee, Calls: n})
+    }
+    sort.Slice(edges, func(i, j int) bool {
+        if edges[i].Caller != edges[j].Caller {
+            return edges[i].Caller < edges[j].Caller
+        }
+        return edges[i].Callee < edges[j].Callee
+    })
+    return edges
+}
+
+func spanServiceName(s trace.ReadOnlySpan) string {
+    for _, kv := range s.Attributes() {
+        if kv.Key == "service.name" {
+            return kv.Value.Emit()
+        }
+    }
+    if res := s.Resource(); res != nil {
+        if v, ok := res.Set().Value("service.name"); ok {
+            return v.Emit()
+        }
+    }
+    return ""
+}
+
+// Attributes whose values change from run to run and are ignored by
+// DiffTraces: timings, and what is derived from them or from batching
+var volatileAttributeKeys = map[attribute.Key]bool{
+    "trace.duration_ms":        true,
+    "json.marshal_duration_ms": true,
+    "session.uptime_s":         true,
+    "repeat.count":             true,
+    "slo.violated":             true,
+    "slow":                     true,
+}
+
+// Differences between two sets of spans, e.g. the export before and after a
+// refactor, one message per difference. Spans are matched by their path of
+// names from the root, and spans on the same path by their sorted content, so
+// arrival order does not matter; IDs, timestamps and volatile attributes are
+// ignored. Kind, status, attributes and event names are compared. Nil means
+// the sets match.
+func DiffTraces(a, b []trace.ReadOnlySpan) []string {
+    as, bs := spansByPath(a), spansByPath(b)
+    var diffs []string
+    for _, path := range sortedPaths(as) {
+        sb, ok := bs[path]
+        if !ok {
+            diffs = append(diffs, fmt.Sprintf("span %q: missing from b", path))
+            continue
+        }
+        diffs = append(diffs, diffSpan(path, as[path], sb)...)
+    }
+    for _, path := range sortedPaths(bs) {
+        if _, ok := as[path]; !ok {
+            diffs = append(diffs, fmt.Sprintf("span %q: missing from a", path))
+        }
+    }
+    return diffs
+}
+
+// Spans keyed by the names from their root down to them joined with " > ".
+// Spans sharing a path are ordered by spanSignature and "#n" is appended to
+// the nth from 2.
+func spansByPath(spans []trace.ReadOnlySpan) map[string]trace.ReadOnlySpan {
+    byID := make(map[oteltrace.SpanID]trace.ReadOnlySpan, len(spans))
+    for _, s := range spans {
+        if s.SpanContext().SpanID().IsValid() {
+            byID[s.SpanContext().SpanID()] = s
+        }
+    }
+    byPath := map[string][]trace.ReadOnlySpan{}
+    for _, s := range spans {
+        names := []string{s.Name()}
+        // Bounded by the span count in case of a parent cycle
+        for p, ok := byID[s.Parent().SpanID()]; ok && len(names) <= len(spans); p, ok = byID[p.Parent().SpanID()] {
+            names = append([]string{p.Name()}, names...)
+        }
+        path := strings.Join(names, " > ")
+        byPath[path] = append(byPath[path], s)
+    }
+
+    out := make(map[string]trace.ReadOnlySpan, len(spans))
+    for path, siblings := range byPath {
+        sort.SliceStable(siblings, func(i, j int) bool { return spanSignature(siblings[i]) < spanSignature(siblings[j]) })
+        for i, s := range siblings {
+            if i == 0 {
+                out[path] = s
+            } else {
+                out[fmt.Sprintf("%s#%d", path, i+1)] = s
+            }
+        }
+    }
+    return out
+}
+
+// Compared content of a span as one string, to order spans sharing a path
+func spanSignature(s trace.ReadOnlySpan) string {
+    attrs := comparableAttributes(s.Attributes())
+    var b strings.Builder
+    fmt.Fprintf(&b, "%s\x00%v", s.SpanKind(), s.Status())
+    for _, k := range sortedKeys(attrs) {
+        fmt.Fprintf(&b, "\x00%s=%s", k, attrs[k])
+    }
+    for _, name := range eventNames(s.Events()) {
+        fmt.Fprintf(&b, "\x00event:%s", name)
+    }
+    return b.String()
+}
+
+func sortedPaths(m map[string]trace.ReadOnlySpan) []string {
+    paths := make([]string, 0, len(m))
+    for p := range m {
+        paths = append(paths, p)
+    }
+    sort.Strings(paths)
+    return paths
+}
+
+func diffSpan(path string, a, b trace.ReadOnlySpan) []string {
+    var diffs []string
+    if a.SpanKind() != b.SpanKind() {
+        diffs = append(diffs, fmt.Sprintf("span %q: kind changed from %s to %s", path, a.SpanKind(), b.SpanKind()))
+    }
+    if a.Status() != b.Status() {
+        diffs = append(diffs, fmt.Sprintf("span %q: status changed from %v to %v", path, a.Status(), b.Status()))
+    }
+
+    aAttrs, bAttrs := comparableAttributes(a.Attributes()), comparableAttributes(b.Attributes())
+    for _, k := range sortedKeys(aAttrs) {
+        bv, ok := bAttrs[k]
+        switch {
+        case !ok:
+            diffs = append(diffs, fmt.Sprintf("span %q: attribute %q removed (was %q)", path, k, aAttrs[k]))
+        case bv != aAttrs[k]:
+            diffs = append(diffs, fmt.Sprintf("span %q: attribute %q changed from %q to %q", path, k, aAttrs[k], bv))
+        }
+    }
+    for _, k := range sortedKeys(bAttrs) {
+        if _, ok := aAttrs[k]; !ok {
+            diffs = append(diffs, fmt.Sprintf("span %q: attribute %q added (%q)", path, k, bAttrs[k]))
+        }
+    }
+
+    aEvents, bEvents := eventNames(a.Events()), eventNames(b.Events())
+    if strings.Join(aEvents, "\x00") != strings.Join(bEvents, "\x00") {
+        diffs = append(diffs, fmt.Sprintf("span %q: events changed from %v to %v", path, aEvents, bEvents))
+    }
+    return diffs
+}
+
+// Attribute values by key as "TYPE:value" strings, skipping
+// volatile keys
+func comparableAttributes(attrs []attribute.KeyValue) map[string]string {
+    out := make(map[string]string, len(attrs))
+    for _, kv := range attrs {
+        if !volatileAttributeKeys[kv.Key] {
+            out[string(kv.Key)] = kv.Value.Type().String() + ":" + kv.Value.Emit()
+        }
+    }
+    return out
+}
+
+func eventNames(events []trace.Event) []string {
+    names := make([]string, len(events))
+    for i, ev := range events {
+        names[i] = ev.Name
+    }
+    return names
+}
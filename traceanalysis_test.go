@@ -0,0 +1,223 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Root "request" with two "query" children, the second one's attributes
+// given
+func diffTestTrace(traceID byte, first, second []attribute.KeyValue) []trace.ReadOnlySpan {
+    spanContext := func(id byte) oteltrace.SpanContext {
+        return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{traceID}, SpanID: oteltrace.SpanID{traceID, id}})
+    }
+    return tracetest.SpanStubs{
+        {Name: "request", SpanContext: spanContext(1)},
+        {Name: "query", SpanContext: spanContext(2), Parent: spanContext(1), Attributes: first},
+        {Name: "query", SpanContext: spanContext(3), Parent: spanContext(1), Attributes: second},
+    }.Snapshots()
+}
+
+func TestDiffTracesReportsChangedAttribute(t *testing.T) {
+    a := diffTestTrace(1, []attribute.KeyValue{attribute.String("db.table", "users")}, []attribute.KeyValue{attribute.String("db.table", "orders")})
+    b := diffTestTrace(2, []attribute.KeyValue{attribute.String("db.table", "users")}, []attribute.KeyValue{attribute.String("db.table", "items")})
+
+    diffs := DiffTraces(a, b)
+    if len(diffs) != 1 || !strings.Contains(diffs[0], `attribute "db.table" changed from "STRING:orders" to "STRING:items"`) {
+        t.Errorf("diffs = %q", diffs)
+    }
+}
+
+func TestDiffTracesIgnoresSiblingOrderAndVolatileAttributes(t *testing.T) {
+    users := []attribute.KeyValue{attribute.String("db.table", "users"), attribute.Bool("slow", true)}
+    orders := []attribute.KeyValue{attribute.String("db.table", "orders"), attribute.Int("repeat.count", 3)}
+    a := diffTestTrace(1, users, orders)
+    b := diffTestTrace(2, orders[:1], users[:1])
+
+    if diffs := DiffTraces(a, b); diffs != nil {
+        t.Errorf("diffs = %q", diffs)
+    }
+}
+
+// Write spans to a protofile exporter file under t's temporary directory
+func writeSpanFile(t *testing.T, name string, spans []trace.ReadOnlySpan) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    e, err := newProtoFileExporter(path, encodingProtobuf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(context.Background(), spans); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestDiffSpanFiles(t *testing.T) {
+    a := writeSpanFile(t, "a.pb", diffTestTrace(1, nil, []attribute.KeyValue{attribute.Int("rows", 1)}))
+    b := writeSpanFile(t, "b.pb", diffTestTrace(2, nil, []attribute.KeyValue{attribute.Int("rows", 2)}))
+
+    var out bytes.Buffer
+    differ, err := diffSpanFiles(&out, a, b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !differ || !strings.Contains(out.String(), `attribute "rows" changed`) {
+        t.Errorf("differ = %v, output %q", differ, out.String())
+    }
+    if differ, err := diffSpanFiles(&out, a, a); err != nil || differ {
+        t.Errorf("same file: differ = %v, err = %v", differ, err)
+    }
+}
+
+func TestWriteSpanReportStats(t *testing.T) {
+    path := writeSpanFile(t, "spans.pb", append(diffTestTrace(1, nil, nil), diffTestTrace(2, nil, nil)...))
+
+    var out bytes.Buffer
+    if err := writeSpanReport(&out, "stats", path); err != nil {
+        t.Fatal(err)
+    }
+    var report struct {
+        Traces []traceStats `json:"traces"`
+    }
+    if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+        t.Fatal(err)
+    }
+    if len(report.Traces) != 2 || report.Traces[0].SpanCount != 3 {
+        t.Errorf("report = %+v", report)
+    }
+    if err := writeSpanReport(&out, "unknown", path); err == nil {
+        t.Error("unknown report accepted")
+    }
+}
+
+func TestWriteSpanReportDOT(t *testing.T) {
+    path := writeSpanFile(t, "spans.pb", append(diffTestTrace(1, nil, nil), diffTestTrace(2, nil, nil)...))
+
+    var out bytes.Buffer
+    if err := writeSpanReport(&out, "dot", path); err != nil {
+        t.Fatal(err)
+    }
+    if n := strings.Count(out.String(), "digraph trace {"); n != 2 {
+        t.Errorf("%d digraphs, want one per trace:\n%s", n, out.String())
+    }
+    if n := strings.Count(out.String(), " -> "); n != 4 {
+        t.Errorf("%d edges, want 4:\n%s", n, out.String())
+    }
+}
+
+func TestDependencyEdgesKeepTracesApart(t *testing.T) {
+    // Both traces reuse span IDs 1 and 2
+    span := func(traceID, id, parent byte, service string) tracetest.SpanStub {
+        stub := tracetest.SpanStub{
+            Name:        service,
+            SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{traceID}, SpanID: oteltrace.SpanID{id}}),
+            Attributes:  []attribute.KeyValue{attribute.String("service.name", service)},
+        }
+        if parent != 0 {
+            stub.Parent = oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{traceID}, SpanID: oteltrace.SpanID{parent}})
+        }
+        return stub
+    }
+    spans := tracetest.SpanStubs{
+        span(1, 1, 0, "frontend"),
+        span(1, 2, 1, "api"),
+        span(2, 1, 0, "worker"),
+        span(2, 2, 1, "db"),
+    }.Snapshots()
+
+    edges := dependencyEdges(spans)
+    want := []Edge{{Caller: "frontend", Callee: "api", Calls: 1}, {Caller: "worker", Callee: "db", Calls: 1}}
+    if len(edges) != len(want) {
+        t.Fatalf("edges = %+v, want %+v", edges, want)
+    }
+    for i := range want {
+        if edges[i] != want[i] {
+            t.Errorf("edge %d = %+v, want %+v", i, edges[i], want[i])
+        }
+    }
+
+    path := writeSpanFile(t, "spans.pb", spans)
+    var out bytes.Buffer
+    if err := writeSpanReport(&out, "deps", path); err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(out.String(), `"caller": "worker"`) {
+        t.Errorf("deps report %s", out.String())
+    }
+}
+
+func TestIsTraceComplete(t *testing.T) {
+    complete := diffTestTrace(1, nil, nil)
+    tests := []struct {
+        name  string
+        spans []trace.ReadOnlySpan
+        want  bool
+    }{
+        {"complete", complete, true},
+        {"root only", complete[:1], true},
+        {"missing root", complete[1:], false},
+        {"orphaned child", append(diffTestTrace(1, nil, nil), tracetest.SpanStub{
+            Name:        "late",
+            SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{1}, SpanID: oteltrace.SpanID{1, 9}}),
+            Parent:      oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: oteltrace.TraceID{1}, SpanID: oteltrace.SpanID{1, 8}}),
+        }.Snapshot()), false},
+        {"empty", nil, false},
+    }
+    for _, tt := range tests {
+        if got := isTraceComplete(tt.spans); got != tt.want {
+            t.Errorf("%s: isTraceComplete = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestReplayFileRoundTrip(t *testing.T) {
+    spans := diffTestTrace(7, []attribute.KeyValue{attribute.String("db.table", "users")}, []attribute.KeyValue{attribute.Int("rows", 3)})
+    jsonPath := filepath.Join(t.TempDir(), "spans.json")
+    e, err := newProtoFileExporter(jsonPath, encodingJSON)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := e.ExportSpans(context.Background(), spans); err != nil {
+        t.Fatal(err)
+    }
+    if err := e.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+
+    for encoding, path := range map[exportEncoding]string{encodingProtobuf: writeSpanFile(t, "spans.pb", spans), encodingJSON: jsonPath} {
+        exporter := tracetest.NewInMemoryExporter()
+        if err := ReplayFile(context.Background(), path, exporter); err != nil {
+            t.Fatalf("%s: %v", encoding, err)
+        }
+        got := exporter.GetSpans()
+        if len(got) != len(spans) {
+            t.Fatalf("%s: replayed %d spans, want %d", encoding, len(got), len(spans))
+        }
+        for i, want := range spans {
+            if got[i].Name != want.Name() || got[i].SpanContext.SpanID() != want.SpanContext().SpanID() ||
+                got[i].SpanContext.TraceID() != want.SpanContext().TraceID() || got[i].Parent.SpanID() != want.Parent().SpanID() {
+                t.Errorf("%s: span %d = %s %s (parent %s), want %s %s (parent %s)", encoding, i,
+                    got[i].Name, got[i].SpanContext.SpanID(), got[i].Parent.SpanID(), want.Name(), want.SpanContext().SpanID(), want.Parent().SpanID())
+            }
+            if gotSet, wantSet := attribute.NewSet(got[i].Attributes...), attribute.NewSet(want.Attributes()...); !gotSet.Equals(&wantSet) {
+                t.Errorf("%s: span %d attributes = %v, want %v", encoding, i, got[i].Attributes, want.Attributes())
+            }
+        }
+        if diffs := DiffTraces(spans, tracetest.SpanStubs(got).Snapshots()); diffs != nil {
+            t.Errorf("%s: replayed trace differs: %q", encoding, diffs)
+        }
+    }
+}
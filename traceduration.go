@@ -0,0 +1,152 @@
+package main
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Limits on the spans traceDurationProcessor remembers: traces whose root
+// has not ended within traceDurationTTL are evicted, as are the oldest traces
+// once maxBufferedTraces are held. Up to as many ended roots are remembered
+// for the same TTL so that their late children are not remembered again.
+const (
+    traceDurationTTL  = 5 * time.Minute
+    maxBufferedTraces = 1024
+)
+
+// Span processor recording trace.duration_ms on each local root span: the
+// time from the earliest start to the latest end among the trace's spans.
+// Children are passed on as soon as they end and only remembered until their
+// root ends, so the duration covers the children that ended before the root;
+// later ones pass through uncounted. Unsampled spans, which are never
+// exported, pass straight through.
+type traceDurationProcessor struct {
+    next trace.SpanProcessor
+    now  func() time.Time
+
+    mu        sync.Mutex
+    traces    map[oteltrace.TraceID]*bufferedTrace
+    ended     map[oteltrace.TraceID]time.Time
+    lastSweep time.Time
+}
+
+// Ended spans of a trace whose root has not ended yet, already passed on
+type bufferedTrace struct {
+    spans []trace.ReadOnlySpan
+    first time.Time
+}
+
+func newTraceDurationProcessor(next trace.SpanProcessor) *traceDurationProcessor {
+    return &traceDurationProcessor{
+        next:   next,
+        now:    time.Now,
+        traces: make(map[oteltrace.TraceID]*bufferedTrace),
+        ended:  make(map[oteltrace.TraceID]time.Time),
+    }
+}
+
+func (p *traceDurationProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *traceDurationProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if !s.SpanContext().IsSampled() {
+        p.next.OnEnd(s)
+        return
+    }
+    traceID := s.SpanContext().TraceID()
+    now := p.now()
+
+    p.mu.Lock()
+    p.evict(now)
+    if parent := s.Parent(); parent.IsValid() && !parent.IsRemote() {
+        if _, done := p.ended[traceID]; !done {
+            t, ok := p.traces[traceID]
+            if !ok {
+                if len(p.traces) >= maxBufferedTraces {
+                    p.evictOldest()
+                }
+                t = &bufferedTrace{first: now}
+                p.traces[traceID] = t
+            }
+            t.spans = append(t.spans, s)
+        }
+        p.mu.Unlock()
+        p.next.OnEnd(s)
+        return
+    }
+
+    var spans []trace.ReadOnlySpan
+    if t, ok := p.traces[traceID]; ok {
+        spans = t.spans
+        delete(p.traces, traceID)
+    }
+    if len(p.ended) >= maxBufferedTraces {
+        p.forgetOldestEnded()
+    }
+    p.ended[traceID] = now
+    p.mu.Unlock()
+
+    d := traceDuration(append(spans, s))
+    p.next.OnEnd(withAttributes(s, attribute.Float64("trace.duration_ms", float64(d.Microseconds())/1000)))
+}
+
+// Drop buffered traces and ended roots older than the TTL, sweeping at most
+// once per TTL. Called with mu held.
+func (p *traceDurationProcessor) evict(now time.Time) {
+    if now.Sub(p.lastSweep) < traceDurationTTL {
+        return
+    }
+    p.lastSweep = now
+    for id, t := range p.traces {
+        if now.Sub(t.first) >= traceDurationTTL {
+            delete(p.traces, id)
+        }
+    }
+    for id, ended := range p.ended {
+        if now.Sub(ended) >= traceDurationTTL {
+            delete(p.ended, id)
+        }
+    }
+}
+
+// Drop the trace buffered longest. Called with mu held.
+func (p *traceDurationProcessor) evictOldest() {
+    var oldest oteltrace.TraceID
+    var first time.Time
+    for id, t := range p.traces {
+        if first.IsZero() || t.first.Before(first) {
+            oldest, first = id, t.first
+        }
+    }
+    delete(p.traces, oldest)
+}
+
+// Forget the root that ended longest ago. Called with mu held.
+func (p *traceDurationProcessor) forgetOldestEnded() {
+    var oldest oteltrace.TraceID
+    var first time.Time
+    for id, ended := range p.ended {
+        if first.IsZero() || ended.Before(first) {
+            oldest, first = id, ended
+        }
+    }
+    delete(p.ended, oldest)
+}
+
+func (p *traceDurationProcessor) Shutdown(ctx context.Context) error {
+    p.mu.Lock()
+    p.traces = make(map[oteltrace.TraceID]*bufferedTrace)
+    p.ended = make(map[oteltrace.TraceID]time.Time)
+    p.mu.Unlock()
+    return p.next.Shutdown(ctx)
+}
+
+func (p *traceDurationProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+    "context"
+    "encoding/binary"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Span processor collecting the spans it is handed
+type collectingProcessor struct {
+    spans []trace.ReadOnlySpan
+}
+
+func (p *collectingProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+func (p *collectingProcessor) OnEnd(s trace.ReadOnlySpan)                   { p.spans = append(p.spans, s) }
+func (p *collectingProcessor) Shutdown(context.Context) error               { return nil }
+func (p *collectingProcessor) ForceFlush(context.Context) error             { return nil }
+
+func durationTestSpan(traceNum uint16, id, parent byte) trace.ReadOnlySpan {
+    traceID := oteltrace.TraceID{1}
+    binary.BigEndian.PutUint16(traceID[14:], traceNum)
+    stub := tracetest.SpanStub{
+        Name:        "span",
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{id}, TraceFlags: oteltrace.FlagsSampled}),
+    }
+    if parent != 0 {
+        stub.Parent = oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{parent}, TraceFlags: oteltrace.FlagsSampled})
+    }
+    return stub.Snapshot()
+}
+
+func TestTraceDurationDropsLateChildren(t *testing.T) {
+    p := newTraceDurationProcessor(&collectingProcessor{})
+    p.OnEnd(durationTestSpan(1, 1, 0))
+    p.OnEnd(durationTestSpan(1, 2, 1))
+    if len(p.traces) != 0 {
+        t.Errorf("child ending after its root buffered: %d traces held", len(p.traces))
+    }
+}
+
+func TestTraceDurationEvictsUnfinishedTraces(t *testing.T) {
+    now := time.Unix(0, 0)
+    p := newTraceDurationProcessor(&collectingProcessor{})
+    p.now = func() time.Time { return now }
+
+    p.OnEnd(durationTestSpan(1, 2, 1))
+    now = now.Add(traceDurationTTL)
+    p.OnEnd(durationTestSpan(2, 2, 1))
+    if len(p.traces) != 1 {
+        t.Error("trace whose root never ended kept past the TTL")
+    }
+
+    for i := 0; i < maxBufferedTraces+10; i++ {
+        p.OnEnd(durationTestSpan(uint16(i+3), 2, 1))
+    }
+    if len(p.traces) > maxBufferedTraces {
+        t.Errorf("%d traces buffered, want at most %d", len(p.traces), maxBufferedTraces)
+    }
+}
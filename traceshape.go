@@ -0,0 +1,92 @@
+package main
+
+import (
+    "context"
+    "sync"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Span processor recording the shape of each trace on its local root span:
+// trace.span_count is the number of spans started under the root (including
+// the root itself) and trace.max_depth the deepest level, the root being 1.
+// Spans started after their root has ended, and unsampled spans, are not
+// counted.
+type traceShapeProcessor struct {
+    mu     sync.Mutex
+    traces map[oteltrace.TraceID]*traceShape
+}
+
+type traceShape struct {
+    root     trace.ReadWriteSpan
+    depths   map[oteltrace.SpanID]int
+    count    int
+    maxDepth int
+}
+
+func newTraceShapeProcessor() *traceShapeProcessor {
+    return &traceShapeProcessor{traces: make(map[oteltrace.TraceID]*traceShape)}
+}
+
+func (p *traceShapeProcessor) OnStart(_ context.Context, s trace.ReadWriteSpan) {
+    sc := s.SpanContext()
+    if !sc.IsSampled() {
+        return
+    }
+    parent := s.Parent()
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    // A span without a local parent is the root of its in-process trace
+    if !parent.IsValid() || parent.IsRemote() {
+        p.traces[sc.TraceID()] = &traceShape{
+            root:     s,
+            depths:   map[oteltrace.SpanID]int{sc.SpanID(): 1},
+            count:    1,
+            maxDepth: 1,
+        }
+        s.SetAttributes(traceShapeAttributes(1, 1)...)
+        return
+    }
+
+    shape, ok := p.traces[sc.TraceID()]
+    if !ok {
+        return
+    }
+    depth := shape.depths[parent.SpanID()] + 1
+    shape.depths[sc.SpanID()] = depth
+    shape.count++
+    if depth > shape.maxDepth {
+        shape.maxDepth = depth
+    }
+    shape.root.SetAttributes(traceShapeAttributes(shape.count, shape.maxDepth)...)
+}
+
+func (p *traceShapeProcessor) OnEnd(s trace.ReadOnlySpan) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    sc := s.SpanContext()
+    if shape, ok := p.traces[sc.TraceID()]; ok && shape.root.SpanContext().SpanID() == sc.SpanID() {
+        delete(p.traces, sc.TraceID())
+    }
+}
+
+func (p *traceShapeProcessor) Shutdown(context.Context) error {
+    p.mu.Lock()
+    p.traces = make(map[oteltrace.TraceID]*traceShape)
+    p.mu.Unlock()
+    return nil
+}
+
+func (p *traceShapeProcessor) ForceFlush(context.Context) error { return nil }
+
+func traceShapeAttributes(count, maxDepth int) []attribute.KeyValue {
+    return []attribute.KeyValue{
+        attribute.Int("trace.span_count", count),
+        attribute.Int("trace.max_depth", maxDepth),
+    }
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceShapeOnKnownTree(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(newTraceShapeProcessor()), trace.WithSpanProcessor(recorder))
+    tracer := tp.Tracer("test")
+
+    // root > (a > (a1, a2 > a2i), b)
+    ctx, root := tracer.Start(context.Background(), "root")
+    actx, a := tracer.Start(ctx, "a")
+    _, a1 := tracer.Start(actx, "a1")
+    a1.End()
+    a2ctx, a2 := tracer.Start(actx, "a2")
+    _, a2i := tracer.Start(a2ctx, "a2i")
+    a2i.End()
+    a2.End()
+    a.End()
+    _, b := tracer.Start(ctx, "b")
+    b.End()
+    root.End()
+
+    // Started after the root ended, so not counted
+    _, late := tracer.Start(ctx, "late")
+    late.End()
+
+    ended := recorder.Ended()
+    var rootSpan trace.ReadOnlySpan
+    for _, s := range ended {
+        if s.Name() == "root" {
+            rootSpan = s
+        }
+    }
+    AssertSpanAttribute(t, rootSpan, "trace.span_count", 6)
+    AssertSpanAttribute(t, rootSpan, "trace.max_depth", 4)
+}
+
+func TestTraceShapeForgetsEndedRoots(t *testing.T) {
+    p := newTraceShapeProcessor()
+    tp := trace.NewTracerProvider(trace.WithSpanProcessor(p))
+    // A child of a span from another process starts a new local root
+    remote := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+        TraceID:    oteltrace.TraceID{1},
+        SpanID:     oteltrace.SpanID{1},
+        TraceFlags: oteltrace.FlagsSampled,
+        Remote:     true,
+    })
+    _, span := tp.Tracer("test").Start(oteltrace.ContextWithRemoteSpanContext(context.Background(), remote), "handler")
+    span.End()
+    if len(p.traces) != 0 {
+        t.Errorf("%d traces still held after their roots ended", len(p.traces))
+    }
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "path"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// How an oversized string attribute value is shortened
+type truncateStrategy string
+
+const (
+    // Keep the start of the value
+    truncateEnd truncateStrategy = "end"
+    // Keep the start and the end, cutting out the middle
+    truncateMiddle truncateStrategy = "middle"
+    // Replace the value by its SHA-256
+    truncateHash truncateStrategy = "hash"
+)
+
+// Strategy applied to values longer than limit bytes of the attributes whose
+// key matches pattern (a path.Match glob such as "http.*")
+type truncateRule struct {
+    pattern  string
+    strategy truncateStrategy
+    limit    int
+}
+
+// Shorten s to at most limit bytes with the rule's strategy
+func (r truncateRule) apply(s string) string {
+    if len(s) <= r.limit {
+        return s
+    }
+    switch r.strategy {
+    case truncateMiddle:
+        return truncateMiddleString(s, r.limit)
+    case truncateHash:
+        sum := sha256.Sum256([]byte(s))
+        // A limit below the digest length keeps a digest prefix
+        digest := "sha256:" + hex.EncodeToString(sum[:])
+        if len(digest) > r.limit {
+            digest = digest[:r.limit]
+        }
+        return digest
+    }
+    return truncateEndString(s, r.limit)
+}
+
+// Keep the start of s and the truncation marker within limit bytes, or only
+// the start when the marker does not fit
+func truncateEndString(s string, limit int) string {
+    if limit <= len(truncatedMarker) {
+        return cutUTF8(s, limit)
+    }
+    return cutUTF8(s, limit-len(truncatedMarker)) + truncatedMarker
+}
+
+// Keep about half of the limit bytes left after the truncation marker from
+// each end of s, cutting on rune boundaries. When the marker does not fit,
+// only the start is kept.
+func truncateMiddleString(s string, limit int) string {
+    budget := limit - len(truncatedMarker)
+    if budget <= 0 {
+        return cutUTF8(s, limit)
+    }
+    head := cutUTF8(s, budget/2)
+    tailStart := len(s) - (budget - len(head))
+    for tailStart < len(s) && !utf8.RuneStart(s[tailStart]) {
+        tailStart++
+    }
+    return head + truncatedMarker + s[tailStart:]
+}
+
+// Span processor shortening oversized string attribute values with the
+// first rule whose pattern matches the key
+type attributeTruncateProcessor struct {
+    next  trace.SpanProcessor
+    rules []truncateRule
+}
+
+func newAttributeTruncateProcessor(next trace.SpanProcessor, rules []truncateRule) *attributeTruncateProcessor {
+    return &attributeTruncateProcessor{next: next, rules: rules}
+}
+
+func (p *attributeTruncateProcessor) rule(key attribute.Key) (truncateRule, bool) {
+    for _, r := range p.rules {
+        if ok, _ := path.Match(r.pattern, string(key)); ok {
+            return r, true
+        }
+    }
+    return truncateRule{}, false
+}
+
+func (p *attributeTruncateProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *attributeTruncateProcessor) OnEnd(s trace.ReadOnlySpan) {
+    var attrs []attribute.KeyValue
+    for i, kv := range s.Attributes() {
+        if kv.Value.Type() != attribute.STRING {
+            continue
+        }
+        r, ok := p.rule(kv.Key)
+        if !ok {
+            continue
+        }
+        if v := kv.Value.AsString(); len(v) > r.limit {
+            if attrs == nil {
+                attrs = append([]attribute.KeyValue(nil), s.Attributes()...)
+            }
+            attrs[i] = kv.Key.String(r.apply(v))
+        }
+    }
+    if attrs != nil {
+        s = replaceAttributes(s, attrs)
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *attributeTruncateProcessor) Shutdown(ctx context.Context) error {
+    return p.next.Shutdown(ctx)
+}
+
+func (p *attributeTruncateProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
+
+// Parse "pattern=strategy:limit" rules separated by commas, e.g.
+// "http.url=middle:256,db.statement=hash:1024,*=end:4096"
+func parseTruncateRules(value string) ([]truncateRule, error) {
+    var rules []truncateRule
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        pattern, spec, ok := strings.Cut(item, "=")
+        pattern = strings.TrimSpace(pattern)
+        strategy, rawLimit, ok2 := strings.Cut(strings.TrimSpace(spec), ":")
+        if !ok || !ok2 || pattern == "" {
+            return nil, fmt.Errorf("invalid truncation rule %q: want pattern=strategy:limit", item)
+        }
+        if _, err := path.Match(pattern, ""); err != nil {
+            return nil, fmt.Errorf("invalid truncation rule %q: %v", item, err)
+        }
+        rule := truncateRule{pattern: pattern, strategy: truncateStrategy(strings.TrimSpace(strategy))}
+        switch rule.strategy {
+        case truncateEnd, truncateMiddle, truncateHash:
+        default:
+            return nil, fmt.Errorf("invalid truncation rule %q: strategy must be %q, %q or %q", item, truncateEnd, truncateMiddle, truncateHash)
+        }
+        limit, err := strconv.Atoi(strings.TrimSpace(rawLimit))
+        if err != nil || limit <= 0 {
+            return nil, fmt.Errorf("invalid truncation rule %q: limit must be a positive number of bytes", item)
+        }
+        rule.limit = limit
+        rules = append(rules, rule)
+    }
+    return rules, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+    "strings"
+    "testing"
+    "unicode/utf8"
+)
+
+func TestTruncateRulesStayWithinLimit(t *testing.T) {
+    value := strings.Repeat("héllo wörld ", 40)
+    for _, strategy := range []truncateStrategy{truncateEnd, truncateMiddle, truncateHash} {
+        for _, limit := range []int{1, 5, len(truncatedMarker), 20, 70, 71, 100, 256} {
+            got := truncateRule{strategy: strategy, limit: limit}.apply(value)
+            if len(got) > limit {
+                t.Errorf("%s:%d: %d bytes", strategy, limit, len(got))
+            }
+            if !utf8.ValidString(got) {
+                t.Errorf("%s:%d: invalid UTF-8 %q", strategy, limit, got)
+            }
+        }
+    }
+}
+
+func TestTruncateStrategies(t *testing.T) {
+    value := "abcdefghij" + strings.Repeat("x", 100) + "0123456789"
+    if got := (truncateRule{strategy: truncateEnd, limit: 24}).apply(value); got != "abcdefghij"+truncatedMarker {
+        t.Errorf("end: %q", got)
+    }
+    if got := (truncateRule{strategy: truncateMiddle, limit: 34}).apply(value); got != "abcdefghij"+truncatedMarker+"0123456789" {
+        t.Errorf("middle: %q", got)
+    }
+    if got := (truncateRule{strategy: truncateHash, limit: 100}).apply(value); !strings.HasPrefix(got, "sha256:") || len(got) != 71 {
+        t.Errorf("hash: %q", got)
+    }
+    if got := (truncateRule{strategy: truncateEnd, limit: 200}).apply(value); got != value {
+        t.Errorf("short value changed: %q", got)
+    }
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "sync"
+)
+
+// Validation result for one file of log entries
+type FileReport struct {
+    Valid  int      `json:"valid"`
+    Errors []string `json:"errors,omitempty"`
+}
+
+// Maximum length of a single .jsonl line
+const maxEntryLineSize = 1 << 20
+
+// Validate every .jsonl (one entry per line) and .json (an entry or an array
+// of entries) file under dir, a few files at a time. Reports are keyed by
+// path relative to dir; the error is only set when dir cannot be walked.
+func ValidateDir(dir string) (map[string]FileReport, error) {
+    var paths []string
+    err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        if ext := strings.ToLower(filepath.Ext(path)); !d.IsDir() && (ext == ".jsonl" || ext == ".json") {
+            paths = append(paths, path)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    reports := make(map[string]FileReport, len(paths))
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+    sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+    for _, path := range paths {
+        wg.Add(1)
+        sem <- struct{}{}
+        go func(path string) {
+            defer wg.Done()
+            defer func() { <-sem }()
+            report := validateFile(path)
+            name, err := filepath.Rel(dir, path)
+            if err != nil {
+                name = path
+            }
+            mu.Lock()
+            reports[name] = report
+            mu.Unlock()
+        }(path)
+    }
+    wg.Wait()
+    return reports, nil
+}
+
+// Body with continuation lines appended, one per line
+func appendContinuation(body string, lines []string) string {
+    if len(lines) == 0 {
+        return body
+    }
+    return body + "\n" + strings.Join(lines, "\n")
+}
+
+func validateFile(path string) FileReport {
+    var report FileReport
+    check := func(where string, data []byte, continuation ...string) {
+        l, err := decodeLogEntry(data)
+        if err != nil {
+            report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", where, err))
+            return
+        }
+        l.Body = appendContinuation(l.Body, continuation)
+        if err := l.Validate(); err != nil {
+            report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", where, err))
+            return
+        }
+        report.Valid++
+    }
+
+    if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+        f, err := os.Open(path)
+        if err != nil {
+            report.Errors = append(report.Errors, err.Error())
+            return report
+        }
+        defer f.Close()
+        scanner := bufio.NewScanner(f)
+        scanner.Buffer(nil, maxEntryLineSize)
+        // Lines not starting with "{" continue the previous entry, e.g. a
+        // stack trace split across lines, so an entry is only checked once
+        // the next one starts
+        var entry []byte
+        var entryLine int
+        var continuation []string
+        flush := func() {
+            if entry != nil {
+                check(fmt.Sprintf("line %d", entryLine), entry, continuation...)
+            }
+            entry, continuation = nil, nil
+        }
+        for line := 1; scanner.Scan(); line++ {
+            data := bytes.TrimSpace(scanner.Bytes())
+            switch {
+            case len(data) == 0:
+            case data[0] == '{':
+                flush()
+                entry, entryLine = bytes.Clone(data), line
+            case entry == nil:
+                report.Errors = append(report.Errors, fmt.Sprintf("line %d: continuation line without an entry", line))
+            default:
+                continuation = append(continuation, strings.TrimRight(scanner.Text(), "\r"))
+            }
+        }
+        flush()
+        if err := scanner.Err(); err != nil {
+            report.Errors = append(report.Errors, err.Error())
+        }
+        return report
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        report.Errors = append(report.Errors, err.Error())
+        return report
+    }
+    data = bytes.TrimSpace(data)
+    if !bytes.HasPrefix(data, []byte("[")) {
+        check("entry", data)
+        return report
+    }
+    var entries []json.RawMessage
+    if err := json.Unmarshal(data, &entries); err != nil {
+        report.Errors = append(report.Errors, err.Error())
+        return report
+    }
+    for i, entry := range entries {
+        check(fmt.Sprintf("entry %d", i), entry)
+    }
+    return report
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestValidateDirMixedFiles(t *testing.T) {
+    dir := t.TempDir()
+    files := map[string]string{
+        "good.jsonl": `{"Body": "one"}
+{"Body": "two", "host.ip": "10.0.0.1"}
+`,
+        "mixed.jsonl": `{"Body": "ok"}
+{"Body": "bad ip", "host.ip": "999.1.1.1"}
+{"Body": "panic: boom"}
+goroutine 1 [running]:
+{"Body": broken
+`,
+        "array.json":       `[{"Body": "a"}, {"Body": "b", "host.mac": "nope"}]`,
+        "single.json":      `{"Body": "single"}`,
+        "nested/one.jsonl": `{"Body": "nested"}`,
+        "notes.txt":        "not a log file",
+    }
+    for name, content := range files {
+        path := filepath.Join(dir, name)
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    reports, err := ValidateDir(dir)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        name       string
+        valid      int
+        errorLines []string
+    }{
+        {"good.jsonl", 2, nil},
+        {"mixed.jsonl", 2, []string{"line 2: host.ip", "line 5:"}},
+        {"array.json", 1, []string{"entry 1: host.mac"}},
+        {"single.json", 1, nil},
+        {filepath.Join("nested", "one.jsonl"), 1, nil},
+    }
+    if len(reports) != len(tests) {
+        t.Errorf("got reports for %d files, want %d: %v", len(reports), len(tests), reports)
+    }
+    for _, tt := range tests {
+        report, ok := reports[tt.name]
+        if !ok {
+            t.Errorf("no report for %s", tt.name)
+            continue
+        }
+        if report.Valid != tt.valid || len(report.Errors) != len(tt.errorLines) {
+            t.Errorf("%s: report = %+v, want %d valid and %d errors", tt.name, report, tt.valid, len(tt.errorLines))
+            continue
+        }
+        for i, prefix := range tt.errorLines {
+            if !strings.HasPrefix(report.Errors[i], prefix) {
+                t.Errorf("%s: error %d = %q, want prefix %q", tt.name, i, report.Errors[i], prefix)
+            }
+        }
+    }
+
+    if _, err := ValidateDir(filepath.Join(dir, "missing")); err == nil {
+        t.Error("missing directory validated without error")
+    }
+}
+
+func TestValidateFileMergesContinuationLines(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "trace.jsonl")
+    fixture := "  at the top\n" +
+        `{"Body": "panic: runtime error"}` + "\n" +
+        "goroutine 1 [running]:\n" +
+        "main.main()\r\n" +
+        "\t/app/main.go:12 +0x1d\n" +
+        "\n" +
+        `{"Body": "recovered"}` + "\n"
+    if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    report := validateFile(path)
+    if report.Valid != 2 {
+        t.Errorf("valid = %d, want 2 (stack trace lines merged into the first entry)", report.Valid)
+    }
+    if len(report.Errors) != 1 || !strings.HasPrefix(report.Errors[0], "line 1: continuation line without an entry") {
+        t.Errorf("errors = %q, want only the orphan line 1", report.Errors)
+    }
+
+    got := appendContinuation("panic: runtime error", []string{"goroutine 1 [running]:", "main.main()", "\t/app/main.go:12 +0x1d"})
+    want := "panic: runtime error\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d"
+    if got != want {
+        t.Errorf("merged body = %q, want %q", got, want)
+    }
+}
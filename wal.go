@@ -0,0 +1,287 @@
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "sync"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Write-ahead log of spans queued for export. Every ended span is appended
+// (in the protofile framing) before it enters the export queue. Export
+// queues are first in, first out, so once a span is acknowledged every span
+// logged before it has been exported, failed to export or been dropped by
+// the queue. The log then keeps only the frames of failed spans up to that
+// point, and those of all later spans. After a crash, or a restart after
+// failed exports, the log holds the spans that may not have been exported.
+// Delivery is at least once: spans exported just before a crash are
+// replayed again.
+type spanWAL struct {
+    mu      sync.Mutex
+    path    string
+    file    *os.File
+    size    int64
+    records []walRecord
+}
+
+// Span logged in the WAL, the file offsets its frame starts and ends at, and
+// whether its export failed, so it must be kept for replay
+type walRecord struct {
+    key        spanKey
+    start, end int64
+    failed     bool
+}
+
+type spanKey struct {
+    traceID oteltrace.TraceID
+    spanID  oteltrace.SpanID
+}
+
+func keyOfSpan(s trace.ReadOnlySpan) spanKey {
+    return spanKey{s.SpanContext().TraceID(), s.SpanContext().SpanID()}
+}
+
+func openSpanWAL(path string) (*spanWAL, error) {
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+    if err != nil {
+        return nil, fmt.Errorf("span wal: %w", err)
+    }
+    info, err := f.Stat()
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("span wal: %w", err)
+    }
+    return &spanWAL{path: path, file: f, size: info.Size()}, nil
+}
+
+// Export the spans persisted by a previous run, then clear the log. A torn
+// or corrupt frame (as left by a crash mid-write) ends the log: the frames
+// before it are still replayed, the rest is cut off and reported in the
+// error along with the replayed count.
+func (w *spanWAL) Replay(ctx context.Context, exporter trace.SpanExporter) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+        return 0, fmt.Errorf("span wal replay: %w", err)
+    }
+    data, err := io.ReadAll(w.file)
+    if err != nil {
+        return 0, fmt.Errorf("span wal replay: %w", err)
+    }
+    stubs, good, readErr := readWALFrames(data)
+    if readErr != nil {
+        // Cut the log at the last whole frame so new frames are readable
+        if err := w.file.Truncate(good); err != nil {
+            return 0, fmt.Errorf("span wal replay: %w", err)
+        }
+        w.size = good
+        readErr = fmt.Errorf("span wal replay: discarded %d bytes after offset %d: %w", int64(len(data))-good, good, readErr)
+    }
+    if len(stubs) > 0 {
+        if err := exporter.ExportSpans(ctx, tracetest.SpanStubs(stubs).Snapshots()); err != nil {
+            return 0, errors.Join(fmt.Errorf("span wal replay: %w", err), readErr)
+        }
+    }
+    if err := w.file.Truncate(0); err != nil {
+        return len(stubs), errors.Join(fmt.Errorf("span wal replay: %w", err), readErr)
+    }
+    w.size = 0
+    return len(stubs), readErr
+}
+
+// Spans of the whole frames at the start of data, the offset those frames
+// end at and, if data does not end there, why the next frame is unreadable
+func readWALFrames(data []byte) ([]tracetest.SpanStub, int64, error) {
+    r := bytes.NewReader(data)
+    br := bufio.NewReader(r)
+    var stubs []tracetest.SpanStub
+    var good int64
+    for {
+        msg, err := protobufSerializer{}.Unmarshal(br)
+        if errors.Is(err, io.EOF) {
+            return stubs, good, nil
+        }
+        if err != nil {
+            return stubs, good, err
+        }
+        stubs = append(stubs, fromProtoTracesData(msg)...)
+        good = r.Size() - int64(r.Len()) - int64(br.Buffered())
+    }
+}
+
+// Wrap the export processor so ended spans are logged before being queued
+func (w *spanWAL) Processor(next trace.SpanProcessor) trace.SpanProcessor {
+    return &walProcessor{next: next, wal: w}
+}
+
+// Wrap the exporter so spans leaving the queue are accounted for: exported
+// ones are acknowledged, failed ones kept for replay
+func (w *spanWAL) Exporter(exporter trace.SpanExporter) trace.SpanExporter {
+    return &walExporter{SpanExporter: exporter, wal: w}
+}
+
+func (w *spanWAL) append(s trace.ReadOnlySpan) error {
+    frame, err := marshalProtoFrame([]trace.ReadOnlySpan{s})
+    if err != nil {
+        return err
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.file == nil {
+        return nil
+    }
+    if _, err := w.file.Write(frame); err != nil {
+        return fmt.Errorf("span wal: %w", err)
+    }
+    start := w.size
+    w.size += int64(len(frame))
+    w.records = append(w.records, walRecord{key: keyOfSpan(s), start: start, end: w.size})
+    return nil
+}
+
+// Mark spans whose export failed, keeping them in the log to be replayed on
+// the next start
+func (w *spanWAL) failed(spans []trace.ReadOnlySpan) {
+    keys := make(map[spanKey]bool, len(spans))
+    for _, s := range spans {
+        keys[keyOfSpan(s)] = true
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    for i := range w.records {
+        if keys[w.records[i].key] {
+            w.records[i].failed = true
+        }
+    }
+}
+
+// Acknowledge exported spans: the log is cut after the last of them, which
+// also discards spans logged earlier that the queue dropped, keeping only the
+// earlier spans whose export failed
+func (w *spanWAL) done(spans []trace.ReadOnlySpan) error {
+    acked := make(map[spanKey]bool, len(spans))
+    for _, s := range spans {
+        acked[keyOfSpan(s)] = true
+    }
+
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    last := -1
+    for i, r := range w.records {
+        if acked[r.key] {
+            last = i
+        }
+    }
+    if last < 0 || w.file == nil {
+        return nil
+    }
+    var keep []walRecord
+    for _, r := range w.records[:last+1] {
+        if r.failed && !acked[r.key] {
+            keep = append(keep, r)
+        }
+    }
+    keep = append(keep, w.records[last+1:]...)
+    if len(keep) == 0 {
+        w.records, w.size = nil, 0
+        return w.file.Truncate(0)
+    }
+    return w.compact(keep)
+}
+
+// Rewrite the log with only the frames of keep. They are written to a
+// temporary file renamed over the log, so a crash leaves either the old or
+// the new log.
+func (w *spanWAL) compact(keep []walRecord) error {
+    var kept []byte
+    records := make([]walRecord, len(keep))
+    for i, r := range keep {
+        frame := make([]byte, r.end-r.start)
+        if _, err := w.file.ReadAt(frame, r.start); err != nil {
+            return fmt.Errorf("span wal: %w", err)
+        }
+        r.start = int64(len(kept))
+        kept = append(kept, frame...)
+        r.end = int64(len(kept))
+        records[i] = r
+    }
+    tmp := w.path + ".tmp"
+    if err := os.WriteFile(tmp, kept, 0o644); err != nil {
+        return fmt.Errorf("span wal: %w", err)
+    }
+    if err := os.Rename(tmp, w.path); err != nil {
+        return fmt.Errorf("span wal: %w", err)
+    }
+    f, err := os.OpenFile(w.path, os.O_RDWR|os.O_APPEND, 0o644)
+    if err != nil {
+        return fmt.Errorf("span wal: %w", err)
+    }
+    w.file.Close()
+    w.file = f
+    w.records, w.size = records, int64(len(kept))
+    return nil
+}
+
+func (w *spanWAL) close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.file == nil {
+        return nil
+    }
+    err := w.file.Close()
+    w.file = nil
+    return err
+}
+
+type walProcessor struct {
+    next trace.SpanProcessor
+    wal  *spanWAL
+}
+
+func (p *walProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+    p.next.OnStart(parent, s)
+}
+
+func (p *walProcessor) OnEnd(s trace.ReadOnlySpan) {
+    if s.SpanContext().IsSampled() {
+        if err := p.wal.append(s); err != nil {
+            otel.Handle(err)
+        }
+    }
+    p.next.OnEnd(s)
+}
+
+func (p *walProcessor) Shutdown(ctx context.Context) error {
+    err := p.next.Shutdown(ctx)
+    if closeErr := p.wal.close(); err == nil {
+        err = closeErr
+    }
+    return err
+}
+
+func (p *walProcessor) ForceFlush(ctx context.Context) error {
+    return p.next.ForceFlush(ctx)
+}
+
+type walExporter struct {
+    trace.SpanExporter
+    wal *spanWAL
+}
+
+func (e *walExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+        e.wal.failed(spans)
+        return err
+    }
+    return e.wal.done(spans)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func walTestSpan(name string, id byte) trace.ReadOnlySpan {
+    return tracetest.SpanStub{
+        Name: name,
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+            TraceID:    oteltrace.TraceID{1},
+            SpanID:     oteltrace.SpanID{id},
+            TraceFlags: oteltrace.FlagsSampled,
+        }),
+    }.Snapshot()
+}
+
+func TestWALReplaysFramesBeforeTornFrame(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "spans.wal")
+    var data []byte
+    for i, name := range []string{"a", "b", "c"} {
+        frame, err := marshalProtoFrame([]trace.ReadOnlySpan{walTestSpan(name, byte(i+1))})
+        if err != nil {
+            t.Fatal(err)
+        }
+        if name == "c" {
+            frame = frame[:len(frame)/2]
+        }
+        data = append(data, frame...)
+    }
+    if err := os.WriteFile(path, data, 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    wal, err := openSpanWAL(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer wal.close()
+    exporter := tracetest.NewInMemoryExporter()
+    replayed, err := wal.Replay(context.Background(), exporter)
+    if err == nil {
+        t.Error("torn frame not reported")
+    }
+    if replayed != 2 || len(exporter.GetSpans()) != 2 {
+        t.Fatalf("replayed %d (exported %d), want the 2 whole frames", replayed, len(exporter.GetSpans()))
+    }
+
+    // The log is usable again: a new span can be appended and replayed
+    if err := wal.append(walTestSpan("d", 4)); err != nil {
+        t.Fatal(err)
+    }
+    exporter.Reset()
+    if replayed, err := wal.Replay(context.Background(), exporter); err != nil || replayed != 1 {
+        t.Fatalf("second replay = %d, %v; want 1, nil", replayed, err)
+    }
+}
+
+func TestWALCutsSpansDroppedByQueue(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "spans.wal")
+    wal, err := openSpanWAL(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer wal.close()
+    a, b, c := walTestSpan("a", 1), walTestSpan("b", 2), walTestSpan("c", 3)
+    for _, s := range []trace.ReadOnlySpan{a, b, c} {
+        if err := wal.append(s); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // a was dropped by a full queue, so only b is exported before c
+    if err := wal.done([]trace.ReadOnlySpan{b}); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    stubs, _, err := readWALFrames(data)
+    if err != nil || len(stubs) != 1 || stubs[0].Name != "c" {
+        t.Fatalf("log holds %v (%v), want only c", stubs, err)
+    }
+
+    if err := wal.done([]trace.ReadOnlySpan{c}); err != nil {
+        t.Fatal(err)
+    }
+    info, err := os.Stat(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.Size() != 0 {
+        t.Errorf("log holds %d bytes once every span was acknowledged, want 0", info.Size())
+    }
+}
+
+func TestWALKeepsFailedExportsForReplay(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "spans.wal")
+    wal, err := openSpanWAL(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    inner := &flakyExporter{fail: true}
+    exporter := wal.Exporter(inner)
+
+    a, b, c := walTestSpan("a", 1), walTestSpan("b", 2), walTestSpan("c", 3)
+    for _, s := range []trace.ReadOnlySpan{a, b, c} {
+        if err := wal.append(s); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := exporter.ExportSpans(context.Background(), []trace.ReadOnlySpan{a}); err == nil {
+        t.Fatal("export error not returned")
+    }
+    // A later successful export must not cut the failed span from the log
+    inner.fail = false
+    if err := exporter.ExportSpans(context.Background(), []trace.ReadOnlySpan{b}); err != nil {
+        t.Fatal(err)
+    }
+    if err := wal.close(); err != nil {
+        t.Fatal(err)
+    }
+
+    // Next start: the failed span and the one never exported are replayed
+    wal, err = openSpanWAL(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer wal.close()
+    replay := tracetest.NewInMemoryExporter()
+    replayed, err := wal.Replay(context.Background(), replay)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var names []string
+    for _, s := range replay.GetSpans() {
+        names = append(names, s.Name)
+    }
+    if replayed != 2 || len(names) != 2 || names[0] != "a" || names[1] != "c" {
+        t.Errorf("replayed %d spans %q, want the failed a and the pending c", replayed, names)
+    }
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+    defaultWarmupTimeout = 5 * time.Second
+    warmupSpanName       = "exporter.warmup"
+)
+
+// Export a single canary span, marked warmup.canary, so the exporter sets up
+// its connection before real load and the first real export does not pay
+// for it. The export is abandoned after timeout. It returns how long the
+// warmup took.
+func warmupExporter(ctx context.Context, exporter trace.SpanExporter, res *resource.Resource, timeout time.Duration) (time.Duration, error) {
+    if timeout <= 0 {
+        timeout = defaultWarmupTimeout
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    var ids randomIDGenerator
+    traceID, spanID := ids.NewIDs(ctx)
+    start := time.Now()
+    canary := tracetest.SpanStub{
+        Name: warmupSpanName,
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+            TraceID:    traceID,
+            SpanID:     spanID,
+            TraceFlags: oteltrace.FlagsSampled,
+        }),
+        StartTime:  start,
+        EndTime:    start,
+        Attributes: []attribute.KeyValue{attribute.Bool("warmup.canary", true)},
+        Resource:   res,
+    }
+    if err := exporter.ExportSpans(ctx, []trace.ReadOnlySpan{canary.Snapshot()}); err != nil {
+        return time.Since(start), fmt.Errorf("exporter warmup: %w", err)
+    }
+    return time.Since(start), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWarmupEstablishesCollectorConnection(t *testing.T) {
+    var mu sync.Mutex
+    var conns int
+    var received []zipkinSpan
+    server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var spans []zipkinSpan
+        if err := json.NewDecoder(r.Body).Decode(&spans); err != nil {
+            t.Errorf("collector got %v", err)
+        }
+        mu.Lock()
+        received = append(received, spans...)
+        mu.Unlock()
+        w.WriteHeader(http.StatusAccepted)
+    }))
+    server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            mu.Lock()
+            conns++
+            mu.Unlock()
+        }
+    }
+    server.Start()
+    defer server.Close()
+
+    e := newZipkinExporter(server.URL)
+    e.client = &http.Client{Transport: &http.Transport{}}
+    if _, err := warmupExporter(context.Background(), e, resource.Empty(), time.Second); err != nil {
+        t.Fatal(err)
+    }
+    mu.Lock()
+    if conns != 1 || len(received) != 1 {
+        t.Fatalf("after warmup: %d connections and %d spans, want 1 and the canary", conns, len(received))
+    }
+    if canary := received[0]; canary.Name != warmupSpanName || canary.Tags["warmup.canary"] != "true" {
+        t.Errorf("canary = %+v, want %s tagged warmup.canary", canary, warmupSpanName)
+    }
+    mu.Unlock()
+
+    if err := e.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "first request"}}.Snapshots()); err != nil {
+        t.Fatal(err)
+    }
+    mu.Lock()
+    defer mu.Unlock()
+    if conns != 1 || len(received) != 2 {
+        t.Errorf("after the first export: %d connections and %d spans, want the warmed-up connection reused", conns, len(received))
+    }
+}
+
+func TestWarmupIsTimeBounded(t *testing.T) {
+    release := make(chan struct{})
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-r.Context().Done():
+        case <-release:
+        }
+    }))
+    defer server.Close()
+    defer close(release)
+
+    took, err := warmupExporter(context.Background(), newZipkinExporter(server.URL), resource.Empty(), 50*time.Millisecond)
+    if err == nil {
+        t.Fatal("warmup against a stalled collector succeeded")
+    }
+    if took > time.Second {
+        t.Errorf("warmup took %v, want it abandoned after the 50ms timeout", took)
+    }
+}
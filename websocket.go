@@ -0,0 +1,171 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    "golang.org/x/net/websocket"
+)
+
+const defaultWebSocketAddress = "localhost:8081"
+
+// Messages buffered per client before it is considered too slow and dropped
+const websocketClientBuffer = 64
+
+// Exporter pushing spans to connected WebSocket clients (e.g. a live trace
+// viewer). Each export call becomes one text message holding a JSON array of
+// span stubs; clients that disconnect or fall behind are dropped, and spans
+// exported while no client is connected are discarded. Browser connections
+// are only accepted from the exporter's own origin or an allowed one, so
+// other web pages cannot read the span stream.
+type websocketExporter struct {
+    server   *http.Server
+    listener net.Listener
+    origins  map[string]bool
+
+    mu      sync.Mutex
+    clients map[*websocketClient]struct{}
+    stopped bool
+}
+
+type websocketClient struct {
+    send chan []byte
+}
+
+func newWebSocketExporter(address string, allowedOrigins []string) (*websocketExporter, error) {
+    if address == "" {
+        address = defaultWebSocketAddress
+    }
+    listener, err := net.Listen("tcp", address)
+    if err != nil {
+        return nil, fmt.Errorf("websocket exporter: %w", err)
+    }
+    e := &websocketExporter{
+        listener: listener,
+        origins:  map[string]bool{},
+        clients:  map[*websocketClient]struct{}{},
+    }
+    for _, origin := range allowedOrigins {
+        e.origins[strings.TrimSuffix(strings.ToLower(origin), "/")] = true
+    }
+    e.server = &http.Server{Handler: websocket.Server{Handler: e.serve, Handshake: e.checkOrigin}}
+    go e.server.Serve(listener)
+    return e, nil
+}
+
+// Address the exporter accepts connections on
+func (e *websocketExporter) Addr() net.Addr {
+    return e.listener.Addr()
+}
+
+// Reject handshakes from another site's pages. Requests without an Origin
+// do not come from a browser and are accepted; otherwise the origin must
+// match the requested host or be in the allowlist.
+func (e *websocketExporter) checkOrigin(config *websocket.Config, req *http.Request) error {
+    origin := req.Header.Get("Origin")
+    if origin == "" {
+        return nil
+    }
+    u, err := url.Parse(origin)
+    if err != nil {
+        return fmt.Errorf("websocket exporter: invalid origin %q", origin)
+    }
+    if strings.EqualFold(u.Host, req.Host) || e.origins[strings.ToLower(origin)] {
+        config.Origin = u
+        return nil
+    }
+    return fmt.Errorf("websocket exporter: origin %q not allowed", origin)
+}
+
+func (e *websocketExporter) serve(ws *websocket.Conn) {
+    client := &websocketClient{send: make(chan []byte, websocketClientBuffer)}
+    if !e.add(client) {
+        return
+    }
+    defer e.remove(client)
+
+    // Client frames are ignored; reading only notices the disconnect
+    disconnected := make(chan struct{})
+    go func() {
+        io.Copy(io.Discard, ws)
+        close(disconnected)
+    }()
+
+    for {
+        select {
+        case msg, ok := <-client.send:
+            if !ok {
+                return
+            }
+            if err := websocket.Message.Send(ws, string(msg)); err != nil {
+                return
+            }
+        case <-disconnected:
+            return
+        }
+    }
+}
+
+func (e *websocketExporter) add(c *websocketClient) bool {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.stopped {
+        return false
+    }
+    e.clients[c] = struct{}{}
+    return true
+}
+
+func (e *websocketExporter) remove(c *websocketClient) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.removeLocked(c)
+}
+
+func (e *websocketExporter) removeLocked(c *websocketClient) {
+    if _, ok := e.clients[c]; ok {
+        delete(e.clients, c)
+        close(c.send)
+    }
+}
+
+func (e *websocketExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    if len(spans) == 0 {
+        return nil
+    }
+    msg, err := json.Marshal(tracetest.SpanStubsFromReadOnlySpans(spans))
+    if err != nil {
+        return err
+    }
+
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    for c := range e.clients {
+        select {
+        case c.send <- msg:
+        default:
+            e.removeLocked(c)
+        }
+    }
+    return nil
+}
+
+// Disconnect every client and stop accepting new ones
+func (e *websocketExporter) Shutdown(ctx context.Context) error {
+    e.mu.Lock()
+    e.stopped = true
+    for c := range e.clients {
+        e.removeLocked(c)
+    }
+    e.mu.Unlock()
+    return e.server.Shutdown(ctx)
+}
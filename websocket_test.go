@@ -0,0 +1,73 @@
+package main
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    "golang.org/x/net/websocket"
+)
+
+func TestWebSocketExporterChecksOrigin(t *testing.T) {
+    e, err := newWebSocketExporter("127.0.0.1:0", []string{"https://viewer.example"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer e.Shutdown(context.Background())
+    endpoint := "ws://" + e.Addr().String() + "/"
+
+    for _, tc := range []struct {
+        origin  string
+        allowed bool
+    }{
+        {"http://" + e.Addr().String(), true},
+        {"https://viewer.example", true},
+        {"https://evil.example", false},
+    } {
+        ws, err := websocket.Dial(endpoint, "", tc.origin)
+        if (err == nil) != tc.allowed {
+            t.Errorf("origin %s: dial error %v, want allowed=%v", tc.origin, err, tc.allowed)
+        }
+        if err == nil {
+            ws.Close()
+        }
+    }
+}
+
+func TestWebSocketExporterStreamsSpans(t *testing.T) {
+    e, err := newWebSocketExporter("127.0.0.1:0", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer e.Shutdown(context.Background())
+    ws, err := websocket.Dial("ws://"+e.Addr().String()+"/", "", "http://"+e.Addr().String())
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer ws.Close()
+
+    // The client registers asynchronously after the handshake
+    deadline := time.Now().Add(time.Second)
+    for {
+        e.mu.Lock()
+        n := len(e.clients)
+        e.mu.Unlock()
+        if n > 0 || time.Now().After(deadline) {
+            break
+        }
+        time.Sleep(time.Millisecond)
+    }
+    if err := e.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "streamed"}}.Snapshots()); err != nil {
+        t.Fatal(err)
+    }
+    ws.SetReadDeadline(time.Now().Add(time.Second))
+    var msg string
+    if err := websocket.Message.Receive(ws, &msg); err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(msg, `"Name":"streamed"`) {
+        t.Errorf("message %s does not hold the exported span", msg)
+    }
+}
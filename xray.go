@@ -0,0 +1,215 @@
+package main
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "math/rand/v2"
+    "net"
+    "regexp"
+    "strconv"
+    "sync"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const defaultXRayDaemonAddress = "127.0.0.1:2000"
+
+// Header preceding every segment document sent to the X-Ray daemon
+const xrayDaemonHeader = `{"format": "json", "version": 1}` + "\n"
+
+// X-Ray segment document. Spans with a local parent are sent as independent
+// subsegments of the parent's segment.
+type xraySegment struct {
+    Name        string                    `json:"name"`
+    ID          string                    `json:"id"`
+    TraceID     string                    `json:"trace_id"`
+    ParentID    string                    `json:"parent_id,omitempty"`
+    Type        string                    `json:"type,omitempty"`
+    StartTime   float64                   `json:"start_time"`
+    EndTime     float64                   `json:"end_time"`
+    Error       bool                      `json:"error,omitempty"`
+    Throttle    bool                      `json:"throttle,omitempty"`
+    Fault       bool                      `json:"fault,omitempty"`
+    Annotations map[string]any            `json:"annotations,omitempty"`
+    Metadata    map[string]map[string]any `json:"metadata,omitempty"`
+}
+
+// Exporter sending spans as X-Ray segment documents to the X-Ray daemon
+// over UDP, one datagram per segment
+type xrayExporter struct {
+    mu   sync.Mutex
+    conn net.Conn
+}
+
+func newXRayExporter(address string) (*xrayExporter, error) {
+    if address == "" {
+        address = defaultXRayDaemonAddress
+    }
+    conn, err := net.Dial("udp", address)
+    if err != nil {
+        return nil, fmt.Errorf("xray exporter: %w", err)
+    }
+    return &xrayExporter{conn: conn}, nil
+}
+
+func (e *xrayExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.conn == nil {
+        return nil
+    }
+    for _, s := range spans {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        doc, err := json.Marshal(toXRaySegment(s))
+        if err != nil {
+            return err
+        }
+        if _, err := e.conn.Write(append([]byte(xrayDaemonHeader), doc...)); err != nil {
+            return fmt.Errorf("xray export: %w", err)
+        }
+    }
+    return nil
+}
+
+func (e *xrayExporter) Shutdown(context.Context) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    if e.conn == nil {
+        return nil
+    }
+    err := e.conn.Close()
+    e.conn = nil
+    return err
+}
+
+func toXRaySegment(s trace.ReadOnlySpan) xraySegment {
+    seg := xraySegment{
+        Name:      xraySegmentName(s),
+        ID:        s.SpanContext().SpanID().String(),
+        TraceID:   xrayTraceID(s.SpanContext().TraceID()),
+        StartTime: xrayTime(s.StartTime()),
+        EndTime:   xrayTime(s.EndTime()),
+    }
+    if parent := s.Parent(); parent.SpanID().IsValid() {
+        seg.ParentID = parent.SpanID().String()
+        if !parent.IsRemote() {
+            seg.Type = "subsegment"
+        }
+    }
+
+    // 4xx responses are errors (429 also throttled), 5xx and error statuses
+    // are faults
+    if s.Status().Code == codes.Error {
+        seg.Fault = true
+    }
+    for _, kv := range s.Attributes() {
+        if kv.Key == "http.status_code" || kv.Key == "http.response.status_code" {
+            code, _ := strconv.Atoi(kv.Value.Emit())
+            switch {
+            case code == 429:
+                seg.Error, seg.Throttle = true, true
+            case code >= 400 && code < 500:
+                seg.Error = true
+            case code >= 500:
+                seg.Fault = true
+            }
+        }
+    }
+
+    seg.Annotations, seg.Metadata = xrayAttributes(s.Attributes())
+    return seg
+}
+
+// Root segments are named after the service, subsegments after the span
+func xraySegmentName(s trace.ReadOnlySpan) string {
+    name := s.Name()
+    if parent := s.Parent(); !parent.IsValid() || parent.IsRemote() {
+        if res := s.Resource(); res != nil {
+            if v, ok := res.Set().Value(attribute.Key("service.name")); ok && v.AsString() != "" {
+                name = v.AsString()
+            }
+        }
+    }
+    return xrayName(name)
+}
+
+// Characters X-Ray accepts in segment names
+var xrayNameInvalid = regexp.MustCompile(`[^\p{L}\p{N}\p{Zs}_.:/%&#=+\-@]`)
+
+const xrayMaxNameLength = 200
+
+func xrayName(name string) string {
+    name = xrayNameInvalid.ReplaceAllString(name, "")
+    if name == "" {
+        return defaultSpanName
+    }
+    return cutUTF8(name, xrayMaxNameLength)
+}
+
+// Annotation keys may only hold alphanumerics and underscores
+var xrayAnnotationKeyInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Scalar attributes become (indexed) annotations; slices, which annotations
+// cannot hold, go to the default metadata namespace under their original key
+func xrayAttributes(attrs []attribute.KeyValue) (map[string]any, map[string]map[string]any) {
+    annotations := map[string]any{}
+    metadata := map[string]any{}
+    for _, kv := range attrs {
+        switch kv.Value.Type() {
+        case attribute.BOOL, attribute.INT64, attribute.FLOAT64, attribute.STRING:
+            key := xrayAnnotationKeyInvalid.ReplaceAllString(string(kv.Key), "_")
+            annotations[key] = kv.Value.AsInterface()
+        default:
+            metadata[string(kv.Key)] = kv.Value.AsInterface()
+        }
+    }
+    if len(annotations) == 0 {
+        annotations = nil
+    }
+    if len(metadata) == 0 {
+        return annotations, nil
+    }
+    return annotations, map[string]map[string]any{"default": metadata}
+}
+
+// Format an OTel trace ID as an X-Ray trace ID, "1-<8 hex>-<24 hex>". The
+// first four bytes are read as the trace's start epoch; X-Ray rejects trace
+// IDs whose epoch is more than 30 days off, so pair this exporter with
+// xrayIDGenerator.
+func xrayTraceID(id oteltrace.TraceID) string {
+    hex := id.String()
+    return "1-" + hex[:8] + "-" + hex[8:]
+}
+
+// Seconds since the epoch with microsecond precision
+func xrayTime(t time.Time) float64 {
+    return float64(t.UnixMicro()) / 1e6
+}
+
+// ID generator whose trace IDs start with the current Unix time, as X-Ray
+// requires
+type xrayIDGenerator struct{}
+
+func (xrayIDGenerator) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+    var tid oteltrace.TraceID
+    binary.BigEndian.PutUint32(tid[:4], uint32(time.Now().Unix()))
+    binary.BigEndian.PutUint32(tid[4:8], rand.Uint32())
+    binary.BigEndian.PutUint64(tid[8:], rand.Uint64())
+    return tid, xrayIDGenerator{}.NewSpanID(ctx, tid)
+}
+
+func (xrayIDGenerator) NewSpanID(context.Context, oteltrace.TraceID) oteltrace.SpanID {
+    var sid oteltrace.SpanID
+    for !sid.IsValid() {
+        binary.BigEndian.PutUint64(sid[:], rand.Uint64())
+    }
+    return sid
+}
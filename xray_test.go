@@ -0,0 +1,127 @@
+package main
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "net"
+    "regexp"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+var (
+    xrayTraceIDFormat   = regexp.MustCompile(`^1-[0-9a-f]{8}-[0-9a-f]{24}$`)
+    xraySegmentIDFormat = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+func TestXRayExporterSendsSegmentDocuments(t *testing.T) {
+    daemon, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer daemon.Close()
+    exporter, err := newXRayExporter(daemon.LocalAddr().String())
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer exporter.Shutdown(context.Background())
+
+    traceID := oteltrace.TraceID{0x65, 0x53, 0xf1, 0x00, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+    root := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}})
+    start := time.Unix(1700000000, 250000000)
+    spans := tracetest.SpanStubs{
+        {
+            Name:        "GET /orders",
+            SpanContext: root,
+            StartTime:   start,
+            EndTime:     start.Add(1500 * time.Microsecond),
+            Attributes:  []attribute.KeyValue{attribute.Int("http.status_code", 404), attribute.StringSlice("tags", []string{"a"})},
+            Resource:    resource.NewSchemaless(attribute.String("service.name", "orders")),
+        },
+        {
+            Name:        "SELECT orders",
+            SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{2}}),
+            Parent:      root,
+            StartTime:   start,
+            EndTime:     start.Add(time.Millisecond),
+            Status:      trace.Status{Code: codes.Error},
+        },
+    }.Snapshots()
+    if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+        t.Fatal(err)
+    }
+
+    daemon.SetReadDeadline(time.Now().Add(5 * time.Second))
+    var segments []map[string]any
+    buf := make([]byte, 64*1024)
+    for range spans {
+        n, _, err := daemon.ReadFrom(buf)
+        if err != nil {
+            t.Fatal(err)
+        }
+        header, doc, ok := strings.Cut(string(buf[:n]), "\n")
+        if !ok || header+"\n" != xrayDaemonHeader {
+            t.Fatalf("datagram missing daemon header: %q", buf[:n])
+        }
+        var seg map[string]any
+        if err := json.Unmarshal([]byte(doc), &seg); err != nil {
+            t.Fatal(err)
+        }
+        segments = append(segments, seg)
+    }
+
+    for _, seg := range segments {
+        for _, field := range []string{"name", "id", "trace_id", "start_time", "end_time"} {
+            if _, ok := seg[field]; !ok {
+                t.Errorf("segment %v missing required field %q", seg, field)
+            }
+        }
+        if id, _ := seg["trace_id"].(string); !xrayTraceIDFormat.MatchString(id) || id != "1-6553f100-0102030405060708090a0b0c" {
+            t.Errorf("trace_id = %q", id)
+        }
+        if id, _ := seg["id"].(string); !xraySegmentIDFormat.MatchString(id) {
+            t.Errorf("id = %q", id)
+        }
+    }
+
+    segment, subsegment := segments[0], segments[1]
+    if segment["name"] != "orders" || segment["type"] != nil || segment["parent_id"] != nil {
+        t.Errorf("root segment = %v", segment)
+    }
+    if segment["start_time"] != 1700000000.25 || segment["end_time"] != 1700000000.2515 {
+        t.Errorf("segment times = %v, %v", segment["start_time"], segment["end_time"])
+    }
+    if segment["error"] != true || segment["fault"] != nil {
+        t.Errorf("404 segment error/fault = %v/%v, want error only", segment["error"], segment["fault"])
+    }
+    if ann, _ := segment["annotations"].(map[string]any); ann["http_status_code"] != float64(404) {
+        t.Errorf("annotations = %v", segment["annotations"])
+    }
+    if meta, _ := segment["metadata"].(map[string]any); meta["default"] == nil {
+        t.Errorf("slice attribute not in default metadata: %v", segment["metadata"])
+    }
+    if subsegment["name"] != "SELECT orders" || subsegment["type"] != "subsegment" || subsegment["parent_id"] != segment["id"] || subsegment["fault"] != true {
+        t.Errorf("subsegment = %v", subsegment)
+    }
+}
+
+func TestXRayIDGeneratorEmbedsEpoch(t *testing.T) {
+    before := time.Now().Unix()
+    tid, sid := xrayIDGenerator{}.NewIDs(context.Background())
+    epoch := int64(binary.BigEndian.Uint32(tid[:4]))
+    if epoch < before || epoch > time.Now().Unix() {
+        t.Errorf("trace ID epoch = %d, want about %d", epoch, before)
+    }
+    if !sid.IsValid() || !xrayTraceIDFormat.MatchString(xrayTraceID(tid)) {
+        t.Errorf("invalid IDs %s, %s", tid, sid)
+    }
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net"
+    "net/http"
+    "sync"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/trace"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const defaultZipkinEndpoint = "http://localhost:9411/api/v2/spans"
+
+// Zipkin v2 JSON span model
+type zipkinSpan struct {
+    TraceID       string             `json:"traceId"`
+    ID            string             `json:"id"`
+    ParentID      string             `json:"parentId,omitempty"`
+    Name          string             `json:"name"`
+    Kind          string             `json:"kind,omitempty"`
+    Timestamp     int64              `json:"timestamp"`
+    Duration      int64              `json:"duration"`
+    LocalEndpoint *zipkinEndpoint    `json:"localEndpoint,omitempty"`
+    Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+    Tags          map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+    ServiceName string `json:"serviceName,omitempty"`
+    IPv4        string `json:"ipv4,omitempty"`
+    IPv6        string `json:"ipv6,omitempty"`
+}
+
+type zipkinAnnotation struct {
+    Timestamp int64  `json:"timestamp"`
+    Value     string `json:"value"`
+}
+
+// Exporter posting spans to a Zipkin collector in the v2 JSON format
+type zipkinExporter struct {
+    endpoint string
+    client   *http.Client
+
+    mu      sync.Mutex
+    stopped bool
+}
+
+func newZipkinExporter(endpoint string) *zipkinExporter {
+    if endpoint == "" {
+        endpoint = defaultZipkinEndpoint
+    }
+    return &zipkinExporter{endpoint: endpoint, client: http.DefaultClient}
+}
+
+func (e *zipkinExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+    e.mu.Lock()
+    stopped := e.stopped
+    e.mu.Unlock()
+    if stopped || len(spans) == 0 {
+        return nil
+    }
+
+    body, err := json.Marshal(toZipkinSpans(spans))
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := e.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("zipkin collector %s returned %s", redactURL(e.endpoint), resp.Status)
+    }
+    return nil
+}
+
+func (e *zipkinExporter) Shutdown(ctx context.Context) error {
+    e.mu.Lock()
+    e.stopped = true
+    e.mu.Unlock()
+    return ctx.Err()
+}
+
+func toZipkinSpans(spans []trace.ReadOnlySpan) []zipkinSpan {
+    out := make([]zipkinSpan, 0, len(spans))
+    for _, s := range spans {
+        out = append(out, toZipkinSpan(s))
+    }
+    return out
+}
+
+func toZipkinSpan(s trace.ReadOnlySpan) zipkinSpan {
+    zs := zipkinSpan{
+        TraceID:       s.SpanContext().TraceID().String(),
+        ID:            s.SpanContext().SpanID().String(),
+        Name:          s.Name(),
+        Kind:          zipkinKind(s.SpanKind()),
+        Timestamp:     s.StartTime().UnixMicro(),
+        Duration:      s.EndTime().Sub(s.StartTime()).Microseconds(),
+        LocalEndpoint: zipkinLocalEndpoint(s),
+    }
+    if s.Parent().SpanID().IsValid() {
+        zs.ParentID = s.Parent().SpanID().String()
+    }
+
+    for _, e := range s.Events() {
+        zs.Annotations = append(zs.Annotations, zipkinAnnotation{
+            Timestamp: e.Time.UnixMicro(),
+            Value:     e.Name,
+        })
+    }
+
+    tags := make(map[string]string)
+    for _, kv := range s.Attributes() {
+        tags[string(kv.Key)] = kv.Value.Emit()
+    }
+    if scope := s.InstrumentationScope(); scope.Name != "" {
+        tags["otel.scope.name"] = scope.Name
+        if scope.Version != "" {
+            tags["otel.scope.version"] = scope.Version
+        }
+    }
+    if status := s.Status(); status.Code == codes.Error {
+        tags["otel.status_code"] = "ERROR"
+        tags["error"] = status.Description
+    }
+    if len(tags) > 0 {
+        zs.Tags = tags
+    }
+    return zs
+}
+
+// Build the local endpoint from the resource service name and host IP
+func zipkinLocalEndpoint(s trace.ReadOnlySpan) *zipkinEndpoint {
+    var ep zipkinEndpoint
+    if res := s.Resource(); res != nil {
+        if v, ok := res.Set().Value(attribute.Key("service.name")); ok {
+            ep.ServiceName = v.AsString()
+        }
+        if v, ok := res.Set().Value(attribute.Key("host.ip")); ok {
+            if ip := net.ParseIP(v.AsString()); ip != nil {
+                if ip.To4() != nil {
+                    ep.IPv4 = ip.String()
+                } else {
+                    ep.IPv6 = ip.String()
+                }
+            }
+        }
+    }
+    if ep == (zipkinEndpoint{}) {
+        return nil
+    }
+    return &ep
+}
+
+func zipkinKind(kind oteltrace.SpanKind) string {
+    switch kind {
+    case oteltrace.SpanKindServer:
+        return "SERVER"
+    case oteltrace.SpanKindClient:
+        return "CLIENT"
+    case oteltrace.SpanKindProducer:
+        return "PRODUCER"
+    case oteltrace.SpanKindConsumer:
+        return "CONSUMER"
+    }
+    return ""
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/sdk/resource"
+    "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestZipkinExporterPostsV2JSON(t *testing.T) {
+    var received []zipkinSpan
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+            t.Errorf("Content-Type %q", ct)
+        }
+        if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+            t.Error(err)
+        }
+        w.WriteHeader(http.StatusAccepted)
+    }))
+    defer server.Close()
+
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    traceID := oteltrace.TraceID{0x4b, 0xf9}
+    span := tracetest.SpanStub{
+        Name:        "GET /users",
+        SpanKind:    oteltrace.SpanKindServer,
+        SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{2}}),
+        Parent:      oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}}),
+        StartTime:   start,
+        EndTime:     start.Add(150 * time.Millisecond),
+        Attributes:  []attribute.KeyValue{attribute.Int("http.status_code", 500)},
+        Events:      []trace.Event{{Name: "exception", Time: start.Add(time.Millisecond)}},
+        Status:      trace.Status{Code: codes.Error, Description: "boom"},
+        Resource:    resource.NewSchemaless(attribute.String("service.name", "web-backend"), attribute.String("host.ip", "10.0.0.5")),
+    }.Snapshot()
+
+    e := newZipkinExporter(server.URL)
+    if err := e.ExportSpans(context.Background(), []trace.ReadOnlySpan{span}); err != nil {
+        t.Fatal(err)
+    }
+    if len(received) != 1 {
+        t.Fatalf("received %d spans", len(received))
+    }
+    got := received[0]
+    if got.TraceID != traceID.String() || got.ParentID != (oteltrace.SpanID{1}).String() || got.Kind != "SERVER" {
+        t.Errorf("ids or kind: %+v", got)
+    }
+    if got.Timestamp != start.UnixMicro() || got.Duration != 150000 {
+        t.Errorf("timestamp %d duration %d", got.Timestamp, got.Duration)
+    }
+    if got.LocalEndpoint == nil || got.LocalEndpoint.ServiceName != "web-backend" || got.LocalEndpoint.IPv4 != "10.0.0.5" {
+        t.Errorf("local endpoint %+v", got.LocalEndpoint)
+    }
+    if got.Tags["http.status_code"] != "500" || got.Tags["error"] != "boom" || len(got.Annotations) != 1 {
+        t.Errorf("tags %v annotations %v", got.Tags, got.Annotations)
+    }
+}
+
+func TestZipkinExporterErrorHidesPassword(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer server.Close()
+
+    endpoint := strings.Replace(server.URL, "http://", "http://user:secret@", 1)
+    err := newZipkinExporter(endpoint).ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "op"}}.Snapshots())
+    if err == nil || !strings.Contains(err.Error(), "503") {
+        t.Fatalf("err = %v, want the collector status", err)
+    }
+    if strings.Contains(err.Error(), "secret") {
+        t.Errorf("error leaks the password: %v", err)
+    }
+}